@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gitlab.com/peleng-meteo/meteo-go/internal/config"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/database/mongodb"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/hash"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/logger"
+)
+
+const (
+	configsDir        = "configs"
+	minPasswordLength = 8
+)
+
+// Creates admin account, e.g. the first super admin who manages other admins via API, or resets password
+// of the existing one, e.g. stored in plain text before passwords were hashed.
+// Password is read from the first line of stdin unless it's given with the flag
+func main() {
+	var (
+		name       = flag.String("name", "", "admin name")
+		email      = flag.String("email", "", "admin email")
+		password   = flag.String("password", "", "admin password, read from stdin if empty")
+		superAdmin = flag.Bool("super", true, "create super admin")
+		reset      = flag.Bool("reset", false, "reset password of the existing admin instead of creating one")
+	)
+	flag.Parse()
+
+	if *email == "" || (*name == "" && !*reset) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *password == "" {
+		var err error
+		if *password, err = readPassword(); err != nil {
+			logger.Error(err)
+			os.Exit(1)
+		}
+	}
+
+	if err := run(service.CreateAdminInput{
+		Name:       *name,
+		Email:      *email,
+		Password:   *password,
+		SuperAdmin: *superAdmin,
+	}, *reset); err != nil {
+		logger.Error(err)
+		os.Exit(1)
+	}
+}
+
+func readPassword() (string, error) {
+	fmt.Fprint(os.Stderr, "Password: ")
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func run(inp service.CreateAdminInput, reset bool) error {
+	if len(inp.Password) < minPasswordLength {
+		return errors.New("password must be at least 8 characters long")
+	}
+
+	cfg, err := config.Init(configsDir)
+	if err != nil {
+		return err
+	}
+
+	hasher, err := hash.NewMultiHasher(cfg.Auth.PasswordHash.Algorithm,
+		hash.NewArgon2idHasher(hash.Argon2idParams{
+			Memory:      cfg.Auth.PasswordHash.Argon2Memory,
+			Iterations:  cfg.Auth.PasswordHash.Argon2Iterations,
+			Parallelism: cfg.Auth.PasswordHash.Argon2Parallelism,
+		}),
+		hash.NewBCryptHasher(cfg.Auth.PasswordHash.BCryptCost),
+		cfg.Auth.PasswordSalt)
+	if err != nil {
+		return err
+	}
+
+	mongoClient, err := mongodb.NewClient(cfg.Mongo.URI, cfg.Mongo.User, cfg.Mongo.Password)
+	if err != nil {
+		return err
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	db := mongoClient.Database(cfg.Mongo.Name)
+	if err := repository.Migrate(context.Background(), db); err != nil {
+		return err
+	}
+
+	if err := repository.CreateIndexes(context.Background(), db); err != nil {
+		return err
+	}
+
+	adminsRepo := repository.NewAdminsRepo(db)
+
+	// admin management doesn't issue tokens, so there is no token manager
+	adminsService := service.NewAdminsService(hasher, nil, adminsRepo, 0, 0)
+
+	if reset {
+		admin, err := adminsRepo.GetByEmail(context.Background(), inp.Email)
+		if err != nil {
+			return err
+		}
+
+		if err := adminsService.Update(context.Background(), service.UpdateAdminInput{ID: admin.ID, Password: &inp.Password}); err != nil {
+			return err
+		}
+
+		fmt.Println(admin.ID.Hex())
+
+		return nil
+	}
+
+	id, err := adminsService.Create(context.Background(), inp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(id.Hex())
+
+	return nil
+}
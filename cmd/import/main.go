@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+
+	"gitlab.com/peleng-meteo/meteo-go/internal/config"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/database/mongodb"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/logger"
+)
+
+const configsDir = "configs"
+
+// Imports historical observations from CSV or NDJSON file and prints import report as JSON
+func main() {
+	var (
+		file       = flag.String("file", "", "path to the observations file")
+		format     = flag.String("format", service.ImportFormatCSV, "file format: csv or ndjson")
+		mapping    = flag.String("mapping", "", "observation fields to columns mapping, e.g. temperature=t,humidity=rh")
+		station    = flag.String("station", "", "station id for rows without station")
+		timeFormat = flag.String("time-format", "", "unix, unixms or Go time layout, RFC3339 by default")
+		batchSize  = flag.Int("batch", 0, "insert batch size")
+	)
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*file, *mapping, service.ImportInput{
+		Format:     *format,
+		StationID:  *station,
+		TimeFormat: *timeFormat,
+		BatchSize:  *batchSize,
+	}); err != nil {
+		logger.Error(err)
+		os.Exit(1)
+	}
+}
+
+func run(path, mapping string, inp service.ImportInput) error {
+	var err error
+	if inp.Mapping, err = service.ParseColumnMapping(mapping); err != nil {
+		return err
+	}
+
+	cfg, err := config.Init(configsDir)
+	if err != nil {
+		return err
+	}
+
+	mongoClient, err := mongodb.NewClient(cfg.Mongo.URI, cfg.Mongo.User, cfg.Mongo.Password)
+	if err != nil {
+		return err
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	db := mongoClient.Database(cfg.Mongo.Name)
+	if err := repository.Migrate(context.Background(), db); err != nil {
+		return err
+	}
+
+	if err := repository.CreateIndexes(context.Background(), db); err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	observationsRepo := repository.NewObservationsRepo(db)
+	importService := service.NewImportService(observationsRepo, service.NewQualityControlService(observationsRepo))
+	report, err := importService.Import(context.Background(), f, inp)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if encodeErr := encoder.Encode(report); encodeErr != nil {
+		return encodeErr
+	}
+
+	return err
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gitlab.com/peleng-meteo/meteo-go/internal/config"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/database/mongodb"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/logger"
+)
+
+const configsDir = "configs"
+
+// Loads NWP model output from GRIB2 files of the directory and prints load reports as JSON
+func main() {
+	var (
+		dir        = flag.String("dir", "", "directory with GRIB2 files")
+		model      = flag.String("model", "", "model name runs are stored under, e.g. gfs")
+		extensions = flag.String("ext", ".grib2,.grb2,.grb", "comma-separated extensions of loaded files, empty loads all files")
+	)
+	flag.Parse()
+
+	if *dir == "" || *model == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var exts []string
+	if *extensions != "" {
+		exts = strings.Split(*extensions, ",")
+	}
+
+	if err := run(*dir, *model, exts); err != nil {
+		logger.Error(err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, model string, extensions []string) error {
+	files, err := gribFiles(dir, extensions)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Init(configsDir)
+	if err != nil {
+		return err
+	}
+
+	mongoClient, err := mongodb.NewClient(cfg.Mongo.URI, cfg.Mongo.User, cfg.Mongo.Password)
+	if err != nil {
+		return err
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	db := mongoClient.Database(cfg.Mongo.Name)
+	if err := repository.Migrate(context.Background(), db); err != nil {
+		return err
+	}
+
+	if err := repository.CreateIndexes(context.Background(), db); err != nil {
+		return err
+	}
+
+	modelsService := service.NewModelsService(repository.NewModelRunsRepo(db), repository.NewModelFieldsRepo(db),
+		repository.NewStationsRepo(db))
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	for _, path := range files {
+		report, err := loadFile(modelsService, path, model)
+		if encodeErr := encoder.Encode(report); encodeErr != nil {
+			return encodeErr
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadFile(modelsService *service.ModelsService, path, model string) (service.ModelLoadReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return service.ModelLoadReport{File: path}, err
+	}
+	defer f.Close()
+
+	return modelsService.Load(context.Background(), f, service.LoadModelInput{
+		Model: model,
+		File:  filepath.Base(path),
+	})
+}
+
+// gribFiles lists files of the directory with given extensions, or all of them if there are no extensions.
+// Files are listed in name order, so forecast steps are loaded in order
+func gribFiles(dir string, extensions []string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		if len(extensions) == 0 {
+			files = append(files, filepath.Join(dir, entry.Name()))
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		for _, e := range extensions {
+			if ext == strings.TrimSpace(e) {
+				files = append(files, filepath.Join(dir, entry.Name()))
+				break
+			}
+		}
+	}
+
+	return files, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"gitlab.com/peleng-meteo/meteo-go/internal/config"
+	delivery "gitlab.com/peleng-meteo/meteo-go/internal/delivery/http"
 	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
 	"gitlab.com/peleng-meteo/meteo-go/internal/server"
 	"gitlab.com/peleng-meteo/meteo-go/internal/service"
@@ -13,9 +14,11 @@ import (
 	"gitlab.com/peleng-meteo/meteo-go/pkg/hash"
 	"gitlab.com/peleng-meteo/meteo-go/pkg/logger"
 	"gitlab.com/peleng-meteo/meteo-go/pkg/otp"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/sensor"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -37,8 +40,30 @@ func Run(configPath string) {
 
 	db := mongoClient.Database(cfg.Mongo.Name)
 
+	if err := repository.Migrate(context.Background(), db); err != nil {
+		logger.Error(err)
+		return
+	}
+
+	if err := repository.CreateIndexes(context.Background(), db); err != nil {
+		logger.Error(err)
+		return
+	}
+
 	memCache := cache.NewMemoryCache()
-	hasher := hash.NewSHA1Hasher(cfg.Auth.PasswordSalt)
+	hasher, err := hash.NewMultiHasher(cfg.Auth.PasswordHash.Algorithm,
+		hash.NewArgon2idHasher(hash.Argon2idParams{
+			Memory:      cfg.Auth.PasswordHash.Argon2Memory,
+			Iterations:  cfg.Auth.PasswordHash.Argon2Iterations,
+			Parallelism: cfg.Auth.PasswordHash.Argon2Parallelism,
+		}),
+		hash.NewBCryptHasher(cfg.Auth.PasswordHash.BCryptCost),
+		cfg.Auth.PasswordSalt)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+
 	emailProvider := sendpulse.NewClient(cfg.Email.SendPulse.ClientID, cfg.Email.SendPulse.ClientSecret, memCache)
 	emailSender, err := smtp.NewSMTPSender(cfg.SMTP.From, cfg.SMTP.Pass, cfg.SMTP.Host, cfg.SMTP.Port)
 	if err != nil {
@@ -56,8 +81,15 @@ func Run(configPath string) {
 
 	// Services, Repos & API Handlers
 	repos := repository.NewRepositories(db)
+	sensors := repository.NewSensorsRepo(sensor.Config{
+		Type:         cfg.Ingestion.Source.Type,
+		Address:      cfg.Ingestion.Source.Address,
+		DialTimeout:  cfg.Ingestion.Source.DialTimeout,
+		PollInterval: cfg.Ingestion.Source.PollInterval,
+	}, cfg.Ingestion.Source.Format, cfg.Ingestion.StationID)
 	services := service.NewServices(service.Deps{
 		Repos:                  repos,
+		Sensors:                sensors,
 		Cache:                  memCache,
 		Hasher:                 hasher,
 		TokenManager:           tokenManager,
@@ -71,6 +103,13 @@ func Run(configPath string) {
 		VerificationCodeLength: cfg.Auth.VerificationCodeLength,
 		FrontendURL:            cfg.FrontendURL,
 		Environment:            cfg.Environment,
+		IngestionRetryInterval: cfg.Ingestion.RetryInterval,
+		WebhooksConfig:         cfg.Webhooks,
+		AlertsQueueSize:        cfg.Alerts.QueueSize,
+		StreamBufferSize:       cfg.Stream.BufferSize,
+		HealthConfig:           cfg.Health,
+		ForecastConfig:         cfg.Forecast,
+		ClimateConfig:          cfg.Climate,
 	})
 	handlers := delivery.NewHandler(services, tokenManager)
 
@@ -84,6 +123,25 @@ func Run(configPath string) {
 
 	logger.Info("Server started")
 
+	// Background Workers: sensors ingestion, webhook deliveries, alert emails, stations health monitor and normals jobs
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	workers := &sync.WaitGroup{}
+
+	if cfg.Ingestion.Enabled {
+		runWorker(workersCtx, workers, services.Ingestion.Run)
+	}
+
+	runWorker(workersCtx, workers, services.Webhooks.Run)
+	runWorker(workersCtx, workers, services.Alerts.Run)
+	runWorker(workersCtx, workers, services.StationHealth.Run)
+	runWorker(workersCtx, workers, services.Climate.Run)
+
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+
 	// Graceful Shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
@@ -99,7 +157,23 @@ func Run(configPath string) {
 		logger.Errorf("failed to stop server: %v", err)
 	}
 
+	stopWorkers()
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		logger.Error("failed to stop background workers: timeout exceeded")
+	}
+
 	if err := mongoClient.Disconnect(context.Background()); err != nil {
 		logger.Error(err.Error())
 	}
 }
+
+func runWorker(ctx context.Context, wg *sync.WaitGroup, run func(ctx context.Context)) {
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		run(ctx)
+	}()
+}
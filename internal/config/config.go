@@ -15,6 +15,35 @@ const (
 	defaultLimiterBurst           = 2
 	defaultLimiterTTL             = 10 * time.Minute
 	defaultVerificationCodeLength = 8
+	defaultPasswordHashAlgorithm  = "argon2id"
+	defaultArgon2Memory           = 64 * 1024
+	defaultArgon2Iterations       = 3
+	defaultArgon2Parallelism      = 2
+	defaultBCryptCost             = 12
+	defaultIngestionRetryInterval = 5 * time.Second
+	defaultSourcePollInterval     = time.Second
+	defaultSourceDialTimeout      = 10 * time.Second
+	defaultWebhooksWorkers        = 4
+	defaultWebhooksQueueSize      = 1000
+	defaultWebhooksMaxAttempts    = 6
+	defaultWebhooksInitialBackoff = 10 * time.Second
+	defaultWebhooksMaxBackoff     = time.Hour
+	defaultWebhooksTimeout        = 10 * time.Second
+	defaultAlertsQueueSize        = 1000
+	defaultStreamBufferSize       = 64
+	defaultHealthCheckInterval    = time.Minute
+	defaultHealthReportInterval   = 10 * time.Minute
+	defaultHealthLateFactor       = 1.5
+	defaultHealthOfflineFactor    = 3
+	defaultForecastMaxHours       = 240
+	defaultForecastStationRadius  = 5
+	defaultForecastBiasRadius     = 50
+	defaultForecastBiasHours      = 24
+	defaultClimateYears           = 30
+	defaultClimateMinYears        = 10
+	defaultClimateMinDailyObs     = 4
+	defaultClimateMonthCoverage   = 0.8
+	defaultClimateDailyWindow     = 7
 
 	EnvLocal = "local"
 )
@@ -30,6 +59,13 @@ type (
 		CacheTTL time.Duration `mapstructure:"ttl"`
 		FrontendURL string
 		SMTP SMTPConfig
+		Ingestion IngestionConfig
+		Webhooks WebhooksConfig
+		Alerts AlertsConfig
+		Stream StreamConfig
+		Health HealthConfig
+		Forecast ForecastConfig
+		Climate ClimateConfig
 	}
 
 	MongoConfig struct {
@@ -43,6 +79,18 @@ type (
 		JWT JWTConfig
 		PasswordSalt string
 		VerificationCodeLength int `mapstructure:"verificationCodeLength"`
+		PasswordHash PasswordHashConfig
+	}
+
+	// PasswordHashConfig configures hashing of new passwords, Algorithm is argon2id or bcrypt.
+	// Argon2Memory is in KiB. Hashes with other algorithm or parameters are upgraded on login,
+	// PasswordSalt is used to verify legacy SHA-1 ones only
+	PasswordHashConfig struct {
+		Algorithm string `mapstructure:"algorithm"`
+		Argon2Memory uint32 `mapstructure:"argon2Memory"`
+		Argon2Iterations uint32 `mapstructure:"argon2Iterations"`
+		Argon2Parallelism uint8 `mapstructure:"argon2Parallelism"`
+		BCryptCost int `mapstructure:"bcryptCost"`
 	}
 
 	JWTConfig struct {
@@ -65,11 +113,13 @@ type (
 
 	EmailTemplates struct {
 		Verification string `mapstructure:"verification_email"`
+		Alert string `mapstructure:"alert_email"`
 		PurhcaseSuccessful string `mapstructure:"purhcase_successful"`
 	}
 
 	EmailSubjects struct {
 		Verification string `mapstructure:"verification_email"`
+		Alert string `mapstructure:"alert_email"`
 		PurhcaseSuccessful string `mapstructure:"purhcase_successful"`
 	}
 
@@ -81,6 +131,70 @@ type (
 		MaxHeaderMegabytes int `mapstructure:"maxHeaderMegabytes"`
 	}
 
+	IngestionConfig struct {
+		Enabled bool `mapstructure:"enabled"`
+		StationID string `mapstructure:"stationId"`
+		RetryInterval time.Duration `mapstructure:"retryInterval"`
+		Source SourceConfig `mapstructure:"source"`
+	}
+
+	SourceConfig struct {
+		Type string `mapstructure:"type"`
+		Address string `mapstructure:"address"`
+		Format string `mapstructure:"format"`
+		DialTimeout time.Duration `mapstructure:"dialTimeout"`
+		PollInterval time.Duration `mapstructure:"pollInterval"`
+	}
+
+	WebhooksConfig struct {
+		Workers int `mapstructure:"workers"`
+		QueueSize int `mapstructure:"queueSize"`
+		MaxAttempts int `mapstructure:"maxAttempts"`
+		InitialBackoff time.Duration `mapstructure:"initialBackoff"`
+		MaxBackoff time.Duration `mapstructure:"maxBackoff"`
+		Timeout time.Duration `mapstructure:"timeout"`
+	}
+
+	// AlertsConfig configures alert notifications. Emails are queued by ingestion and sent in background
+	AlertsConfig struct {
+		QueueSize int `mapstructure:"queueSize"`
+	}
+
+	StreamConfig struct {
+		BufferSize int `mapstructure:"bufferSize"`
+	}
+
+	// HealthConfig configures station health monitor. Station is late when it hasn't reported
+	// for LateFactor report intervals and offline after OfflineFactor intervals
+	HealthConfig struct {
+		CheckInterval time.Duration `mapstructure:"checkInterval"`
+		DefaultReportInterval time.Duration `mapstructure:"defaultReportInterval"`
+		LateFactor float64 `mapstructure:"lateFactor"`
+		OfflineFactor float64 `mapstructure:"offlineFactor"`
+	}
+
+	// ForecastConfig configures point forecasts. Forecast is given for the station within StationRadius km
+	// or the nearest grid point otherwise, it's corrected by bias at the station within BiasRadius km
+	// averaged over the last BiasHours hours
+	ForecastConfig struct {
+		Model string `mapstructure:"model"`
+		MaxHours int `mapstructure:"maxHours"`
+		StationRadius float64 `mapstructure:"stationRadius"`
+		BiasRadius float64 `mapstructure:"biasRadius"`
+		BiasHours int `mapstructure:"biasHours"`
+	}
+
+	// ClimateConfig configures climatological normals computed over Years complete years preceding the current one.
+	// Day counts when it has MinDailyObservations observations, month when MinMonthCoverage share of its days count.
+	// Daily normals pool days within DailyWindow days of the calendar day, normals of less than MinYears are skipped
+	ClimateConfig struct {
+		Years int `mapstructure:"years"`
+		MinYears int `mapstructure:"minYears"`
+		MinDailyObservations int64 `mapstructure:"minDailyObservations"`
+		MinMonthCoverage float64 `mapstructure:"minMonthCoverage"`
+		DailyWindow int `mapstructure:"dailyWindow"`
+	}
+
 	LimiterConfig struct {
 		RPS int
 		Burst int
@@ -139,6 +253,10 @@ func unmarshal(cfg *Config) error {
 		return err
 	}
 
+	if err := viper.UnmarshalKey("auth.passwordHash", &cfg.Auth.PasswordHash); err != nil {
+		return err
+	}
+
 	if err := viper.UnmarshalKey("limiter", &cfg.Limiter); err != nil {
 		return err
 	}
@@ -155,6 +273,34 @@ func unmarshal(cfg *Config) error {
 		return err
 	}
 
+	if err := viper.UnmarshalKey("ingestion", &cfg.Ingestion); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("webhooks", &cfg.Webhooks); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("alerts", &cfg.Alerts); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("stream", &cfg.Stream); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("health", &cfg.Health); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("forecast", &cfg.Forecast); err != nil {
+		return err
+	}
+
+	if err := viper.UnmarshalKey("climate", &cfg.Climate); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -202,9 +348,38 @@ func populateDefaults() {
 	viper.SetDefault("auth.accessTokenTTL", defaultAccessTokenTTL)
 	viper.SetDefault("auth.refreshTokenTTL", defaultRefreshTokenTTL)
 	viper.SetDefault("auth.verificationCodeLength", defaultVerificationCodeLength)
+	viper.SetDefault("auth.passwordHash.algorithm", defaultPasswordHashAlgorithm)
+	viper.SetDefault("auth.passwordHash.argon2Memory", defaultArgon2Memory)
+	viper.SetDefault("auth.passwordHash.argon2Iterations", defaultArgon2Iterations)
+	viper.SetDefault("auth.passwordHash.argon2Parallelism", defaultArgon2Parallelism)
+	viper.SetDefault("auth.passwordHash.bcryptCost", defaultBCryptCost)
 	viper.SetDefault("limiter.rps", defaultLimiterRPS)
 	viper.SetDefault("limiter.burst", defaultLimiterBurst)
 	viper.SetDefault("limiter.ttl", defaultLimiterTTL)
+	viper.SetDefault("ingestion.retryInterval", defaultIngestionRetryInterval)
+	viper.SetDefault("ingestion.source.pollInterval", defaultSourcePollInterval)
+	viper.SetDefault("ingestion.source.dialTimeout", defaultSourceDialTimeout)
+	viper.SetDefault("webhooks.workers", defaultWebhooksWorkers)
+	viper.SetDefault("webhooks.queueSize", defaultWebhooksQueueSize)
+	viper.SetDefault("webhooks.maxAttempts", defaultWebhooksMaxAttempts)
+	viper.SetDefault("webhooks.initialBackoff", defaultWebhooksInitialBackoff)
+	viper.SetDefault("webhooks.maxBackoff", defaultWebhooksMaxBackoff)
+	viper.SetDefault("webhooks.timeout", defaultWebhooksTimeout)
+	viper.SetDefault("alerts.queueSize", defaultAlertsQueueSize)
+	viper.SetDefault("stream.bufferSize", defaultStreamBufferSize)
+	viper.SetDefault("health.checkInterval", defaultHealthCheckInterval)
+	viper.SetDefault("health.defaultReportInterval", defaultHealthReportInterval)
+	viper.SetDefault("health.lateFactor", defaultHealthLateFactor)
+	viper.SetDefault("health.offlineFactor", defaultHealthOfflineFactor)
+	viper.SetDefault("forecast.maxHours", defaultForecastMaxHours)
+	viper.SetDefault("forecast.stationRadius", defaultForecastStationRadius)
+	viper.SetDefault("forecast.biasRadius", defaultForecastBiasRadius)
+	viper.SetDefault("forecast.biasHours", defaultForecastBiasHours)
+	viper.SetDefault("climate.years", defaultClimateYears)
+	viper.SetDefault("climate.minYears", defaultClimateMinYears)
+	viper.SetDefault("climate.minDailyObservations", defaultClimateMinDailyObs)
+	viper.SetDefault("climate.minMonthCoverage", defaultClimateMonthCoverage)
+	viper.SetDefault("climate.dailyWindow", defaultClimateDailyWindow)
 }
 
 func parseEnv() error {
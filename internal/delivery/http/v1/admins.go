@@ -0,0 +1,336 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (h *Handler) initAdminRoutes(api *gin.RouterGroup) {
+	admins := api.Group("/admins")
+	{
+		admins.POST("/sign-in", h.adminSignIn)
+		admins.POST("/auth/refresh", h.adminRefresh)
+
+		authenticated := admins.Group("/", h.adminIdentity)
+		{
+			stations := authenticated.Group("/stations")
+			{
+				stations.POST("", h.authorize(domain.PermissionStationsManage), h.adminCreateStation)
+				stations.GET("", h.authorize(domain.PermissionStationsRead), h.adminGetAllStations)
+				stations.GET("/uptime", h.authorize(domain.PermissionStationsRead), h.adminGetStationsUptime)
+				stations.GET("/:id", h.authorizeStation(domain.PermissionStationsRead, h.stationById), h.adminGetStationById)
+				stations.PUT("/:id", h.authorizeStation(domain.PermissionStationsWrite, h.stationById), h.adminUpdateStation)
+				stations.DELETE("/:id", h.authorize(domain.PermissionStationsManage), h.adminDeleteStation)
+			}
+
+			observations := authenticated.Group("/observations", h.authorize(domain.PermissionObservationsWrite))
+			{
+				observations.POST("/import", h.adminImportObservations)
+				observations.PUT("/:id/qc", h.adminOverrideQualityFlag)
+			}
+
+			forecasts := authenticated.Group("/forecasts", h.authorize(domain.PermissionForecastsWrite))
+			{
+				forecasts.POST("/taf", h.adminCreateTerminalForecast)
+			}
+
+			climate := authenticated.Group("/climate", h.authorize(domain.PermissionClimateManage))
+			{
+				climate.POST("/normals/jobs", h.adminRecomputeNormals)
+				climate.GET("/normals/jobs/:id", h.adminGetNormalsJob)
+			}
+
+			users := authenticated.Group("/users", h.authorize(domain.PermissionUsersManage))
+			{
+				users.PUT("/:id/grants", h.adminSetUserGrants)
+			}
+
+			h.initAdminAPIKeysRoutes(authenticated)
+
+			accounts := authenticated.Group("/accounts", h.authorize(domain.PermissionAdminsManage), h.superAdminIdentity)
+			{
+				accounts.POST("", h.adminCreateAdmin)
+				accounts.GET("", h.adminGetAllAdmins)
+				accounts.GET("/:id", h.adminGetAdminById)
+				accounts.PUT("/:id", h.adminUpdateAdmin)
+				accounts.DELETE("/:id", h.adminDeleteAdmin)
+			}
+		}
+	}
+}
+
+// @Summary Admin SignIn
+// @Tags admins-auth
+// @Description admin sign in
+// @ModuleID adminSignIn
+// @Accept  json
+// @Produce  json
+// @Param input body signInInput true "sign in info"
+// @Success 200 {object} tokenResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/sign-in [post]
+func (h *Handler) adminSignIn(c *gin.Context) {
+	var inp signInInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	res, err := h.services.Admins.SignIn(c.Request.Context(), service.SignInInput{
+		Email:    inp.Email,
+		Password: inp.Password,
+	})
+	if err != nil {
+		if err == service.ErrAdminNotFound {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse{
+		AccessToken:  res.AccessToken,
+		RefreshToken: res.RefreshToken,
+	})
+}
+
+// @Summary Admin Refresh Tokens
+// @Tags admins-auth
+// @Description admin refresh tokens
+// @Accept  json
+// @Produce  json
+// @Param input body refreshInput true "refresh info"
+// @Success 200 {object} tokenResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/auth/refresh [post]
+func (h *Handler) adminRefresh(c *gin.Context) {
+	var inp refreshInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	res, err := h.services.Admins.RefreshTokens(c.Request.Context(), inp.Token)
+	if err != nil {
+		if err == service.ErrAdminNotFound {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse{
+		AccessToken:  res.AccessToken,
+		RefreshToken: res.RefreshToken,
+	})
+}
+
+type createAdminInput struct {
+	Name       string `json:"name" binding:"required,min=2,max=64"`
+	Email      string `json:"email" binding:"required,email,max=64"`
+	Password   string `json:"password" binding:"required,min=8,max=64"`
+	SuperAdmin bool   `json:"superAdmin"`
+}
+
+// @Summary Admin Create Admin
+// @Security AdminAuth
+// @Tags admins-accounts
+// @Description super admin create admin account
+// @ModuleID adminCreateAdmin
+// @Accept  json
+// @Produce  json
+// @Param input body createAdminInput true "admin info"
+// @Success 201 {object} idResponse
+// @Failure 400,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/accounts [post]
+func (h *Handler) adminCreateAdmin(c *gin.Context) {
+	var inp createAdminInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	id, err := h.services.Admins.Create(c.Request.Context(), service.CreateAdminInput{
+		Name:       inp.Name,
+		Email:      inp.Email,
+		Password:   inp.Password,
+		SuperAdmin: inp.SuperAdmin,
+	})
+	if err != nil {
+		if err == service.ErrAdminAlreadyExists {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, idResponse{id})
+}
+
+// @Summary Admin Get All Admins
+// @Security AdminAuth
+// @Tags admins-accounts
+// @Description super admin get all admin accounts
+// @ModuleID adminGetAllAdmins
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} dataResponse
+// @Failure 400,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/accounts [get]
+func (h *Handler) adminGetAllAdmins(c *gin.Context) {
+	admins, err := h.services.Admins.GetAll(c.Request.Context())
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse{Data: admins, Count: int64(len(admins))})
+}
+
+// @Summary Admin Get Admin By ID
+// @Security AdminAuth
+// @Tags admins-accounts
+// @Description super admin get admin account by id
+// @ModuleID adminGetAdminById
+// @Accept  json
+// @Produce  json
+// @Param id path string true "admin id"
+// @Success 200 {object} domain.Admin
+// @Failure 400,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/accounts/{id} [get]
+func (h *Handler) adminGetAdminById(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	admin, err := h.services.Admins.GetById(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrAdminNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, admin)
+}
+
+type updateAdminInput struct {
+	Name       *string `json:"name" binding:"omitempty,min=2,max=64"`
+	Email      *string `json:"email" binding:"omitempty,email,max=64"`
+	Password   *string `json:"password" binding:"omitempty,min=8,max=64"`
+	SuperAdmin *bool   `json:"superAdmin"`
+}
+
+// @Summary Admin Update Admin
+// @Security AdminAuth
+// @Tags admins-accounts
+// @Description super admin update admin account, the last super admin can't be demoted
+// @ModuleID adminUpdateAdmin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "admin id"
+// @Param input body updateAdminInput true "admin update info"
+// @Success 200 {object} response
+// @Failure 400,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/accounts/{id} [put]
+func (h *Handler) adminUpdateAdmin(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var inp updateAdminInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.Admins.Update(c.Request.Context(), service.UpdateAdminInput{
+		ID:         id,
+		Name:       inp.Name,
+		Email:      inp.Email,
+		Password:   inp.Password,
+		SuperAdmin: inp.SuperAdmin,
+	}); err != nil {
+		if err == service.ErrAdminNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if err == service.ErrAdminAlreadyExists || err == service.ErrLastSuperAdmin {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response{"success"})
+}
+
+// @Summary Admin Delete Admin
+// @Security AdminAuth
+// @Tags admins-accounts
+// @Description super admin delete admin account, the last super admin can't be deleted
+// @ModuleID adminDeleteAdmin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "admin id"
+// @Success 200 {object} response
+// @Failure 400,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/accounts/{id} [delete]
+func (h *Handler) adminDeleteAdmin(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.services.Admins.Delete(c.Request.Context(), id); err != nil {
+		if err == service.ErrAdminNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if err == service.ErrLastSuperAdmin {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response{"success"})
+}
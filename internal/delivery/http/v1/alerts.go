@@ -0,0 +1,308 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const defaultAlertEventsLimit = 100
+
+func (h *Handler) initAlertsRoutes(authenticated *gin.RouterGroup) {
+	alerts := authenticated.Group("/alerts", h.sessionIdentity)
+	{
+		alerts.POST("", h.userCreateAlertRule)
+		alerts.GET("", h.userGetAlertRules)
+		alerts.GET("/events", h.userGetAlertEvents)
+		alerts.GET("/:id", h.userGetAlertRuleById)
+		alerts.PUT("/:id", h.userUpdateAlertRule)
+		alerts.DELETE("/:id", h.userDeleteAlertRule)
+	}
+}
+
+type createAlertRuleInput struct {
+	Name       string   `json:"name" binding:"required,min=2,max=128"`
+	StationID  string   `json:"stationId" binding:"required"`
+	Field      string   `json:"field" binding:"required"`
+	Operator   string   `json:"operator" binding:"required"`
+	Threshold  *float64 `json:"threshold" binding:"required"`
+	Hysteresis float64  `json:"hysteresis"`
+	ForSeconds int64    `json:"forSeconds"`
+}
+
+// @Summary User Create Alert Rule
+// @Security UsersAuth
+// @Tags alerts
+// @Description create alert rule firing when station observation field satisfies condition for forSeconds
+// @ModuleID userCreateAlertRule
+// @Accept  json
+// @Produce  json
+// @Param input body createAlertRuleInput true "alert rule info"
+// @Success 201 {object} idResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/alerts [post]
+func (h *Handler) userCreateAlertRule(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var inp createAlertRuleInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	id, err := h.services.Alerts.Create(c.Request.Context(), service.CreateAlertRuleInput{
+		UserID:     userId,
+		Name:       inp.Name,
+		StationID:  inp.StationID,
+		Field:      inp.Field,
+		Operator:   inp.Operator,
+		Threshold:  *inp.Threshold,
+		Hysteresis: inp.Hysteresis,
+		ForSeconds: inp.ForSeconds,
+	})
+	if err != nil {
+		if isAlertRuleInputError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, idResponse{id})
+}
+
+// @Summary User Get Alert Rules
+// @Security UsersAuth
+// @Tags alerts
+// @Description get user alert rules with their current state
+// @ModuleID userGetAlertRules
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} dataResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/alerts [get]
+func (h *Handler) userGetAlertRules(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rules, err := h.services.Alerts.GetByUser(c.Request.Context(), userId)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse{Data: rules, Count: int64(len(rules))})
+}
+
+// @Summary User Get Alert Rule By ID
+// @Security UsersAuth
+// @Tags alerts
+// @Description get user alert rule by id
+// @ModuleID userGetAlertRuleById
+// @Accept  json
+// @Produce  json
+// @Param id path string true "alert rule id"
+// @Success 200 {object} domain.AlertRule
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/alerts/{id} [get]
+func (h *Handler) userGetAlertRuleById(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	rule, err := h.services.Alerts.GetById(c.Request.Context(), userId, id)
+	if err != nil {
+		if err == service.ErrAlertRuleNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+type updateAlertRuleInput struct {
+	Name       *string  `json:"name" binding:"omitempty,min=2,max=128"`
+	Operator   *string  `json:"operator"`
+	Threshold  *float64 `json:"threshold"`
+	Hysteresis *float64 `json:"hysteresis"`
+	ForSeconds *int64   `json:"forSeconds"`
+	Enabled    *bool    `json:"enabled"`
+}
+
+// @Summary User Update Alert Rule
+// @Security UsersAuth
+// @Tags alerts
+// @Description update user alert rule
+// @ModuleID userUpdateAlertRule
+// @Accept  json
+// @Produce  json
+// @Param id path string true "alert rule id"
+// @Param input body updateAlertRuleInput true "alert rule update info"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/alerts/{id} [put]
+func (h *Handler) userUpdateAlertRule(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var inp updateAlertRuleInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.Alerts.Update(c.Request.Context(), service.UpdateAlertRuleInput{
+		ID:         id,
+		UserID:     userId,
+		Name:       inp.Name,
+		Operator:   inp.Operator,
+		Threshold:  inp.Threshold,
+		Hysteresis: inp.Hysteresis,
+		ForSeconds: inp.ForSeconds,
+		Enabled:    inp.Enabled,
+	}); err != nil {
+		if err == service.ErrAlertRuleNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if isAlertRuleInputError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response{"success"})
+}
+
+// @Summary User Delete Alert Rule
+// @Security UsersAuth
+// @Tags alerts
+// @Description delete user alert rule
+// @ModuleID userDeleteAlertRule
+// @Accept  json
+// @Produce  json
+// @Param id path string true "alert rule id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/alerts/{id} [delete]
+func (h *Handler) userDeleteAlertRule(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.services.Alerts.Delete(c.Request.Context(), userId, id); err != nil {
+		if err == service.ErrAlertRuleNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response{"success"})
+}
+
+type alertEventsQuery struct {
+	Limit int64 `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// @Summary User Get Alert Events
+// @Security UsersAuth
+// @Tags alerts
+// @Description get the latest firing and resolved events of user alert rules, newest first
+// @ModuleID userGetAlertEvents
+// @Accept  json
+// @Produce  json
+// @Param limit query int false "number of events, 100 by default"
+// @Success 200 {object} dataResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/alerts/events [get]
+func (h *Handler) userGetAlertEvents(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var query alertEventsQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultAlertEventsLimit
+	}
+
+	events, err := h.services.Alerts.GetEvents(c.Request.Context(), userId, query.Limit)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse{Data: events, Count: int64(len(events))})
+}
+
+func isAlertRuleInputError(err error) bool {
+	return errors.Is(err, service.ErrUnknownObservationField) ||
+		err == service.ErrInvalidAlertOperator ||
+		err == service.ErrInvalidAlertRule
+}
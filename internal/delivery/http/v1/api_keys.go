@@ -0,0 +1,250 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (h *Handler) initUserAPIKeysRoutes(authenticated *gin.RouterGroup) {
+	apiKeys := authenticated.Group("/api-keys", h.sessionIdentity)
+	{
+		apiKeys.POST("", h.userCreateAPIKey)
+		apiKeys.GET("", h.userGetAPIKeys)
+		apiKeys.DELETE("/:id", h.userDeleteAPIKey)
+	}
+}
+
+func (h *Handler) initAdminAPIKeysRoutes(authenticated *gin.RouterGroup) {
+	apiKeys := authenticated.Group("/api-keys", h.sessionIdentity)
+	{
+		apiKeys.POST("", h.adminCreateAPIKey)
+		apiKeys.GET("", h.adminGetAPIKeys)
+		apiKeys.DELETE("/:id", h.adminDeleteAPIKey)
+	}
+}
+
+type createAPIKeyInput struct {
+	Name       string     `json:"name" binding:"required,max=64"`
+	Scopes     []string   `json:"scopes" binding:"required,min=1"`
+	AllowedIPs []string   `json:"allowedIps"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+}
+
+type createAPIKeyResponse struct {
+	domain.APIKey
+	Key string `json:"key"`
+}
+
+// @Summary User Create API Key
+// @Security UsersAuth
+// @Tags users-api-keys
+// @Description create API key for machine clients, it's passed as "Authorization: ApiKey <key>" header.
+// @Description Key is returned only once. Scopes are read, ingest and admin, key can't exceed user roles
+// @ModuleID userCreateAPIKey
+// @Accept  json
+// @Produce  json
+// @Param input body createAPIKeyInput true "api key info"
+// @Success 201 {object} createAPIKeyResponse
+// @Failure 400,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/api-keys [post]
+func (h *Handler) userCreateAPIKey(c *gin.Context) {
+	owner, ok := getAPIKeyOwner(c, userCtx, domain.APIKeyOwnerUser)
+	if !ok {
+		return
+	}
+
+	h.createAPIKey(c, owner)
+}
+
+// @Summary User Get API Keys
+// @Security UsersAuth
+// @Tags users-api-keys
+// @Description get user API keys with usage counters
+// @ModuleID userGetAPIKeys
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} dataResponse
+// @Failure 400,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/api-keys [get]
+func (h *Handler) userGetAPIKeys(c *gin.Context) {
+	owner, ok := getAPIKeyOwner(c, userCtx, domain.APIKeyOwnerUser)
+	if !ok {
+		return
+	}
+
+	h.getAPIKeys(c, owner)
+}
+
+// @Summary User Revoke API Key
+// @Security UsersAuth
+// @Tags users-api-keys
+// @Description revoke user API key
+// @ModuleID userDeleteAPIKey
+// @Accept  json
+// @Produce  json
+// @Param id path string true "api key id"
+// @Success 200 {object} response
+// @Failure 400,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/api-keys/{id} [delete]
+func (h *Handler) userDeleteAPIKey(c *gin.Context) {
+	owner, ok := getAPIKeyOwner(c, userCtx, domain.APIKeyOwnerUser)
+	if !ok {
+		return
+	}
+
+	h.deleteAPIKey(c, owner)
+}
+
+// @Summary Admin Create API Key
+// @Security AdminAuth
+// @Tags admins-api-keys
+// @Description create API key for machine clients, it's passed as "Authorization: ApiKey <key>" header.
+// @Description Key is returned only once. Scopes are read, ingest and admin
+// @ModuleID adminCreateAPIKey
+// @Accept  json
+// @Produce  json
+// @Param input body createAPIKeyInput true "api key info"
+// @Success 201 {object} createAPIKeyResponse
+// @Failure 400,401,403 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/api-keys [post]
+func (h *Handler) adminCreateAPIKey(c *gin.Context) {
+	owner, ok := getAPIKeyOwner(c, adminCtx, domain.APIKeyOwnerAdmin)
+	if !ok {
+		return
+	}
+
+	h.createAPIKey(c, owner)
+}
+
+// @Summary Admin Get API Keys
+// @Security AdminAuth
+// @Tags admins-api-keys
+// @Description get admin API keys with usage counters
+// @ModuleID adminGetAPIKeys
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} dataResponse
+// @Failure 400,401,403 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/api-keys [get]
+func (h *Handler) adminGetAPIKeys(c *gin.Context) {
+	owner, ok := getAPIKeyOwner(c, adminCtx, domain.APIKeyOwnerAdmin)
+	if !ok {
+		return
+	}
+
+	h.getAPIKeys(c, owner)
+}
+
+// @Summary Admin Revoke API Key
+// @Security AdminAuth
+// @Tags admins-api-keys
+// @Description revoke admin API key
+// @ModuleID adminDeleteAPIKey
+// @Accept  json
+// @Produce  json
+// @Param id path string true "api key id"
+// @Success 200 {object} response
+// @Failure 400,401,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/api-keys/{id} [delete]
+func (h *Handler) adminDeleteAPIKey(c *gin.Context) {
+	owner, ok := getAPIKeyOwner(c, adminCtx, domain.APIKeyOwnerAdmin)
+	if !ok {
+		return
+	}
+
+	h.deleteAPIKey(c, owner)
+}
+
+func (h *Handler) createAPIKey(c *gin.Context, owner domain.APIKeyOwner) {
+	var inp createAPIKeyInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	key, secret, err := h.services.APIKeys.Create(c.Request.Context(), service.CreateAPIKeyInput{
+		Owner:      owner,
+		Name:       inp.Name,
+		Scopes:     inp.Scopes,
+		AllowedIPs: inp.AllowedIPs,
+		ExpiresAt:  inp.ExpiresAt,
+	})
+	if err != nil {
+		if err == service.ErrUserNotFound || err == service.ErrAdminNotFound {
+			newResponse(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if isAPIKeyInputError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, createAPIKeyResponse{APIKey: key, Key: secret})
+}
+
+func (h *Handler) getAPIKeys(c *gin.Context, owner domain.APIKeyOwner) {
+	keys, err := h.services.APIKeys.GetByOwner(c.Request.Context(), owner)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse{Data: keys, Count: int64(len(keys))})
+}
+
+func (h *Handler) deleteAPIKey(c *gin.Context, owner domain.APIKeyOwner) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.services.APIKeys.Delete(c.Request.Context(), owner, id); err != nil {
+		if err == service.ErrAPIKeyNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response{"success"})
+}
+
+// getAPIKeyOwner responds with error itself, handlers just return if owner wasn't resolved
+func getAPIKeyOwner(c *gin.Context, context, ownerType string) (domain.APIKeyOwner, bool) {
+	id, err := getIdByContext(c, context)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return domain.APIKeyOwner{}, false
+	}
+
+	return domain.APIKeyOwner{ID: id, Type: ownerType}, true
+}
+
+func isAPIKeyInputError(err error) bool {
+	return err == service.ErrUnknownAPIKeyScope || err == service.ErrInvalidAllowedIP || err == service.ErrInvalidAPIKeyExpiry
+}
@@ -0,0 +1,340 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const defaultWebhookDeliveriesLimit = 100
+
+// initCallbackRoutes registers management of user webhooks, the outgoing callbacks of the service.
+// Webhooks are managed by signed in users only, API keys can't create them
+func (h *Handler) initCallbackRoutes(api *gin.RouterGroup) {
+	callbacks := api.Group("/callback", h.userIdentity, h.sessionIdentity, h.authorize(domain.PermissionDataRead))
+	{
+		webhooks := callbacks.Group("/webhooks")
+		{
+			webhooks.POST("", h.userCreateWebhook)
+			webhooks.GET("", h.userGetWebhooks)
+			webhooks.GET("/:id", h.userGetWebhookById)
+			webhooks.PUT("/:id", h.userUpdateWebhook)
+			webhooks.DELETE("/:id", h.userDeleteWebhook)
+			webhooks.GET("/:id/deliveries", h.userGetWebhookDeliveries)
+			webhooks.POST("/:id/ping", h.userPingWebhook)
+		}
+	}
+}
+
+type createWebhookInput struct {
+	URL        string   `json:"url" binding:"required,url"`
+	Events     []string `json:"events" binding:"required,min=1"`
+	StationIDs []string `json:"stationIds"`
+}
+
+type createWebhookResponse struct {
+	ID     primitive.ObjectID `json:"id"`
+	Secret string             `json:"secret"`
+}
+
+// @Summary User Create Webhook
+// @Security UsersAuth
+// @Tags webhooks
+// @Description register HTTPS endpoint receiving subscribed events. Deliveries are signed with returned secret:
+// @Description X-Meteo-Signature is "sha256=" + hex HMAC-SHA256 of X-Meteo-Timestamp + "." + body
+// @ModuleID userCreateWebhook
+// @Accept  json
+// @Produce  json
+// @Param input body createWebhookInput true "webhook info"
+// @Success 201 {object} createWebhookResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /callback/webhooks [post]
+func (h *Handler) userCreateWebhook(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var inp createWebhookInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	webhook, err := h.services.Webhooks.Create(c.Request.Context(), service.CreateWebhookInput{
+		UserID:     userId,
+		URL:        inp.URL,
+		Events:     inp.Events,
+		StationIDs: inp.StationIDs,
+	})
+	if err != nil {
+		if isWebhookInputError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, createWebhookResponse{ID: webhook.ID, Secret: webhook.Secret})
+}
+
+// @Summary User Get Webhooks
+// @Security UsersAuth
+// @Tags webhooks
+// @Description get user webhooks
+// @ModuleID userGetWebhooks
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} dataResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /callback/webhooks [get]
+func (h *Handler) userGetWebhooks(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	webhooks, err := h.services.Webhooks.GetByUser(c.Request.Context(), userId)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse{Data: webhooks, Count: int64(len(webhooks))})
+}
+
+// @Summary User Get Webhook By ID
+// @Security UsersAuth
+// @Tags webhooks
+// @Description get user webhook by id
+// @ModuleID userGetWebhookById
+// @Accept  json
+// @Produce  json
+// @Param id path string true "webhook id"
+// @Success 200 {object} domain.Webhook
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /callback/webhooks/{id} [get]
+func (h *Handler) userGetWebhookById(c *gin.Context) {
+	userId, id, ok := parseUserWebhookIds(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.services.Webhooks.GetById(c.Request.Context(), userId, id)
+	if err != nil {
+		if err == service.ErrWebhookNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+type updateWebhookInput struct {
+	URL        *string  `json:"url" binding:"omitempty,url"`
+	Events     []string `json:"events" binding:"omitempty,min=1"`
+	StationIDs []string `json:"stationIds"`
+	Enabled    *bool    `json:"enabled"`
+}
+
+// @Summary User Update Webhook
+// @Security UsersAuth
+// @Tags webhooks
+// @Description update user webhook
+// @ModuleID userUpdateWebhook
+// @Accept  json
+// @Produce  json
+// @Param id path string true "webhook id"
+// @Param input body updateWebhookInput true "webhook update info"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /callback/webhooks/{id} [put]
+func (h *Handler) userUpdateWebhook(c *gin.Context) {
+	userId, id, ok := parseUserWebhookIds(c)
+	if !ok {
+		return
+	}
+
+	var inp updateWebhookInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.Webhooks.Update(c.Request.Context(), service.UpdateWebhookInput{
+		ID:         id,
+		UserID:     userId,
+		URL:        inp.URL,
+		Events:     inp.Events,
+		StationIDs: inp.StationIDs,
+		Enabled:    inp.Enabled,
+	}); err != nil {
+		if err == service.ErrWebhookNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if isWebhookInputError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response{"success"})
+}
+
+// @Summary User Delete Webhook
+// @Security UsersAuth
+// @Tags webhooks
+// @Description delete user webhook
+// @ModuleID userDeleteWebhook
+// @Accept  json
+// @Produce  json
+// @Param id path string true "webhook id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /callback/webhooks/{id} [delete]
+func (h *Handler) userDeleteWebhook(c *gin.Context) {
+	userId, id, ok := parseUserWebhookIds(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Webhooks.Delete(c.Request.Context(), userId, id); err != nil {
+		if err == service.ErrWebhookNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response{"success"})
+}
+
+type webhookDeliveriesQuery struct {
+	Limit int64 `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// @Summary User Get Webhook Deliveries
+// @Security UsersAuth
+// @Tags webhooks
+// @Description get the latest delivery attempts of user webhook, newest first
+// @ModuleID userGetWebhookDeliveries
+// @Accept  json
+// @Produce  json
+// @Param id path string true "webhook id"
+// @Param limit query int false "number of attempts, 100 by default"
+// @Success 200 {object} dataResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /callback/webhooks/{id}/deliveries [get]
+func (h *Handler) userGetWebhookDeliveries(c *gin.Context) {
+	userId, id, ok := parseUserWebhookIds(c)
+	if !ok {
+		return
+	}
+
+	var query webhookDeliveriesQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultWebhookDeliveriesLimit
+	}
+
+	deliveries, err := h.services.Webhooks.GetDeliveries(c.Request.Context(), userId, id, query.Limit)
+	if err != nil {
+		if err == service.ErrWebhookNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse{Data: deliveries, Count: int64(len(deliveries))})
+}
+
+// @Summary User Ping Webhook
+// @Security UsersAuth
+// @Tags webhooks
+// @Description queue ping event delivery to user webhook
+// @ModuleID userPingWebhook
+// @Accept  json
+// @Produce  json
+// @Param id path string true "webhook id"
+// @Success 202 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /callback/webhooks/{id}/ping [post]
+func (h *Handler) userPingWebhook(c *gin.Context) {
+	userId, id, ok := parseUserWebhookIds(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Webhooks.Ping(c.Request.Context(), userId, id); err != nil {
+		if err == service.ErrWebhookNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response{domain.WebhookEventPing + " queued"})
+}
+
+// parseUserWebhookIds responds with error itself, handlers just return if ids weren't parsed
+func parseUserWebhookIds(c *gin.Context) (primitive.ObjectID, primitive.ObjectID, bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return primitive.ObjectID{}, primitive.ObjectID{}, false
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return primitive.ObjectID{}, primitive.ObjectID{}, false
+	}
+
+	return userId, id, true
+}
+
+func isWebhookInputError(err error) bool {
+	return err == service.ErrInvalidWebhookURL || err == service.ErrWebhookURLNotPublic ||
+		err == service.ErrInvalidWebhookEvent
+}
@@ -0,0 +1,264 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/units"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (h *Handler) initClimateRoutes(api *gin.RouterGroup) {
+	climate := api.Group("/climate", h.userIdentity, h.authorizeStation(domain.PermissionDataRead, queryStation))
+	{
+		climate.GET("/normals", h.getNormals)
+		climate.GET("/anomalies", h.getAnomalies)
+	}
+}
+
+type normalsQuery struct {
+	Station  string `form:"station" binding:"required"`
+	Period   string `form:"period" binding:"omitempty,oneof=daily monthly"`
+	Elements string `form:"elements"`
+	Month    int    `form:"month" binding:"omitempty,min=1,max=12"`
+	Day      int    `form:"day" binding:"omitempty,min=1,max=31"`
+	Units    string `form:"units"`
+}
+
+type normalsResponse struct {
+	Data  []domain.Normal `json:"data"`
+	Units units.System    `json:"units"`
+	Count int64           `json:"count"`
+}
+
+// @Summary Get Normals
+// @Security UsersAuth
+// @Tags climate
+// @Description get climatological normals of the station with percentiles at every 5th percent from 0 to 100
+// @ModuleID getNormals
+// @Accept  json
+// @Produce  json
+// @Param station query string true "station identifier"
+// @Param period query string false "daily or monthly, daily by default"
+// @Param elements query string false "comma-separated elements: temperature, temperatureMin, temperatureMax, humidity, pressure, windSpeed, windGust, precipitation"
+// @Param month query int false "month, all by default"
+// @Param day query int false "day of month, all by default"
+// @Param units query string false "metric or imperial, preferred units by default"
+// @Success 200 {object} normalsResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /climate/normals [get]
+func (h *Handler) getNormals(c *gin.Context) {
+	var query normalsQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	if query.Period == "" {
+		query.Period = domain.NormalPeriodDaily
+	}
+
+	presentation, ok := h.getPresentation(c, query.Units)
+	if !ok {
+		return
+	}
+
+	normals, err := h.services.Climate.Normals(c.Request.Context(), service.NormalsInput{
+		StationID: query.Station,
+		Period:    query.Period,
+		Elements:  splitQueryList(query.Elements),
+		Month:     query.Month,
+		Day:       query.Day,
+	})
+	if err != nil {
+		if err == service.ErrStationNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if isClimateQueryError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	presentation.Normals(normals)
+
+	c.JSON(http.StatusOK, normalsResponse{Data: normals, Units: presentation.Units, Count: int64(len(normals))})
+}
+
+type anomaliesQuery struct {
+	Station  string    `form:"station" binding:"required"`
+	Period   string    `form:"period" binding:"omitempty,oneof=daily monthly"`
+	Elements string    `form:"elements"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Units    string    `form:"units"`
+}
+
+type anomaliesResponse struct {
+	Data  []service.Anomaly `json:"data"`
+	Units units.System      `json:"units"`
+	Count int64             `json:"count"`
+}
+
+// @Summary Get Anomalies
+// @Security UsersAuth
+// @Tags climate
+// @Description get differences of observed daily or monthly values from normals and their percentile ranks
+// @ModuleID getAnomalies
+// @Accept  json
+// @Produce  json
+// @Param station query string true "station identifier"
+// @Param period query string false "daily or monthly, daily by default"
+// @Param elements query string false "comma-separated elements: temperature, temperatureMin, temperatureMax, humidity, pressure, windSpeed, windGust, precipitation"
+// @Param from query string false "RFC3339 start of time range, today or the current month by default"
+// @Param to query string false "RFC3339 end of time range (exclusive), now by default"
+// @Param units query string false "metric or imperial, preferred units by default"
+// @Success 200 {object} anomaliesResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /climate/anomalies [get]
+func (h *Handler) getAnomalies(c *gin.Context) {
+	var query anomaliesQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	presentation, ok := h.getPresentation(c, query.Units)
+	if !ok {
+		return
+	}
+
+	anomalies, err := h.services.Climate.Anomalies(c.Request.Context(), service.AnomaliesInput{
+		StationID: query.Station,
+		Period:    query.Period,
+		Elements:  splitQueryList(query.Elements),
+		From:      query.From,
+		To:        query.To,
+	})
+	if err != nil {
+		if err == service.ErrStationNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if isClimateQueryError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	presentation.Anomalies(anomalies)
+
+	c.JSON(http.StatusOK, anomaliesResponse{Data: anomalies, Units: presentation.Units, Count: int64(len(anomalies))})
+}
+
+type recomputeNormalsInput struct {
+	Stations []string `json:"stations"`
+	FromYear int      `json:"fromYear" binding:"omitempty,min=0"`
+	ToYear   int      `json:"toYear" binding:"omitempty,min=0"`
+}
+
+// @Summary Admin Recompute Normals
+// @Security AdminAuth
+// @Tags admins-climate
+// @Description admin queue background recomputation of normals, all stations and configured reference period by default
+// @ModuleID adminRecomputeNormals
+// @Accept  json
+// @Produce  json
+// @Param input body recomputeNormalsInput true "stations and reference period"
+// @Success 202 {object} idResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/climate/normals/jobs [post]
+func (h *Handler) adminRecomputeNormals(c *gin.Context) {
+	var inp recomputeNormalsInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	id, err := h.services.Climate.Recompute(c.Request.Context(), service.RecomputeNormalsInput{
+		StationIDs: inp.Stations,
+		FromYear:   inp.FromYear,
+		ToYear:     inp.ToYear,
+	})
+	if err != nil {
+		if err == service.ErrStationNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if err == service.ErrInvalidReferencePeriod {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err == service.ErrNormalsQueueFull {
+			newResponse(c, http.StatusTooManyRequests, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, idResponse{id})
+}
+
+// @Summary Admin Get Normals Job
+// @Security AdminAuth
+// @Tags admins-climate
+// @Description admin get status and progress of normals recomputation
+// @ModuleID adminGetNormalsJob
+// @Accept  json
+// @Produce  json
+// @Param id path string true "job id"
+// @Success 200 {object} domain.NormalsJob
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/climate/normals/jobs/{id} [get]
+func (h *Handler) adminGetNormalsJob(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	job, err := h.services.Climate.GetJob(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrNormalsJobNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func isClimateQueryError(err error) bool {
+	return errors.Is(err, service.ErrUnknownNormalElement) ||
+		err == service.ErrInvalidNormalsPeriod ||
+		err == service.ErrInvalidTimeRange ||
+		err == service.ErrTooLongTimeRange
+}
@@ -0,0 +1,118 @@
+package v1
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/logger"
+)
+
+type exportFormat struct {
+	contentType string
+	extension   string
+}
+
+var exportFormats = map[string]exportFormat{
+	service.ExportFormatCSV:    {"text/csv; charset=utf-8", "csv"},
+	service.ExportFormatNDJSON: {"application/x-ndjson", "ndjson"},
+	service.ExportFormatCFJSON: {"application/json", "json"},
+}
+
+// exportMediaTypes maps Accept header media types to export formats
+var exportMediaTypes = map[string]string{
+	"text/csv":             service.ExportFormatCSV,
+	"application/x-ndjson": service.ExportFormatNDJSON,
+	"application/jsonl":    service.ExportFormatNDJSON,
+	"application/json":     service.ExportFormatCFJSON,
+}
+
+type exportObservationsQuery struct {
+	Station string    `form:"station" binding:"required"`
+	From    time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To      time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Fields  string    `form:"fields"`
+	QC      string    `form:"qc"`
+	Format  string    `form:"format" binding:"omitempty,oneof=csv ndjson cf-json"`
+}
+
+// @Summary Export Observations
+// @Security UsersAuth
+// @Tags observations
+// @Description stream station observations as CSV, NDJSON or CF-JSON.
+// @Description Format query param takes precedence over Accept header, CSV is used by default
+// @ModuleID exportObservations
+// @Produce  text/csv,application/x-ndjson,application/json
+// @Param station query string true "station identifier"
+// @Param from query string false "RFC3339 start of time range (inclusive)"
+// @Param to query string false "RFC3339 end of time range (exclusive)"
+// @Param fields query string false "comma-separated observation fields, all by default"
+// @Param qc query string false "comma-separated quality flags of exported values: good, suspect, bad, unchecked. All but bad by default"
+// @Param format query string false "csv, ndjson or cf-json"
+// @Success 200 {file} file
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /observations/export [get]
+func (h *Handler) exportObservations(c *gin.Context) {
+	var query exportObservationsQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	format := query.Format
+	if format == "" {
+		format = negotiateExportFormat(c.GetHeader("Accept"))
+	}
+
+	c.Header("Content-Type", exportFormats[format].contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, query.Station, exportFormats[format].extension))
+
+	err := h.services.Export.Export(c.Request.Context(), c.Writer, service.ExportInput{
+		StationID: query.Station,
+		From:      query.From,
+		To:        query.To,
+		Fields:    splitQueryList(query.Fields),
+		Quality:   splitQueryList(query.QC),
+		Format:    format,
+	})
+	if err == nil {
+		return
+	}
+
+	// once streaming started the status is already sent, so the error can only be logged
+	if c.Writer.Written() {
+		logger.Errorf("export of station %s observations interrupted: %s", query.Station, err.Error())
+		return
+	}
+
+	c.Writer.Header().Del("Content-Type")
+	c.Writer.Header().Del("Content-Disposition")
+
+	if isObservationsQueryError(err) {
+		newResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	newResponse(c, http.StatusInternalServerError, err.Error())
+}
+
+func negotiateExportFormat(accept string) string {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		if format, ok := exportMediaTypes[mediaType]; ok {
+			return format
+		}
+	}
+
+	return service.ExportFormatCSV
+}
@@ -0,0 +1,211 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+)
+
+func (h *Handler) initForecastsRoutes(api *gin.RouterGroup) {
+	forecasts := api.Group("/forecasts", h.userIdentity, h.authorizeStation(domain.PermissionDataRead, queryStation))
+	{
+		forecasts.GET("/taf", h.getTerminalForecastInEffect)
+		forecasts.GET("/model", h.getStationModelForecast)
+	}
+
+	api.GET("/forecast", h.userIdentity, h.authorize(domain.PermissionDataRead), h.getPointForecast)
+}
+
+type pointForecastQuery struct {
+	Latitude  *float64 `form:"lat" binding:"required,min=-90,max=90"`
+	Longitude *float64 `form:"lon" binding:"required,min=-180,max=180"`
+	Hours     int      `form:"hours" binding:"omitempty,min=1"`
+	Model     string   `form:"model"`
+}
+
+// @Summary Get Point Forecast
+// @Security UsersAuth
+// @Tags forecasts
+// @Description get hourly forecast at the point from the latest model run corrected by recent bias at the nearest station
+// @ModuleID getPointForecast
+// @Accept  json
+// @Produce  json
+// @Param lat query number true "latitude"
+// @Param lon query number true "longitude"
+// @Param hours query int false "number of forecast hours, 48 by default"
+// @Param model query string false "model name, configured model by default"
+// @Success 200 {object} service.PointForecast
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /forecast [get]
+func (h *Handler) getPointForecast(c *gin.Context) {
+	var query pointForecastQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	forecast, err := h.services.PointForecasts.Get(c.Request.Context(), service.PointForecastInput{
+		Latitude:  *query.Latitude,
+		Longitude: *query.Longitude,
+		Hours:     query.Hours,
+		Model:     query.Model,
+	})
+	if err != nil {
+		if err == service.ErrModelRunNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if err == service.ErrOutsideModelGrid || err == service.ErrTooManyForecastHours {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+type terminalForecastQuery struct {
+	Station string    `form:"station" binding:"required,len=4"`
+	At      time.Time `form:"at" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// @Summary Get Terminal Forecast In Effect
+// @Security UsersAuth
+// @Tags forecasts
+// @Description get TAF conditions forecast for the station at the given time
+// @ModuleID getTerminalForecastInEffect
+// @Accept  json
+// @Produce  json
+// @Param station query string true "station ICAO identifier"
+// @Param at query string false "RFC3339 time, now by default"
+// @Success 200 {object} domain.ForecastInEffect
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /forecasts/taf [get]
+func (h *Handler) getTerminalForecastInEffect(c *gin.Context) {
+	var query terminalForecastQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	if query.At.IsZero() {
+		query.At = time.Now()
+	}
+
+	forecast, err := h.services.TerminalForecasts.GetInEffect(c.Request.Context(), query.Station, query.At.UTC())
+	if err != nil {
+		if err == service.ErrForecastNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+type createTerminalForecastInput struct {
+	Raw string `json:"raw" binding:"required"`
+}
+
+// @Summary Admin Create Terminal Forecast
+// @Security AdminAuth
+// @Tags admins-forecasts
+// @Description admin store raw TAF
+// @ModuleID adminCreateTerminalForecast
+// @Accept  json
+// @Produce  json
+// @Param input body createTerminalForecastInput true "raw TAF"
+// @Success 201 {object} idResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/forecasts/taf [post]
+func (h *Handler) adminCreateTerminalForecast(c *gin.Context) {
+	var inp createTerminalForecastInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	id, err := h.services.TerminalForecasts.Create(c.Request.Context(), inp.Raw)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidForecast) || err == service.ErrForecastAlreadyExists {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, idResponse{id})
+}
+
+type stationModelForecastQuery struct {
+	Station string    `form:"station" binding:"required"`
+	Model   string    `form:"model"`
+	From    time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To      time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// @Summary Get Station Model Forecast
+// @Security UsersAuth
+// @Tags forecasts
+// @Description get forecast of the latest NWP model run interpolated at the station
+// @ModuleID getStationModelForecast
+// @Accept  json
+// @Produce  json
+// @Param station query string true "station WMO or ICAO identifier"
+// @Param model query string false "model name, any model by default"
+// @Param from query string false "RFC3339 start of forecast period, current hour by default"
+// @Param to query string false "RFC3339 end of forecast period, 48 hours after its start by default"
+// @Success 200 {object} service.PointModelForecast
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /forecasts/model [get]
+func (h *Handler) getStationModelForecast(c *gin.Context) {
+	var query stationModelForecastQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	forecast, err := h.services.Models.AtStation(c.Request.Context(), service.StationModelForecastInput{
+		Model:     query.Model,
+		StationID: query.Station,
+		From:      query.From,
+		To:        query.To,
+	})
+	if err != nil {
+		if err == service.ErrStationNotFound || err == service.ErrModelRunNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if err == service.ErrOutsideModelGrid || err == service.ErrInvalidTimeRange {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
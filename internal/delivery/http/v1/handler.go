@@ -24,6 +24,11 @@ func (h *Handler) Init(api *gin.RouterGroup) {
 		h.initUsersRoutes(v1)
 		h.initCallbackRoutes(v1)
 		h.initAdminRoutes(v1)
+		h.initObservationsRoutes(v1)
+		h.initStationsRoutes(v1)
+		h.initClimateRoutes(v1)
+		h.initForecastsRoutes(v1)
+		h.initStreamRoutes(v1)
 
 		// TODO: check this
 		/*
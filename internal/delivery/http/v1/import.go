@@ -0,0 +1,118 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+)
+
+const (
+	importFileField    = "file"
+	maxImportFieldSize = 4096
+)
+
+// @Summary Admin Import Observations
+// @Security AdminAuth
+// @Tags admins-observations
+// @Description admin import historical observations from CSV or NDJSON file.
+// @Description Form fields must precede the file. Observations already stored for the same station and time are skipped
+// @ModuleID adminImportObservations
+// @Accept  mpfd
+// @Produce  json
+// @Param format formData string true "csv or ndjson"
+// @Param mapping formData string false "observation fields to columns mapping, e.g. temperature=t,humidity=rh"
+// @Param station formData string false "station id for rows without station"
+// @Param timeFormat formData string false "unix, unixms or Go time layout, RFC3339 by default"
+// @Param batchSize formData int false "insert batch size"
+// @Param file formData file true "observations file"
+// @Success 200 {object} service.ImportReport
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/observations/import [post]
+func (h *Handler) adminImportObservations(c *gin.Context) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "expected multipart form")
+		return
+	}
+
+	fields := make(map[string]string)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			newResponse(c, http.StatusBadRequest, "file is missing")
+			return
+		}
+
+		if err != nil {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if part.FormName() != importFileField {
+			value, err := ioutil.ReadAll(io.LimitReader(part, maxImportFieldSize))
+			if err != nil {
+				newResponse(c, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			fields[part.FormName()] = string(value)
+			continue
+		}
+
+		inp, err := toImportInput(fields)
+		if err != nil {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		report, err := h.services.Import.Import(c.Request.Context(), part, inp)
+		if err != nil {
+			if isImportInputError(err) {
+				newResponse(c, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			newResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+		return
+	}
+}
+
+func toImportInput(fields map[string]string) (service.ImportInput, error) {
+	mapping, err := service.ParseColumnMapping(fields["mapping"])
+	if err != nil {
+		return service.ImportInput{}, err
+	}
+
+	inp := service.ImportInput{
+		Format:     fields["format"],
+		Mapping:    mapping,
+		StationID:  fields["station"],
+		TimeFormat: fields["timeFormat"],
+	}
+
+	if batchSize := fields["batchSize"]; batchSize != "" {
+		inp.BatchSize, err = strconv.Atoi(batchSize)
+		if err != nil {
+			return service.ImportInput{}, errors.New("invalid batch size")
+		}
+	}
+
+	return inp, nil
+}
+
+func isImportInputError(err error) bool {
+	return errors.Is(err, service.ErrInvalidColumnMapping) ||
+		err == service.ErrUnknownImportFormat ||
+		err == service.ErrEmptyImport
+}
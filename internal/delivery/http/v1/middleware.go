@@ -0,0 +1,308 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/auth"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	authorizationHeader = "Authorization"
+	accessTokenParam    = "access_token"
+
+	bearerScheme = "Bearer"
+	apiKeyScheme = "ApiKey"
+
+	userCtx        = "userId"
+	adminCtx       = "adminId"
+	grantsCtx      = "grants"
+	apiKeyCtx      = "apiKey"
+	apiKeyOwnerCtx = "apiKeyOwner"
+	sessionCtx     = "sessionId"
+)
+
+// apiKeyOwner is identity of API key owner waiting for the key scopes to be checked
+type apiKeyOwner struct {
+	context string
+	id      string
+	grants  []domain.Grant
+}
+
+func (h *Handler) userIdentity(c *gin.Context) {
+	h.identify(c, userCtx)
+}
+
+// adminIdentity lets through anyone signed in, routes under it are guarded with authorize
+func (h *Handler) adminIdentity(c *gin.Context) {
+	h.identify(c, adminCtx)
+}
+
+// identify authenticates request with access token or API key from Authorization header
+func (h *Handler) identify(c *gin.Context, context string) {
+	scheme, credentials, err := parseAuthHeader(c)
+	if err != nil {
+		newResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if scheme == apiKeyScheme {
+		h.apiKeyIdentity(c, context, credentials)
+		return
+	}
+
+	claims, err := h.tokenManager.Parse(credentials)
+	if err != nil {
+		newResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	setIdentity(c, context, claims)
+}
+
+// apiKeyIdentity authenticates API key. Identity of the key owner is set by authorize or authorizeStation
+// once the key scopes allow their permission, so handlers without permission check get no identity
+func (h *Handler) apiKeyIdentity(c *gin.Context, context, secret string) {
+	identity, err := h.services.APIKeys.Authenticate(c.Request.Context(), secret, c.ClientIP())
+	if err != nil {
+		if err == service.ErrInvalidAPIKey {
+			newResponse(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if err == service.ErrAPIKeyIPNotAllowed {
+			newResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Set(apiKeyCtx, identity.Key)
+	c.Set(apiKeyOwnerCtx, apiKeyOwner{context: context, id: identity.Key.Owner.ID.Hex(), grants: identity.Grants})
+}
+
+// sessionIdentity rejects requests authenticated with API key, it goes after identity middleware
+func (h *Handler) sessionIdentity(c *gin.Context) {
+	if _, ok := c.Get(apiKeyCtx); ok {
+		newResponse(c, http.StatusForbidden, "api keys can't be used here")
+	}
+}
+
+func setIdentity(c *gin.Context, context string, claims auth.Claims) {
+	grants := service.ClaimGrants(claims)
+	// tokens issued before roles were introduced carry no roles, their holders are viewers
+	if len(grants) == 0 {
+		grants = []domain.Grant{{Role: domain.RoleViewer}}
+	}
+
+	c.Set(context, claims.Subject)
+	c.Set(grantsCtx, grants)
+
+	if claims.SessionID != "" {
+		c.Set(sessionCtx, claims.SessionID)
+	}
+}
+
+// authorize lets through only requests with the permission granted globally. Roles come from access token,
+// so there is no database lookup. Requests with API key also need the permission in key scopes
+func (h *Handler) authorize(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !checkScopes(c, permission) || !domain.Allows(getGrants(c), permission) {
+			newResponse(c, http.StatusForbidden, "not enough permissions")
+		}
+	}
+}
+
+// stationResolver returns identifiers of the station request is about. It responds with error itself
+// and returns false if the station can't be resolved
+type stationResolver func(c *gin.Context) ([]string, bool)
+
+// authorizeStation lets through requests with the permission granted globally or for the station,
+// the station is resolved only if there is no global grant
+func (h *Handler) authorizeStation(permission string, resolve stationResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !checkScopes(c, permission) {
+			newResponse(c, http.StatusForbidden, "not enough permissions")
+			return
+		}
+
+		grants := getGrants(c)
+		if domain.Allows(grants, permission) {
+			return
+		}
+
+		identifiers, ok := resolve(c)
+		if !ok {
+			return
+		}
+
+		for _, identifier := range identifiers {
+			if domain.Allows(grants, permission, identifier) {
+				return
+			}
+		}
+
+		newResponse(c, http.StatusForbidden, "not enough permissions")
+	}
+}
+
+// queryStation resolves station from station query param, requests for all stations need global grant
+func queryStation(c *gin.Context) ([]string, bool) {
+	if station := c.Query("station"); station != "" {
+		return []string{station}, true
+	}
+
+	return nil, true
+}
+
+// stationById resolves station from id path param
+func (h *Handler) stationById(c *gin.Context) ([]string, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return nil, false
+	}
+
+	station, err := h.services.Stations.GetById(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrStationNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return nil, false
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+
+	return station.Identifiers(), true
+}
+
+// superAdminIdentity lets only super admins through, it goes after adminIdentity
+func (h *Handler) superAdminIdentity(c *gin.Context) {
+	id, err := getAdminId(c)
+	if err != nil {
+		newResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	admin, err := h.services.Admins.GetById(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrAdminNotFound {
+			newResponse(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !admin.SuperAdmin {
+		newResponse(c, http.StatusForbidden, service.ErrNotSuperAdmin.Error())
+	}
+}
+
+// streamIdentity also accepts access token from query, browsers can't set headers of WebSocket and EventSource requests
+func (h *Handler) streamIdentity(c *gin.Context) {
+	if token := c.Query(accessTokenParam); token != "" && c.GetHeader(authorizationHeader) == "" {
+		claims, err := h.tokenManager.Parse(token)
+		if err != nil {
+			newResponse(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		setIdentity(c, userCtx, claims)
+		return
+	}
+
+	h.userIdentity(c)
+}
+
+// parseAuthHeader returns scheme and credentials of Authorization header, scheme is Bearer or ApiKey
+func parseAuthHeader(c *gin.Context) (string, string, error) {
+	header := c.GetHeader(authorizationHeader)
+	if header == "" {
+		return "", "", errors.New("empty auth header")
+	}
+
+	headerParts := strings.Split(header, " ")
+	if len(headerParts) != 2 || (headerParts[0] != bearerScheme && headerParts[0] != apiKeyScheme) {
+		return "", "", errors.New("invalid auth header")
+	}
+
+	if len(headerParts[1]) == 0 {
+		return "", "", errors.New("token is empty")
+	}
+
+	return headerParts[0], headerParts[1], nil
+}
+
+func getUserId(c *gin.Context) (primitive.ObjectID, error) {
+	return getIdByContext(c, userCtx)
+}
+
+func getAdminId(c *gin.Context) (primitive.ObjectID, error) {
+	return getIdByContext(c, adminCtx)
+}
+
+func getGrants(c *gin.Context) []domain.Grant {
+	grants, _ := c.Get(grantsCtx)
+	g, _ := grants.([]domain.Grant)
+
+	return g
+}
+
+// getSessionId returns id of the session access token was issued for, it's zero for tokens without one
+func getSessionId(c *gin.Context) primitive.ObjectID {
+	id, _ := getIdByContext(c, sessionCtx)
+
+	return id
+}
+
+// scopesAllow reports whether API key request was authenticated with allows the permission.
+// Requests with access token aren't limited by scopes
+func scopesAllow(c *gin.Context, permission string) bool {
+	key, ok := c.Get(apiKeyCtx)
+	if !ok {
+		return true
+	}
+
+	apiKey, ok := key.(domain.APIKey)
+
+	return ok && domain.ScopesAllow(apiKey.Scopes, permission)
+}
+
+// checkScopes is scopesAllow setting identity of API key owner when the permission is allowed
+func checkScopes(c *gin.Context, permission string) bool {
+	if !scopesAllow(c, permission) {
+		return false
+	}
+
+	if value, ok := c.Get(apiKeyOwnerCtx); ok {
+		owner := value.(apiKeyOwner)
+		c.Set(owner.context, owner.id)
+		c.Set(grantsCtx, owner.grants)
+	}
+
+	return true
+}
+
+func getIdByContext(c *gin.Context, context string) (primitive.ObjectID, error) {
+	idFromCtx, ok := c.Get(context)
+	if !ok {
+		return primitive.ObjectID{}, errors.New(context + " not found")
+	}
+
+	idStr, ok := idFromCtx.(string)
+	if !ok {
+		return primitive.ObjectID{}, errors.New(context + " is of invalid type")
+	}
+
+	return primitive.ObjectIDFromHex(idStr)
+}
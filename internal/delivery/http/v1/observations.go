@@ -0,0 +1,199 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/units"
+)
+
+func (h *Handler) initObservationsRoutes(api *gin.RouterGroup) {
+	observations := api.Group("/observations", h.userIdentity, h.authorizeStation(domain.PermissionDataRead, queryStation))
+	{
+		observations.GET("", h.getObservations)
+		observations.GET("/aggregate", h.aggregateObservations)
+		observations.GET("/export", h.exportObservations)
+	}
+}
+
+type observationsQuery struct {
+	Station  string    `form:"station"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Fields   string    `form:"fields"`
+	Computed string    `form:"computed"`
+	QC       string    `form:"qc"`
+	Cursor   string    `form:"cursor"`
+	Order    string    `form:"order" binding:"omitempty,oneof=asc desc"`
+	Limit    int64     `form:"limit" binding:"omitempty,min=1,max=1000"`
+	Units    string    `form:"units"`
+}
+
+type observationsResponse struct {
+	Data       []domain.Observation `json:"data"`
+	Units      units.System         `json:"units"`
+	NextCursor string               `json:"nextCursor,omitempty"`
+}
+
+// @Summary Get Observations
+// @Security UsersAuth
+// @Tags observations
+// @Description get station observations filtered by time range with cursor pagination
+// @ModuleID getObservations
+// @Accept  json
+// @Produce  json
+// @Param station query string false "comma-separated station identifiers"
+// @Param from query string false "RFC3339 start of time range (inclusive)"
+// @Param to query string false "RFC3339 end of time range (exclusive)"
+// @Param fields query string false "comma-separated observation fields"
+// @Param computed query string false "comma-separated derived quantities: dewPoint, heatIndex, windChill, humidex, apparentTemperature, seaLevelPressure, absoluteHumidity, mixingRatio"
+// @Param qc query string false "comma-separated quality flags of returned values: good, suspect, bad, unchecked"
+// @Param cursor query string false "cursor returned with the previous page"
+// @Param order query string false "asc or desc, asc by default"
+// @Param limit query int false "page size, 100 by default"
+// @Param units query string false "metric or imperial, preferred units by default"
+// @Success 200 {object} observationsResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /observations [get]
+func (h *Handler) getObservations(c *gin.Context) {
+	var query observationsQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	presentation, ok := h.getPresentation(c, query.Units)
+	if !ok {
+		return
+	}
+
+	page, err := h.services.Observations.Find(c.Request.Context(), service.ObservationsQueryInput{
+		StationIDs: splitQueryList(query.Station),
+		From:       query.From,
+		To:         query.To,
+		Fields:     splitQueryList(query.Fields),
+		Computed:   splitQueryList(query.Computed),
+		Quality:    splitQueryList(query.QC),
+		Cursor:     query.Cursor,
+		Descending: query.Order == "desc",
+		Limit:      query.Limit,
+	})
+	if err != nil {
+		if isObservationsQueryError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	presentation.Observations(page.Observations)
+
+	c.JSON(http.StatusOK, observationsResponse{
+		Data:       page.Observations,
+		Units:      presentation.Units,
+		NextCursor: page.NextCursor,
+	})
+}
+
+type aggregateObservationsQuery struct {
+	Station  string    `form:"station"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00" binding:"required"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" binding:"required"`
+	Fields   string    `form:"fields"`
+	Interval string    `form:"interval" binding:"required,oneof=1m 10m 1h 1d 1mo"`
+	Units    string    `form:"units"`
+}
+
+type aggregateObservationsResponse struct {
+	Data  []domain.ObservationAggregate `json:"data"`
+	Units units.System                  `json:"units"`
+	Count int64                         `json:"count"`
+}
+
+// @Summary Aggregate Observations
+// @Security UsersAuth
+// @Tags observations
+// @Description get min/max/mean (and sum for precipitation) of observation fields per time interval.
+// @Description Values flagged bad by quality control are left out
+// @ModuleID aggregateObservations
+// @Accept  json
+// @Produce  json
+// @Param station query string false "comma-separated station identifiers"
+// @Param from query string true "RFC3339 start of time range (inclusive)"
+// @Param to query string true "RFC3339 end of time range (exclusive)"
+// @Param fields query string false "comma-separated observation fields, all by default"
+// @Param interval query string true "1m, 10m, 1h, 1d or 1mo"
+// @Param units query string false "metric or imperial, preferred units by default"
+// @Success 200 {object} aggregateObservationsResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /observations/aggregate [get]
+func (h *Handler) aggregateObservations(c *gin.Context) {
+	var query aggregateObservationsQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	presentation, ok := h.getPresentation(c, query.Units)
+	if !ok {
+		return
+	}
+
+	aggregates, err := h.services.Observations.Aggregate(c.Request.Context(), service.AggregateObservationsInput{
+		StationIDs: splitQueryList(query.Station),
+		From:       query.From,
+		To:         query.To,
+		Fields:     splitQueryList(query.Fields),
+		Interval:   query.Interval,
+	})
+	if err != nil {
+		if isObservationsQueryError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	presentation.Aggregates(aggregates)
+
+	c.JSON(http.StatusOK, aggregateObservationsResponse{Data: aggregates, Units: presentation.Units, Count: int64(len(aggregates))})
+}
+
+func splitQueryList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	items := strings.Split(value, ",")
+	res := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+
+	return res
+}
+
+func isObservationsQueryError(err error) bool {
+	return errors.Is(err, service.ErrUnknownObservationField) ||
+		errors.Is(err, service.ErrUnknownComputedField) ||
+		errors.Is(err, service.ErrUnknownQualityFlag) ||
+		err == service.ErrInvalidTimeRange ||
+		err == service.ErrInvalidCursor ||
+		err == service.ErrInvalidInterval ||
+		err == service.ErrTooManyBuckets
+}
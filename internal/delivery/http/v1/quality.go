@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type overrideQualityInput struct {
+	Field string `json:"field" binding:"required"`
+	Flag  string `json:"flag" binding:"required"`
+}
+
+// @Summary Admin Override Quality Flag
+// @Security AdminAuth
+// @Tags admins-observations
+// @Description admin manually set quality flag of observation value
+// @ModuleID adminOverrideQualityFlag
+// @Accept  json
+// @Produce  json
+// @Param id path string true "observation id"
+// @Param input body overrideQualityInput true "field and flag: good, suspect or bad"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/observations/{id}/qc [put]
+func (h *Handler) adminOverrideQualityFlag(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var inp overrideQualityInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.QualityControl.Override(c.Request.Context(), service.OverrideQualityInput{
+		ObservationID: id,
+		Field:         inp.Field,
+		Flag:          inp.Flag,
+	}); err != nil {
+		if err == service.ErrObservationNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if err == service.ErrUnknownObservationField || err == service.ErrUnknownQualityFlag {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response{"success"})
+}
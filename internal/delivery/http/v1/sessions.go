@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (h *Handler) initUserSessionsRoutes(authenticated *gin.RouterGroup) {
+	sessions := authenticated.Group("/sessions", h.sessionIdentity)
+	{
+		sessions.GET("", h.userGetSessions)
+		sessions.DELETE("", h.userDeleteSessions)
+		sessions.DELETE("/:id", h.userDeleteSession)
+	}
+}
+
+// @Summary User Get Sessions
+// @Security UsersAuth
+// @Tags users-sessions
+// @Description get devices the user is signed in on, session of the request is marked as current
+// @ModuleID userGetSessions
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} dataResponse
+// @Failure 400,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/sessions [get]
+func (h *Handler) userGetSessions(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sessions, err := h.services.Users.GetSessions(c.Request.Context(), userId, getSessionId(c))
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse{Data: sessions, Count: int64(len(sessions))})
+}
+
+// @Summary User Delete Session
+// @Security UsersAuth
+// @Tags users-sessions
+// @Description sign out of the session, its refresh token stops working. Issued access tokens expire by themselves
+// @ModuleID userDeleteSession
+// @Accept  json
+// @Produce  json
+// @Param id path string true "session id"
+// @Success 200 {object} response
+// @Failure 400,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/sessions/{id} [delete]
+func (h *Handler) userDeleteSession(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.services.Users.DeleteSession(c.Request.Context(), userId, id); err != nil {
+		if err == service.ErrSessionNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response{"success"})
+}
+
+// @Summary User Sign Out Everywhere
+// @Security UsersAuth
+// @Tags users-sessions
+// @Description sign out of all sessions including the current one
+// @ModuleID userDeleteSessions
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} response
+// @Failure 400,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/sessions [delete]
+func (h *Handler) userDeleteSessions(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := h.services.Users.DeleteSessions(c.Request.Context(), userId); err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response{"success"})
+}
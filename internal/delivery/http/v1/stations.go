@@ -0,0 +1,342 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type stationSensor struct {
+	Type        string    `json:"type" binding:"required"`
+	Model       string    `json:"model"`
+	Serial      string    `json:"serial"`
+	InstalledAt time.Time `json:"installedAt"`
+}
+
+type createStationInput struct {
+	WMO       string          `json:"wmo" binding:"omitempty,len=5,numeric"`
+	ICAO      string          `json:"icao" binding:"omitempty,len=4,alphanum"`
+	Name      string          `json:"name" binding:"required,min=2,max=128"`
+	Latitude  *float64        `json:"latitude" binding:"required,min=-90,max=90"`
+	Longitude *float64        `json:"longitude" binding:"required,min=-180,max=180"`
+	Elevation float64         `json:"elevation"`
+	Type      string          `json:"type"`
+	Sensors   []stationSensor `json:"sensors" binding:"dive"`
+	Status    string          `json:"status"`
+
+	ReportInterval int64 `json:"reportInterval" binding:"min=0"`
+}
+
+// @Summary Admin Create Station
+// @Security AdminAuth
+// @Tags admins-stations
+// @Description admin create station
+// @ModuleID adminCreateStation
+// @Accept  json
+// @Produce  json
+// @Param input body createStationInput true "station info"
+// @Success 201 {object} idResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/stations [post]
+func (h *Handler) adminCreateStation(c *gin.Context) {
+	var inp createStationInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	id, err := h.services.Stations.Create(c.Request.Context(), service.CreateStationInput{
+		WMO:       inp.WMO,
+		ICAO:      inp.ICAO,
+		Name:      inp.Name,
+		Latitude:  *inp.Latitude,
+		Longitude: *inp.Longitude,
+		Elevation: inp.Elevation,
+		Type:      inp.Type,
+		Sensors:   toDomainSensors(inp.Sensors),
+		Status:    inp.Status,
+
+		ReportInterval: inp.ReportInterval,
+	})
+	if err != nil {
+		if isStationInputError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, idResponse{id})
+}
+
+// @Summary Admin Get All Stations
+// @Security AdminAuth
+// @Tags admins-stations
+// @Description admin get all stations
+// @ModuleID adminGetAllStations
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} dataResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/stations [get]
+func (h *Handler) adminGetAllStations(c *gin.Context) {
+	stations, err := h.services.Stations.GetAll(c.Request.Context())
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse{Data: stations, Count: int64(len(stations))})
+}
+
+// @Summary Admin Get Station By ID
+// @Security AdminAuth
+// @Tags admins-stations
+// @Description admin get station by id
+// @ModuleID adminGetStationById
+// @Accept  json
+// @Produce  json
+// @Param id path string true "station id"
+// @Success 200 {object} domain.Station
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/stations/{id} [get]
+func (h *Handler) adminGetStationById(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	station, err := h.services.Stations.GetById(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrStationNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, station)
+}
+
+type updateStationInput struct {
+	WMO       *string         `json:"wmo" binding:"omitempty,len=5,numeric"`
+	ICAO      *string         `json:"icao" binding:"omitempty,len=4,alphanum"`
+	Name      *string         `json:"name" binding:"omitempty,min=2,max=128"`
+	Latitude  *float64        `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude *float64        `json:"longitude" binding:"omitempty,min=-180,max=180"`
+	Elevation *float64        `json:"elevation"`
+	Type      *string         `json:"type"`
+	Sensors   []stationSensor `json:"sensors" binding:"omitempty,dive"`
+	Status    *string         `json:"status"`
+
+	ReportInterval *int64 `json:"reportInterval" binding:"omitempty,min=0"`
+}
+
+// @Summary Admin Update Station
+// @Security AdminAuth
+// @Tags admins-stations
+// @Description admin update station. Changing WMO or ICAO identifier requires stations:manage permission,
+// @Description grants for the station only don't allow moving it to other identifier
+// @ModuleID adminUpdateStation
+// @Accept  json
+// @Produce  json
+// @Param id path string true "station id"
+// @Param input body updateStationInput true "station update info"
+// @Success 200 {object} response
+// @Failure 400,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/stations/{id} [put]
+func (h *Handler) adminUpdateStation(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var inp updateStationInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if !h.canChangeIdentifiers(c, id, inp) {
+		return
+	}
+
+	if err := h.services.Stations.Update(c.Request.Context(), service.UpdateStationInput{
+		ID:        id,
+		WMO:       inp.WMO,
+		ICAO:      inp.ICAO,
+		Name:      inp.Name,
+		Latitude:  inp.Latitude,
+		Longitude: inp.Longitude,
+		Elevation: inp.Elevation,
+		Type:      inp.Type,
+		Sensors:   toDomainSensors(inp.Sensors),
+		Status:    inp.Status,
+
+		ReportInterval: inp.ReportInterval,
+	}); err != nil {
+		if err == service.ErrStationNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if isStationInputError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response{"success"})
+}
+
+// canChangeIdentifiers responds with error itself. Station grants are given by identifier,
+// so only global managers can change them, otherwise station owner could take over any identifier
+func (h *Handler) canChangeIdentifiers(c *gin.Context, id primitive.ObjectID, inp updateStationInput) bool {
+	if inp.WMO == nil && inp.ICAO == nil {
+		return true
+	}
+
+	if scopesAllow(c, domain.PermissionStationsManage) && domain.Allows(getGrants(c), domain.PermissionStationsManage) {
+		return true
+	}
+
+	station, err := h.services.Stations.GetById(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrStationNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return false
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return false
+	}
+
+	if (inp.WMO != nil && *inp.WMO != station.WMO) || (inp.ICAO != nil && *inp.ICAO != station.ICAO) {
+		newResponse(c, http.StatusForbidden, "not enough permissions")
+		return false
+	}
+
+	return true
+}
+
+// @Summary Admin Delete Station
+// @Security AdminAuth
+// @Tags admins-stations
+// @Description admin delete station
+// @ModuleID adminDeleteStation
+// @Accept  json
+// @Produce  json
+// @Param id path string true "station id"
+// @Success 200 {object} response
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/stations/{id} [delete]
+func (h *Handler) adminDeleteStation(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.services.Stations.Delete(c.Request.Context(), id); err != nil {
+		if err == service.ErrStationNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response{"success"})
+}
+
+func toDomainSensors(sensors []stationSensor) []domain.StationSensor {
+	if sensors == nil {
+		return nil
+	}
+
+	res := make([]domain.StationSensor, len(sensors))
+	for i, s := range sensors {
+		res[i] = domain.StationSensor{
+			Type:        s.Type,
+			Model:       s.Model,
+			Serial:      s.Serial,
+			InstalledAt: s.InstalledAt,
+		}
+	}
+
+	return res
+}
+
+type stationsUptimeQuery struct {
+	From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// @Summary Admin Get Stations Uptime
+// @Security AdminAuth
+// @Tags admins-stations
+// @Description admin get percentage of time window stations were online, late, offline or in unknown state
+// @ModuleID adminGetStationsUptime
+// @Accept  json
+// @Produce  json
+// @Param from query string false "RFC3339 start of window, 7 days before its end by default"
+// @Param to query string false "RFC3339 end of window, now by default"
+// @Success 200 {object} dataResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/stations/uptime [get]
+func (h *Handler) adminGetStationsUptime(c *gin.Context) {
+	var query stationsUptimeQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	uptime, err := h.services.StationHealth.Uptime(c.Request.Context(), service.StationUptimeInput{
+		From: query.From,
+		To:   query.To,
+	})
+	if err != nil {
+		if err == service.ErrInvalidTimeRange {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, dataResponse{Data: uptime, Count: int64(len(uptime))})
+}
+
+func isStationInputError(err error) bool {
+	return err == service.ErrStationAlreadyExists ||
+		err == service.ErrStationIdentifierEmpty ||
+		err == service.ErrInvalidStationStatus ||
+		err == service.ErrInvalidStationType
+}
@@ -0,0 +1,232 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+)
+
+const (
+	formatGeoJSON      = "geojson"
+	geoJSONContentType = "application/geo+json"
+)
+
+func (h *Handler) initStationsRoutes(api *gin.RouterGroup) {
+	stations := api.Group("/stations", h.userIdentity, h.authorize(domain.PermissionDataRead))
+	{
+		stations.GET("/nearest", h.getNearestStations)
+		stations.GET("/radius", h.getStationsWithinRadius)
+		stations.GET("/bbox", h.getStationsWithinBox)
+	}
+}
+
+// geoJSONFeatureCollection lets map clients consume search results directly
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Geometry   domain.GeoPoint        `json:"geometry"`
+	Properties domain.StationDistance `json:"properties"`
+}
+
+type stationsFilterQuery struct {
+	Type   string `form:"type"`
+	Status string `form:"status"`
+	Format string `form:"format" binding:"omitempty,oneof=json geojson"`
+	Limit  int64  `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+type nearestStationsQuery struct {
+	Latitude  *float64 `form:"lat" binding:"required,min=-90,max=90"`
+	Longitude *float64 `form:"lon" binding:"required,min=-180,max=180"`
+
+	stationsFilterQuery
+}
+
+// @Summary Get Nearest Stations
+// @Security UsersAuth
+// @Tags stations
+// @Description get stations nearest to the point ordered by distance in km
+// @ModuleID getNearestStations
+// @Accept  json
+// @Produce  json
+// @Param lat query number true "latitude"
+// @Param lon query number true "longitude"
+// @Param type query string false "comma-separated station types: synoptic, aviation, automatic, climate"
+// @Param status query string false "comma-separated station statuses: active, maintenance, decommissioned"
+// @Param format query string false "json or geojson, json by default"
+// @Param limit query int false "number of stations, 10 by default"
+// @Success 200 {object} dataResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /stations/nearest [get]
+func (h *Handler) getNearestStations(c *gin.Context) {
+	var query nearestStationsQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	h.getStationsNear(c, service.StationsNearInput{
+		Latitude:  *query.Latitude,
+		Longitude: *query.Longitude,
+		Types:     splitQueryList(query.Type),
+		Statuses:  splitQueryList(query.Status),
+		Limit:     query.Limit,
+	}, query.Format)
+}
+
+type stationsWithinRadiusQuery struct {
+	Latitude  *float64 `form:"lat" binding:"required,min=-90,max=90"`
+	Longitude *float64 `form:"lon" binding:"required,min=-180,max=180"`
+	Radius    float64  `form:"radius" binding:"required,gt=0,max=20038"`
+
+	stationsFilterQuery
+}
+
+// @Summary Get Stations Within Radius
+// @Security UsersAuth
+// @Tags stations
+// @Description get stations within radius of the point ordered by distance in km
+// @ModuleID getStationsWithinRadius
+// @Accept  json
+// @Produce  json
+// @Param lat query number true "latitude"
+// @Param lon query number true "longitude"
+// @Param radius query number true "radius in km"
+// @Param type query string false "comma-separated station types: synoptic, aviation, automatic, climate"
+// @Param status query string false "comma-separated station statuses: active, maintenance, decommissioned"
+// @Param format query string false "json or geojson, json by default"
+// @Param limit query int false "max number of stations, 100 by default"
+// @Success 200 {object} dataResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /stations/radius [get]
+func (h *Handler) getStationsWithinRadius(c *gin.Context) {
+	var query stationsWithinRadiusQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	h.getStationsNear(c, service.StationsNearInput{
+		Latitude:  *query.Latitude,
+		Longitude: *query.Longitude,
+		Radius:    query.Radius,
+		Types:     splitQueryList(query.Type),
+		Statuses:  splitQueryList(query.Status),
+		Limit:     query.Limit,
+	}, query.Format)
+}
+
+func (h *Handler) getStationsNear(c *gin.Context, inp service.StationsNearInput, format string) {
+	stations, err := h.services.Stations.GetNear(c.Request.Context(), inp)
+	if err != nil {
+		if isStationsSearchError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeStations(c, stations, format)
+}
+
+type stationsWithinBoxQuery struct {
+	MinLatitude  *float64 `form:"minLat" binding:"required,min=-90,max=90"`
+	MinLongitude *float64 `form:"minLon" binding:"required,min=-180,max=180"`
+	MaxLatitude  *float64 `form:"maxLat" binding:"required,min=-90,max=90"`
+	MaxLongitude *float64 `form:"maxLon" binding:"required,min=-180,max=180"`
+
+	stationsFilterQuery
+}
+
+// @Summary Get Stations Within Bounding Box
+// @Security UsersAuth
+// @Tags stations
+// @Description get stations within bounding box sorted by name. minLon greater than maxLon means the box crosses the antimeridian
+// @ModuleID getStationsWithinBox
+// @Accept  json
+// @Produce  json
+// @Param minLat query number true "south latitude"
+// @Param minLon query number true "west longitude"
+// @Param maxLat query number true "north latitude"
+// @Param maxLon query number true "east longitude"
+// @Param type query string false "comma-separated station types: synoptic, aviation, automatic, climate"
+// @Param status query string false "comma-separated station statuses: active, maintenance, decommissioned"
+// @Param format query string false "json or geojson, json by default"
+// @Param limit query int false "max number of stations, 100 by default"
+// @Success 200 {object} dataResponse
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /stations/bbox [get]
+func (h *Handler) getStationsWithinBox(c *gin.Context) {
+	var query stationsWithinBoxQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	stations, err := h.services.Stations.GetWithinBox(c.Request.Context(), service.StationsBoxInput{
+		MinLatitude:  *query.MinLatitude,
+		MinLongitude: *query.MinLongitude,
+		MaxLatitude:  *query.MaxLatitude,
+		MaxLongitude: *query.MaxLongitude,
+		Types:        splitQueryList(query.Type),
+		Statuses:     splitQueryList(query.Status),
+		Limit:        query.Limit,
+	})
+	if err != nil {
+		if isStationsSearchError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	found := make([]domain.StationDistance, len(stations))
+	for i := range stations {
+		found[i] = domain.StationDistance{Station: stations[i]}
+	}
+
+	writeStations(c, found, query.Format)
+}
+
+func writeStations(c *gin.Context, stations []domain.StationDistance, format string) {
+	if format != formatGeoJSON {
+		c.JSON(http.StatusOK, dataResponse{Data: stations, Count: int64(len(stations))})
+		return
+	}
+
+	collection := geoJSONFeatureCollection{Type: "FeatureCollection", Features: make([]geoJSONFeature, len(stations))}
+	for i, station := range stations {
+		collection.Features[i] = geoJSONFeature{
+			Type:       "Feature",
+			ID:         station.ID.Hex(),
+			Geometry:   domain.NewGeoPoint(station.Latitude, station.Longitude),
+			Properties: station,
+		}
+	}
+
+	c.Header("Content-Type", geoJSONContentType)
+	c.JSON(http.StatusOK, collection)
+}
+
+func isStationsSearchError(err error) bool {
+	return err == service.ErrInvalidStationType ||
+		err == service.ErrInvalidStationStatus ||
+		err == service.ErrInvalidBoundingBox
+}
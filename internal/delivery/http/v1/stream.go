@@ -0,0 +1,276 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/service"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/logger"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	streamPingInterval = 30 * time.Second
+	streamWriteTimeout = 10 * time.Second
+
+	streamMessageObservation = "observation"
+	streamMessageDropped     = "dropped"
+	streamMessageError       = "error"
+	streamMessagePing        = "ping"
+)
+
+func (h *Handler) initStreamRoutes(api *gin.RouterGroup) {
+	stream := api.Group("/stream", h.streamIdentity, h.authorize(domain.PermissionDataRead))
+	{
+		stream.GET("/observations", h.streamObservations)
+	}
+}
+
+type streamObservationsQuery struct {
+	Station string `form:"station"`
+	Fields  string `form:"fields"`
+	Units   string `form:"units"`
+}
+
+// streamMessage is a WebSocket message, SSE events carry the same data with type as event name
+type streamMessage struct {
+	Type        string              `json:"type"`
+	Observation *domain.Observation `json:"observation,omitempty"`
+	Dropped     uint64              `json:"dropped,omitempty"`
+	Message     string              `json:"message,omitempty"`
+}
+
+// streamSubscribeMessage is sent by WebSocket clients to change subscription
+type streamSubscribeMessage struct {
+	Stations []string `json:"stations"`
+	Fields   []string `json:"fields"`
+}
+
+// @Summary Stream Observations
+// @Security UsersAuth
+// @Tags observations
+// @Description stream new observations over WebSocket (requests with Upgrade header) or Server-Sent Events.
+// @Description Access token may be passed in access_token query param. WebSocket clients can change subscription
+// @Description by sending {"stations": [...], "fields": [...]}. Observations not delivered in time to slow clients
+// @Description are dropped and reported with "dropped" message
+// @ModuleID streamObservations
+// @Produce  text/event-stream
+// @Param station query string false "comma-separated station identifiers, all stations by default"
+// @Param fields query string false "comma-separated observation fields, all by default"
+// @Param units query string false "metric or imperial, preferred units by default"
+// @Param access_token query string false "access token for clients that can't set Authorization header"
+// @Success 200 {object} streamMessage
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /stream/observations [get]
+func (h *Handler) streamObservations(c *gin.Context) {
+	var query streamObservationsQuery
+	if err := c.BindQuery(&query); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+
+	presentation, ok := h.getPresentation(c, query.Units)
+	if !ok {
+		return
+	}
+
+	sub, err := h.services.Stream.Subscribe(service.StreamSubscriptionInput{
+		StationIDs: splitQueryList(query.Station),
+		Fields:     splitQueryList(query.Fields),
+	})
+	if err != nil {
+		if isObservationsQueryError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer sub.Close()
+
+	if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		websocket.Server{Handler: func(ws *websocket.Conn) {
+			streamWebSocket(ws, sub, presentation)
+		}}.ServeHTTP(c.Writer, c.Request)
+
+		return
+	}
+
+	streamSSE(c, sub, presentation)
+}
+
+func streamWebSocket(ws *websocket.Conn, sub *service.ObservationsSubscription, presentation service.Presentation) {
+	defer ws.Close()
+
+	// connection is idle while client doesn't change subscription, server read timeout doesn't apply
+	if err := ws.SetReadDeadline(time.Time{}); err != nil {
+		return
+	}
+
+	send := func(msg streamMessage) error {
+		if err := ws.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+			return err
+		}
+
+		return websocket.JSON.Send(ws, msg)
+	}
+
+	// subscription updates are read concurrently, writes are made from the loop below only
+	done := make(chan struct{})
+	updateErrors := make(chan error, 1)
+	go func() {
+		defer close(done)
+
+		for {
+			var msg streamSubscribeMessage
+			err := websocket.JSON.Receive(ws, &msg)
+			if err == nil {
+				err = sub.Update(service.StreamSubscriptionInput{StationIDs: msg.Stations, Fields: msg.Fields})
+			} else if !isJSONError(err) {
+				return
+			}
+
+			if err != nil {
+				select {
+				case updateErrors <- err:
+				default:
+				}
+			}
+		}
+	}()
+
+	streamLoop(sub, presentation, done, updateErrors, send)
+}
+
+// streamSSE writes events to hijacked connection: server write timeout would otherwise close long-lived response
+func streamSSE(c *gin.Context, sub *service.ObservationsSubscription, presentation service.Presentation) {
+	conn, rw, err := c.Writer.Hijack()
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer conn.Close()
+
+	write := func(data string) error {
+		if err := conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+			return err
+		}
+
+		if _, err := rw.WriteString(data); err != nil {
+			return err
+		}
+
+		return rw.Flush()
+	}
+
+	// headers set by middlewares, e.g. CORS, are written along with the stream ones
+	header := c.Writer.Header().Clone()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "close")
+
+	var headers strings.Builder
+	headers.WriteString("HTTP/1.1 200 OK\r\n")
+	if err := header.Write(&headers); err != nil {
+		return
+	}
+	headers.WriteString("\r\n")
+
+	if err := write(headers.String()); err != nil {
+		return
+	}
+
+	// SSE clients don't send anything, reading only detects closed connection
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(ioutil.Discard, rw)
+	}()
+
+	send := func(msg streamMessage) error {
+		if msg.Type == streamMessagePing {
+			return write(": ping\n\n")
+		}
+
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+
+		return write(fmt.Sprintf("event: %s\ndata: %s\n\n", msg.Type, data))
+	}
+
+	streamLoop(sub, presentation, done, nil, send)
+}
+
+// streamLoop sends subscribed observations until client disconnects or sending fails
+func streamLoop(sub *service.ObservationsSubscription, presentation service.Presentation, done <-chan struct{},
+	updateErrors <-chan error, send func(msg streamMessage) error) {
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+
+	for {
+		var msg streamMessage
+
+		select {
+		case <-done:
+			return
+		case err := <-updateErrors:
+			msg = streamMessage{Type: streamMessageError, Message: err.Error()}
+		case <-ticker.C:
+			msg = streamMessage{Type: streamMessagePing}
+		case observation, ok := <-sub.C():
+			if !ok {
+				return
+			}
+
+			presentation.Observation(&observation)
+			msg = streamMessage{Type: streamMessageObservation, Observation: &observation}
+		}
+
+		if dropped := sub.Dropped(); dropped > 0 {
+			if err := send(streamMessage{Type: streamMessageDropped, Dropped: dropped}); err != nil {
+				logStreamError(err)
+				return
+			}
+		}
+
+		if err := send(msg); err != nil {
+			logStreamError(err)
+			return
+		}
+	}
+}
+
+func isJSONError(err error) bool {
+	switch err.(type) {
+	case *json.SyntaxError, *json.UnmarshalTypeError:
+		return true
+	default:
+		return false
+	}
+}
+
+func logStreamError(err error) {
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		logger.Errorf("observations stream client is too slow: %s", err.Error())
+		return
+	}
+
+	logger.Errorf("failed to write to observations stream: %s", err.Error())
+}
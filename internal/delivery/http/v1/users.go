@@ -10,7 +10,7 @@ import (
 )
 
 func (h *Handler) initUsersRoutes(api *gin.RouterGroup) {
-	students := api.Group("/users", h.setSchoolFromRequest)
+	students := api.Group("/users")
 	{
 		students.POST("/sign-up", h.userSignUp)
 		students.POST("/sign-in", h.userSignIn)
@@ -19,7 +19,16 @@ func (h *Handler) initUsersRoutes(api *gin.RouterGroup) {
 
 		authenticated := students.Group("/", h.userIdentity)
 		{
-			authenticated.GET("/account", h.studentGetAccount)
+			account := authenticated.Group("/account", h.sessionIdentity)
+			{
+				account.GET("", h.userGetAccount)
+				account.GET("/preferences", h.userGetPreferences)
+				account.PUT("/preferences", h.userUpdatePreferences)
+			}
+
+			h.initAlertsRoutes(authenticated)
+			h.initUserAPIKeysRoutes(authenticated)
+			h.initUserSessionsRoutes(authenticated)
 		}
 	}
 }
@@ -96,8 +105,10 @@ func (h *Handler) userSignIn(c *gin.Context) {
 	}
 
 	res, err := h.services.Users.SignIn(c.Request.Context(), service.SignInInput{
-		Email:    inp.Email,
-		Password: inp.Password,
+		Email:     inp.Email,
+		Password:  inp.Password,
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
 	})
 	if err != nil {
 		if err == service.ErrUserNotFound {
@@ -121,12 +132,12 @@ type refreshInput struct {
 
 // @Summary Student Refresh Tokens
 // @Tags students-auth
-// @Description student refresh tokens
+// @Description student refresh tokens. Refresh token is rotated, reusing the previous one revokes the session
 // @Accept  json
 // @Produce  json
 // @Param input body refreshInput true "sign up info"
 // @Success 200 {object} tokenResponse
-// @Failure 400,404 {object} response
+// @Failure 400,401,404 {object} response
 // @Failure 500 {object} response
 // @Failure default {object} response
 // @Router /students/auth/refresh [post]
@@ -137,8 +148,17 @@ func (h *Handler) userRefresh(c *gin.Context) {
 		return
 	}
 
-	res, err := h.services.Users.RefreshTokens(c.Request.Context(), inp.Token)
+	res, err := h.services.Users.RefreshTokens(c.Request.Context(), service.RefreshTokensInput{
+		Token:     inp.Token,
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	})
 	if err != nil {
+		if err == service.ErrInvalidRefreshToken || err == service.ErrRefreshTokenReused {
+			newResponse(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+
 		newResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -168,7 +188,7 @@ func (h *Handler) userVerify(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.Students.Verify(c.Request.Context(), code); err != nil {
+	if err := h.services.Users.Verify(c.Request.Context(), code); err != nil {
 		if err == service.ErrVerificationCodeInvalid {
 			newResponse(c, http.StatusBadRequest, err.Error())
 			return
@@ -181,6 +201,13 @@ func (h *Handler) userVerify(c *gin.Context) {
 	c.JSON(http.StatusOK, response{"success"})
 }
 
+type userAccountResponse struct {
+	Name        string             `json:"name"`
+	Email       string             `json:"email"`
+	Preferences domain.Preferences `json:"preferences"`
+	Grants      []domain.Grant     `json:"grants"`
+}
+
 // @Summary Student Get Account Info
 // @Security StudentsAuth
 // @Tags students-account
@@ -207,7 +234,181 @@ func (h *Handler) userGetAccount(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, userAccountResponse{
-		Name:  user.Name,
-		Email: user.Email,
+		Name:        user.Name,
+		Email:       user.Email,
+		Preferences: user.Preferences,
+		Grants:      user.Grants,
+	})
+}
+
+// @Summary User Get Preferences
+// @Security UsersAuth
+// @Tags users-account
+// @Description get user units, timezone and locale preferences
+// @ModuleID userGetPreferences
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} domain.Preferences
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/account/preferences [get]
+func (h *Handler) userGetPreferences(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	user, err := h.services.Users.GetById(c.Request.Context(), userId)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, user.Preferences)
+}
+
+type updatePreferencesInput struct {
+	Units    *string `json:"units" binding:"omitempty,oneof=metric imperial"`
+	Timezone *string `json:"timezone"`
+	Locale   *string `json:"locale"`
+}
+
+// @Summary User Update Preferences
+// @Security UsersAuth
+// @Tags users-account
+// @Description update user preferences. Observations are returned in preferred units (metric or imperial)
+// @Description and IANA timezone unless units query param is passed
+// @ModuleID userUpdatePreferences
+// @Accept  json
+// @Produce  json
+// @Param input body updatePreferencesInput true "preferences"
+// @Success 200 {object} domain.Preferences
+// @Failure 400,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /users/account/preferences [put]
+func (h *Handler) userUpdatePreferences(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var inp updatePreferencesInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	preferences, err := h.services.Users.UpdatePreferences(c.Request.Context(), service.UpdatePreferencesInput{
+		UserID:   userId,
+		Units:    inp.Units,
+		Timezone: inp.Timezone,
+		Locale:   inp.Locale,
 	})
+	if err != nil {
+		if isPresentationError(err) || err == service.ErrInvalidLocale {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+type grantInput struct {
+	Role     string   `json:"role" binding:"required"`
+	Stations []string `json:"stations" binding:"omitempty,dive,required"`
+}
+
+type setGrantsInput struct {
+	Grants []grantInput `json:"grants" binding:"dive"`
+}
+
+// @Summary Admin Set User Grants
+// @Security AdminAuth
+// @Tags admins-users
+// @Description replace roles of the user. Roles without stations are granted for all stations.
+// @Description Users without roles are viewers, changes take effect once user refreshes access token
+// @ModuleID adminSetUserGrants
+// @Accept  json
+// @Produce  json
+// @Param id path string true "user id"
+// @Param input body setGrantsInput true "user grants"
+// @Success 200 {object} response
+// @Failure 400,403,404 {object} response
+// @Failure 500 {object} response
+// @Failure default {object} response
+// @Router /admins/users/{id}/grants [put]
+func (h *Handler) adminSetUserGrants(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var inp setGrantsInput
+	if err := c.BindJSON(&inp); err != nil {
+		newResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	grants := make([]domain.Grant, len(inp.Grants))
+	for i, g := range inp.Grants {
+		grants[i] = domain.Grant{Role: g.Role, Stations: g.Stations}
+	}
+
+	if err := h.services.Users.SetGrants(c.Request.Context(), id, grants); err != nil {
+		if err == service.ErrUserNotFound {
+			newResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		if err == service.ErrUnknownRole || err == service.ErrInvalidGrant {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, response{"success"})
+}
+
+// getPresentation responds with error itself, handlers just return if presentation wasn't resolved.
+// Admins and their API keys have no preferences, they get the default presentation in requested units
+func (h *Handler) getPresentation(c *gin.Context, unitSystem string) (service.Presentation, bool) {
+	var presentation service.Presentation
+
+	userId, err := getUserId(c)
+	if err != nil {
+		presentation, err = service.NewPresentation(domain.Preferences{}, unitSystem)
+	} else {
+		presentation, err = h.services.Users.GetPresentation(c.Request.Context(), userId, unitSystem)
+		if err == service.ErrUserNotFound {
+			presentation, err = service.NewPresentation(domain.Preferences{}, unitSystem)
+		}
+	}
+
+	if err != nil {
+		if isPresentationError(err) {
+			newResponse(c, http.StatusBadRequest, err.Error())
+			return service.Presentation{}, false
+		}
+
+		newResponse(c, http.StatusInternalServerError, err.Error())
+		return service.Presentation{}, false
+	}
+
+	return presentation, true
+}
+
+func isPresentationError(err error) bool {
+	return err == service.ErrUnknownUnitSystem || err == service.ErrInvalidTimezone
 }
@@ -2,10 +2,12 @@ package domain
 
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
+// Admin is an operator of the service. Super admins manage other admins, RegisteredAt is Unix time in seconds
 type Admin struct {
 	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	Name         string             `json:"name" bson:"name"`
 	Email        string             `json:"email" bson:"email"`
-	Password     string             `json:"password" bson:"password"`
+	Password     string             `json:"-" bson:"password"`
+	SuperAdmin   bool               `json:"superAdmin" bson:"superAdmin"`
 	RegisteredAt int64              `json:"registeredAt" bson:"registeredAt"`
 }
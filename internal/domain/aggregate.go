@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// ObservationAggregate holds statistics of station observations within [Start, Start + interval)
+type ObservationAggregate struct {
+	StationID string                `json:"stationId"`
+	Start     time.Time             `json:"start"`
+	Count     int64                 `json:"count"`
+	Fields    map[string]FieldStats `json:"fields"`
+}
+
+// FieldStats holds statistics of a single observation field. Sum is set for precipitation only,
+// Mean of wind direction is a circular mean
+type FieldStats struct {
+	Min  *float64 `json:"min"`
+	Max  *float64 `json:"max"`
+	Mean *float64 `json:"mean"`
+	Sum  *float64 `json:"sum,omitempty"`
+}
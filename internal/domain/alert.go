@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const (
+	AlertOperatorGreater      = ">"
+	AlertOperatorGreaterEqual = ">="
+	AlertOperatorLess         = "<"
+	AlertOperatorLessEqual    = "<="
+
+	AlertStateInactive = "inactive"
+	AlertStatePending  = "pending"
+	AlertStateFiring   = "firing"
+	AlertStateResolved = "resolved"
+)
+
+// AlertRule fires when Field of station observations satisfies "Field Operator Threshold"
+// for at least ForSeconds. Firing rule resolves once the value goes back past
+// the threshold by more than Hysteresis, so values jittering around the threshold
+// don't produce a stream of notifications
+type AlertRule struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"userId" bson:"userId"`
+	Name       string             `json:"name" bson:"name"`
+	StationID  string             `json:"stationId" bson:"stationId"`
+	Field      string             `json:"field" bson:"field"`
+	Operator   string             `json:"operator" bson:"operator"`
+	Threshold  float64            `json:"threshold" bson:"threshold"`
+	Hysteresis float64            `json:"hysteresis" bson:"hysteresis"`
+	ForSeconds int64              `json:"forSeconds" bson:"forSeconds"`
+	Enabled    bool               `json:"enabled" bson:"enabled"`
+	Status     AlertRuleStatus    `json:"status" bson:"status"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt  time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// AlertRuleStatus is evaluation state of the rule. PendingSince is the observation time
+// the condition started to hold, LastValue and LastTimestamp belong to the last evaluated observation
+type AlertRuleStatus struct {
+	State         string     `json:"state" bson:"state"`
+	PendingSince  *time.Time `json:"pendingSince,omitempty" bson:"pendingSince,omitempty"`
+	LastValue     *float64   `json:"lastValue,omitempty" bson:"lastValue,omitempty"`
+	LastTimestamp time.Time  `json:"lastTimestamp" bson:"lastTimestamp"`
+	ChangedAt     time.Time  `json:"changedAt" bson:"changedAt"`
+}
+
+// AlertEvent records transition of the rule to firing or resolved state
+type AlertEvent struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	RuleID    primitive.ObjectID `json:"ruleId" bson:"ruleId"`
+	UserID    primitive.ObjectID `json:"userId" bson:"userId"`
+	RuleName  string             `json:"ruleName" bson:"ruleName"`
+	StationID string             `json:"stationId" bson:"stationId"`
+	Field     string             `json:"field" bson:"field"`
+	State     string             `json:"state" bson:"state"`
+	Value     float64            `json:"value" bson:"value"`
+	Threshold float64            `json:"threshold" bson:"threshold"`
+	Timestamp time.Time          `json:"timestamp" bson:"timestamp"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+func (r AlertRule) For() time.Duration {
+	return time.Duration(r.ForSeconds) * time.Second
+}
+
+// Matches reports whether value satisfies the rule condition
+func (r AlertRule) Matches(value float64) bool {
+	switch r.Operator {
+	case AlertOperatorGreater:
+		return value > r.Threshold
+	case AlertOperatorGreaterEqual:
+		return value >= r.Threshold
+	case AlertOperatorLess:
+		return value < r.Threshold
+	case AlertOperatorLessEqual:
+		return value <= r.Threshold
+	default:
+		return false
+	}
+}
+
+// Clears reports whether value is far enough from the threshold to resolve firing rule
+func (r AlertRule) Clears(value float64) bool {
+	switch r.Operator {
+	case AlertOperatorGreater, AlertOperatorGreaterEqual:
+		return value < r.Threshold-r.Hysteresis
+	case AlertOperatorLess, AlertOperatorLessEqual:
+		return value > r.Threshold+r.Hysteresis
+	default:
+		return true
+	}
+}
+
+func IsAlertOperator(operator string) bool {
+	switch operator {
+	case AlertOperatorGreater, AlertOperatorGreaterEqual, AlertOperatorLess, AlertOperatorLessEqual:
+		return true
+	default:
+		return false
+	}
+}
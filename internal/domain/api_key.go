@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const (
+	APIKeyScopeRead   = "read"
+	APIKeyScopeIngest = "ingest"
+	APIKeyScopeAdmin  = "admin"
+
+	APIKeyOwnerUser  = "user"
+	APIKeyOwnerAdmin = "admin"
+)
+
+// APIKeyScopes lists scopes API keys can be limited to
+var APIKeyScopes = []string{APIKeyScopeRead, APIKeyScopeIngest, APIKeyScopeAdmin}
+
+var apiKeyScopePermissions = map[string][]string{
+	APIKeyScopeRead:   {PermissionDataRead, PermissionStationsRead},
+	APIKeyScopeIngest: {PermissionObservationsWrite},
+	APIKeyScopeAdmin:  rolePermissions[RoleAdmin],
+}
+
+// APIKey authenticates machine clients on behalf of its owner. Key can't do more than its owner is granted
+// and its scopes allow. Hash is SHA-256 of the key, the key itself is shown only once on creation and Prefix
+// tells keys apart. Empty AllowedIPs allows any address, entries are IPs or CIDR ranges
+type APIKey struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Owner      APIKeyOwner        `json:"owner" bson:"owner"`
+	Name       string             `json:"name" bson:"name"`
+	Prefix     string             `json:"prefix" bson:"prefix"`
+	Hash       string             `json:"-" bson:"hash"`
+	Scopes     []string           `json:"scopes" bson:"scopes"`
+	AllowedIPs []string           `json:"allowedIps" bson:"allowedIps"`
+	ExpiresAt  *time.Time         `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+	Usage      APIKeyUsage        `json:"usage" bson:"usage"`
+}
+
+// APIKeyOwner is a user or an admin depending on Type
+type APIKeyOwner struct {
+	ID   primitive.ObjectID `json:"id" bson:"id"`
+	Type string             `json:"type" bson:"type"`
+}
+
+// APIKeyUsage counts requests authenticated with the key
+type APIKeyUsage struct {
+	Requests   int64      `json:"requests" bson:"requests"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" bson:"lastUsedAt,omitempty"`
+	LastUsedIP string     `json:"lastUsedIp,omitempty" bson:"lastUsedIp,omitempty"`
+}
+
+func IsAPIKeyScope(scope string) bool {
+	_, ok := apiKeyScopePermissions[scope]
+	return ok
+}
+
+// ScopesAllow reports whether any of API key scopes includes the permission
+func ScopesAllow(scopes []string, permission string) bool {
+	for _, scope := range scopes {
+		for _, p := range apiKeyScopePermissions[scope] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+
+	return false
+}
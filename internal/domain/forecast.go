@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const (
+	ForecastChangeFrom        = "FM"
+	ForecastChangeBecoming    = "BECMG"
+	ForecastChangeTemporary   = "TEMPO"
+	ForecastChangeProbability = "PROB"
+)
+
+// TerminalForecast is an aerodrome forecast (TAF) of the station with ICAO identifier StationID
+type TerminalForecast struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	StationID string             `json:"stationId" bson:"stationId"`
+	IssuedAt  time.Time          `json:"issuedAt" bson:"issuedAt"`
+	ValidFrom time.Time          `json:"validFrom" bson:"validFrom"`
+	ValidTo   time.Time          `json:"validTo" bson:"validTo"`
+	Amended   bool               `json:"amended" bson:"amended"`
+	Raw       string             `json:"raw" bson:"raw"`
+	Base      ForecastConditions `json:"base" bson:"base"`
+	Changes   []ForecastChange   `json:"changes" bson:"changes"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+type ForecastChange struct {
+	Type        string             `json:"type" bson:"type"`
+	Probability int                `json:"probability,omitempty" bson:"probability,omitempty"`
+	From        time.Time          `json:"from" bson:"from"`
+	To          time.Time          `json:"to" bson:"to"`
+	Conditions  ForecastConditions `json:"conditions" bson:"conditions"`
+}
+
+// ForecastConditions in SI units: wind speed m/s, visibility and heights in meters.
+// Nil values and empty slices weren't forecast
+type ForecastConditions struct {
+	WindDirection        *float64     `json:"windDirection,omitempty" bson:"windDirection,omitempty"`
+	WindVariable         bool         `json:"windVariable,omitempty" bson:"windVariable,omitempty"`
+	WindSpeed            *float64     `json:"windSpeed,omitempty" bson:"windSpeed,omitempty"`
+	WindGust             *float64     `json:"windGust,omitempty" bson:"windGust,omitempty"`
+	CAVOK                bool         `json:"cavok,omitempty" bson:"cavok,omitempty"`
+	Visibility           *float64     `json:"visibility,omitempty" bson:"visibility,omitempty"`
+	Weather              []string     `json:"weather,omitempty" bson:"weather,omitempty"`
+	NoSignificantWeather bool         `json:"nsw,omitempty" bson:"nsw,omitempty"`
+	Clouds               []CloudLayer `json:"clouds,omitempty" bson:"clouds,omitempty"`
+	VerticalVisibility   *float64     `json:"verticalVisibility,omitempty" bson:"verticalVisibility,omitempty"`
+}
+
+type CloudLayer struct {
+	Cover  string   `json:"cover" bson:"cover"`
+	Height *float64 `json:"height,omitempty" bson:"height,omitempty"`
+	Type   string   `json:"type,omitempty" bson:"type,omitempty"`
+}
+
+// Merge overlays forecast conditions of the change onto c
+func (c ForecastConditions) Merge(change ForecastConditions) ForecastConditions {
+	if change.WindSpeed != nil || change.WindVariable {
+		c.WindDirection, c.WindVariable, c.WindSpeed, c.WindGust = change.WindDirection, change.WindVariable, change.WindSpeed, change.WindGust
+	}
+
+	if change.CAVOK {
+		return ForecastConditions{
+			WindDirection: c.WindDirection,
+			WindVariable:  c.WindVariable,
+			WindSpeed:     c.WindSpeed,
+			WindGust:      c.WindGust,
+			CAVOK:         true,
+		}
+	}
+
+	if change.Visibility != nil {
+		c.Visibility, c.CAVOK = change.Visibility, false
+	}
+
+	if change.NoSignificantWeather {
+		c.Weather = nil
+	}
+
+	if len(change.Weather) > 0 {
+		c.Weather = change.Weather
+	}
+
+	if len(change.Clouds) > 0 {
+		c.Clouds, c.VerticalVisibility, c.CAVOK = change.Clouds, nil, false
+	}
+
+	if change.VerticalVisibility != nil {
+		c.VerticalVisibility, c.Clouds, c.CAVOK = change.VerticalVisibility, nil, false
+	}
+
+	return c
+}
+
+// ForecastInEffect is a forecast for the specific time: prevailing conditions
+// and changes (TEMPO, PROB, ongoing BECMG) that may occur at that time
+type ForecastInEffect struct {
+	Forecast   TerminalForecast   `json:"forecast"`
+	At         time.Time          `json:"at"`
+	Prevailing ForecastConditions `json:"prevailing"`
+	Possible   []ForecastChange   `json:"possible"`
+}
+
+// InEffect returns forecast conditions at the given time
+func (f TerminalForecast) InEffect(at time.Time) ForecastInEffect {
+	res := ForecastInEffect{
+		Forecast:   f,
+		At:         at,
+		Prevailing: f.Base,
+		Possible:   make([]ForecastChange, 0),
+	}
+
+	for _, change := range f.Changes {
+		switch change.Type {
+		case ForecastChangeFrom:
+			if !at.Before(change.From) {
+				res.Prevailing = change.Conditions
+			}
+		case ForecastChangeBecoming:
+			if !at.Before(change.To) {
+				res.Prevailing = res.Prevailing.Merge(change.Conditions)
+			} else if !at.Before(change.From) {
+				res.Possible = append(res.Possible, change)
+			}
+		default:
+			if !at.Before(change.From) && at.Before(change.To) {
+				res.Possible = append(res.Possible, change)
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,21 @@
+package domain
+
+const GeoTypePoint = "Point"
+
+// GeoPoint is a GeoJSON point. Coordinates are longitude and latitude in this order
+type GeoPoint struct {
+	Type        string     `json:"type" bson:"type"`
+	Coordinates [2]float64 `json:"coordinates" bson:"coordinates"`
+}
+
+func NewGeoPoint(latitude, longitude float64) GeoPoint {
+	return GeoPoint{Type: GeoTypePoint, Coordinates: [2]float64{longitude, latitude}}
+}
+
+func (p GeoPoint) Latitude() float64 {
+	return p.Coordinates[1]
+}
+
+func (p GeoPoint) Longitude() float64 {
+	return p.Coordinates[0]
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Model parameters are stored in observation units under observation field names,
+// wind is stored as vector components so that it can be interpolated
+const (
+	ModelParameterWindU = "windU"
+	ModelParameterWindV = "windV"
+)
+
+// ModelRun is NWP model run loaded from GRIB2 files. All fields of the run share the Grid
+type ModelRun struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Model      string             `json:"model" bson:"model"`
+	RunTime    time.Time          `json:"runTime" bson:"runTime"`
+	Grid       ModelGrid          `json:"grid" bson:"grid"`
+	Parameters []string           `json:"parameters" bson:"parameters"`
+	ValidFrom  time.Time          `json:"validFrom" bson:"validFrom"`
+	ValidTo    time.Time          `json:"validTo" bson:"validTo"`
+	Files      []string           `json:"files" bson:"files"`
+	LoadedAt   time.Time          `json:"loadedAt" bson:"loadedAt"`
+}
+
+// ModelGrid is regular latitude/longitude grid in terms of GRIB2 grid definition template 3.0
+type ModelGrid struct {
+	Ni       int     `json:"ni" bson:"ni"`
+	Nj       int     `json:"nj" bson:"nj"`
+	La1      float64 `json:"la1" bson:"la1"`
+	Lo1      float64 `json:"lo1" bson:"lo1"`
+	La2      float64 `json:"la2" bson:"la2"`
+	Lo2      float64 `json:"lo2" bson:"lo2"`
+	Di       float64 `json:"di" bson:"di"`
+	Dj       float64 `json:"dj" bson:"dj"`
+	ScanMode int     `json:"scanMode" bson:"scanMode"`
+}
+
+// ModelFieldRow holds values of the model field along j-th row of the grid ordered by i, missing values are NaN.
+// Accumulated parameters like precipitation hold amount since AccumulatedFrom
+type ModelFieldRow struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty"`
+	RunID           primitive.ObjectID `bson:"runId"`
+	Parameter       string             `bson:"parameter"`
+	ValidTime       time.Time          `bson:"validTime"`
+	AccumulatedFrom time.Time          `bson:"accumulatedFrom,omitempty"`
+	Row             int                `bson:"row"`
+	Values          []float64          `bson:"values"`
+}
+
+// ModelForecast is model forecast at a point valid at ValidTime in observation units.
+// Precipitation is amount since the previous forecast time. Nil values weren't forecast
+type ModelForecast struct {
+	ValidTime     time.Time `json:"validTime"`
+	Temperature   *float64  `json:"temperature,omitempty"`
+	Humidity      *float64  `json:"humidity,omitempty"`
+	Pressure      *float64  `json:"pressure,omitempty"`
+	WindSpeed     *float64  `json:"windSpeed,omitempty"`
+	WindGust      *float64  `json:"windGust,omitempty"`
+	WindDirection *float64  `json:"windDirection,omitempty"`
+	Precipitation *float64  `json:"precipitation,omitempty"`
+}
+
+// Value returns forecast value of the observation field, nil if it wasn't forecast or the field is unknown
+func (f *ModelForecast) Value(field string) *float64 {
+	if ptr := f.fieldPtr(field); ptr != nil {
+		return *ptr
+	}
+
+	return nil
+}
+
+func (f *ModelForecast) SetValue(field string, value *float64) {
+	if ptr := f.fieldPtr(field); ptr != nil {
+		*ptr = value
+	}
+}
+
+func (f *ModelForecast) fieldPtr(field string) **float64 {
+	switch field {
+	case FieldTemperature:
+		return &f.Temperature
+	case FieldHumidity:
+		return &f.Humidity
+	case FieldPressure:
+		return &f.Pressure
+	case FieldWindSpeed:
+		return &f.WindSpeed
+	case FieldWindGust:
+		return &f.WindGust
+	case FieldWindDirection:
+		return &f.WindDirection
+	case FieldPrecipitation:
+		return &f.Precipitation
+	default:
+		return nil
+	}
+}
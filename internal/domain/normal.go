@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const (
+	NormalPeriodDaily   = "daily"
+	NormalPeriodMonthly = "monthly"
+
+	// NormalTemperatureMin and NormalTemperatureMax are normals of daily extremes of temperature,
+	// other elements are named after observation fields
+	NormalTemperatureMin = "temperatureMin"
+	NormalTemperatureMax = "temperatureMax"
+
+	NormalsJobQueued  = "queued"
+	NormalsJobRunning = "running"
+	NormalsJobDone    = "done"
+	NormalsJobFailed  = "failed"
+)
+
+// NormalElements lists climatological elements normals are computed for
+var NormalElements = []string{
+	FieldTemperature,
+	NormalTemperatureMin,
+	NormalTemperatureMax,
+	FieldHumidity,
+	FieldPressure,
+	FieldWindSpeed,
+	FieldWindGust,
+	FieldPrecipitation,
+}
+
+func IsNormalElement(element string) bool {
+	for _, e := range NormalElements {
+		if e == element {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Normal is climatological normal of the element for a calendar day or month over FromYear-ToYear.
+// Day is zero for monthly normals. Percentiles holds values at every 5th percentile from 0 to 100,
+// Samples is the number of daily or monthly values the normal is computed from
+type Normal struct {
+	ID          primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	StationID   string             `json:"stationId" bson:"stationId"`
+	Element     string             `json:"element" bson:"element"`
+	Period      string             `json:"period" bson:"period"`
+	Month       int                `json:"month" bson:"month"`
+	Day         int                `json:"day,omitempty" bson:"day,omitempty"`
+	Mean        float64            `json:"mean" bson:"mean"`
+	StdDev      float64            `json:"stdDev" bson:"stdDev"`
+	Percentiles []float64          `json:"percentiles" bson:"percentiles"`
+	Samples     int                `json:"samples" bson:"samples"`
+	Years       int                `json:"years" bson:"years"`
+	FromYear    int                `json:"fromYear" bson:"fromYear"`
+	ToYear      int                `json:"toYear" bson:"toYear"`
+	ComputedAt  time.Time          `json:"computedAt" bson:"computedAt"`
+}
+
+// NormalsJob is a background recomputation of normals of StationIDs, all stations if it's empty
+type NormalsJob struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Status         string             `json:"status" bson:"status"`
+	StationIDs     []string           `json:"stationIds,omitempty" bson:"stationIds,omitempty"`
+	FromYear       int                `json:"fromYear" bson:"fromYear"`
+	ToYear         int                `json:"toYear" bson:"toYear"`
+	Total          int                `json:"total" bson:"total"`
+	Processed      int                `json:"processed" bson:"processed"`
+	FailedStations []string           `json:"failedStations,omitempty" bson:"failedStations,omitempty"`
+	Error          string             `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt" bson:"createdAt"`
+	StartedAt      time.Time          `json:"startedAt,omitempty" bson:"startedAt,omitempty"`
+	FinishedAt     time.Time          `json:"finishedAt,omitempty" bson:"finishedAt,omitempty"`
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Observation is a single set of measurements reported by a station.
+// Units: temperature °C, humidity %, pressure hPa, wind speed and gust m/s,
+// wind direction degrees, precipitation mm. Nil values weren't reported.
+// Pressure is reduced to sea level (QNH or QFF), StationPressure is measured at station elevation.
+// QC holds quality control flags per field, Computed holds derived quantities
+// requested by API clients, it isn't stored
+type Observation struct {
+	ID              primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	StationID       string                 `json:"stationId" bson:"stationId"`
+	Timestamp       time.Time              `json:"timestamp" bson:"timestamp"`
+	ReceivedAt      time.Time              `json:"receivedAt" bson:"receivedAt"`
+	Temperature     *float64               `json:"temperature,omitempty" bson:"temperature,omitempty"`
+	Humidity        *float64               `json:"humidity,omitempty" bson:"humidity,omitempty"`
+	Pressure        *float64               `json:"pressure,omitempty" bson:"pressure,omitempty"`
+	StationPressure *float64               `json:"stationPressure,omitempty" bson:"stationPressure,omitempty"`
+	WindSpeed       *float64               `json:"windSpeed,omitempty" bson:"windSpeed,omitempty"`
+	WindGust        *float64               `json:"windGust,omitempty" bson:"windGust,omitempty"`
+	WindDirection   *float64               `json:"windDirection,omitempty" bson:"windDirection,omitempty"`
+	Precipitation   *float64               `json:"precipitation,omitempty" bson:"precipitation,omitempty"`
+	QC              map[string]QualityFlag `json:"qc,omitempty" bson:"qc,omitempty"`
+	Computed        map[string]float64     `json:"computed,omitempty" bson:"-"`
+}
+
+const (
+	FieldTemperature     = "temperature"
+	FieldHumidity        = "humidity"
+	FieldPressure        = "pressure"
+	FieldStationPressure = "stationPressure"
+	FieldWindSpeed       = "windSpeed"
+	FieldWindGust        = "windGust"
+	FieldWindDirection   = "windDirection"
+	FieldPrecipitation   = "precipitation"
+)
+
+// ObservationFields lists names of all meteorological fields of Observation
+var ObservationFields = []string{
+	FieldTemperature,
+	FieldHumidity,
+	FieldPressure,
+	FieldStationPressure,
+	FieldWindSpeed,
+	FieldWindGust,
+	FieldWindDirection,
+	FieldPrecipitation,
+}
+
+func IsObservationField(field string) bool {
+	for _, f := range ObservationFields {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Value returns value of the field with given name, nil if it wasn't reported or there is no such field
+func (o *Observation) Value(field string) *float64 {
+	if ptr := o.fieldPtr(field); ptr != nil {
+		return *ptr
+	}
+
+	return nil
+}
+
+// SetValue sets the field with given name, nil value unsets it
+func (o *Observation) SetValue(field string, value *float64) {
+	if ptr := o.fieldPtr(field); ptr != nil {
+		*ptr = value
+	}
+}
+
+func (o *Observation) fieldPtr(field string) **float64 {
+	switch field {
+	case FieldTemperature:
+		return &o.Temperature
+	case FieldHumidity:
+		return &o.Humidity
+	case FieldPressure:
+		return &o.Pressure
+	case FieldStationPressure:
+		return &o.StationPressure
+	case FieldWindSpeed:
+		return &o.WindSpeed
+	case FieldWindGust:
+		return &o.WindGust
+	case FieldWindDirection:
+		return &o.WindDirection
+	case FieldPrecipitation:
+		return &o.Precipitation
+	default:
+		return nil
+	}
+}
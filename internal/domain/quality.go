@@ -0,0 +1,43 @@
+package domain
+
+const (
+	QualityGood      = "good"
+	QualitySuspect   = "suspect"
+	QualityBad       = "bad"
+	QualityUnchecked = "unchecked"
+
+	QualityCheckRange       = "range"
+	QualityCheckStep        = "step"
+	QualityCheckPersistence = "persistence"
+	QualityCheckConsistency = "consistency"
+)
+
+// QualityFlags lists flags values can be tagged with. Values without flag are unchecked
+var QualityFlags = []string{QualityGood, QualitySuspect, QualityBad, QualityUnchecked}
+
+// QualityFlag is quality control result of a single observation value.
+// Checks lists failed checks, Manual is set when the flag was overridden by admin
+type QualityFlag struct {
+	Flag   string   `json:"flag" bson:"flag"`
+	Checks []string `json:"checks,omitempty" bson:"checks,omitempty"`
+	Manual bool     `json:"manual,omitempty" bson:"manual,omitempty"`
+}
+
+func IsQualityFlag(flag string) bool {
+	for _, f := range QualityFlags {
+		if f == flag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Quality returns quality flag of the field value
+func (o *Observation) Quality(field string) string {
+	if flag, ok := o.QC[field]; ok {
+		return flag.Flag
+	}
+
+	return QualityUnchecked
+}
@@ -0,0 +1,96 @@
+package domain
+
+const (
+	RoleViewer       = "viewer"
+	RoleOperator     = "operator"
+	RoleStationOwner = "station-owner"
+	RoleAdmin        = "admin"
+
+	PermissionDataRead          = "data:read"
+	PermissionObservationsWrite = "observations:write"
+	PermissionForecastsWrite    = "forecasts:write"
+	PermissionStationsRead      = "stations:read"
+	PermissionStationsWrite     = "stations:write"
+	PermissionStationsManage    = "stations:manage"
+	PermissionClimateManage     = "climate:manage"
+	PermissionUsersManage       = "users:manage"
+	PermissionAdminsManage      = "admins:manage"
+)
+
+// Roles lists roles that can be granted. Viewers read observations, forecasts and climate data, operators
+// also import observations, override quality flags and issue forecasts, station owners maintain their stations
+var Roles = []string{RoleViewer, RoleOperator, RoleStationOwner, RoleAdmin}
+
+var rolePermissions = map[string][]string{
+	RoleViewer: {PermissionDataRead},
+	RoleOperator: {
+		PermissionDataRead, PermissionObservationsWrite, PermissionForecastsWrite, PermissionStationsRead,
+	},
+	RoleStationOwner: {PermissionDataRead, PermissionStationsRead, PermissionStationsWrite},
+	RoleAdmin: {
+		PermissionDataRead, PermissionObservationsWrite, PermissionForecastsWrite, PermissionStationsRead,
+		PermissionStationsWrite, PermissionStationsManage, PermissionClimateManage, PermissionUsersManage,
+		PermissionAdminsManage,
+	},
+}
+
+// Grant gives permissions of the role for Stations only or for all stations if Stations is empty.
+// Stations are WMO or ICAO identifiers
+type Grant struct {
+	Role     string   `json:"role" bson:"role"`
+	Stations []string `json:"stations,omitempty" bson:"stations,omitempty"`
+}
+
+func IsRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission reports whether the role has the permission
+func HasPermission(role, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Allows reports whether grants give the permission for all the stations. Global grants allow everything,
+// grants for stations allow the permission only if stations are given and each of them is granted
+func Allows(grants []Grant, permission string, stations ...string) bool {
+	for _, g := range grants {
+		if len(g.Stations) == 0 && HasPermission(g.Role, permission) {
+			return true
+		}
+	}
+
+	if len(stations) == 0 {
+		return false
+	}
+
+	for _, station := range stations {
+		if !allowsStation(grants, permission, station) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func allowsStation(grants []Grant, permission, station string) bool {
+	for _, g := range grants {
+		if !HasPermission(g.Role, permission) {
+			continue
+		}
+
+		for _, s := range g.Stations {
+			if s == station {
+				return true
+			}
+		}
+	}
+
+	return false
+}
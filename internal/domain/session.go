@@ -1,8 +1,26 @@
 package domain
 
-import "time"
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
 
 type Session struct {
-	RefreshToken string `json:"refresh_token" bson:"refresh_token"`
-	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+	RefreshToken string    `json:"refreshToken" bson:"refreshToken"`
+	ExpiresAt    time.Time `json:"expiresAt" bson:"expiresAt"`
+}
+
+// UserSession is a device the user is signed in on. Refresh token is rotated on every refresh, previous
+// tokens are kept in RotatedTokens to detect their reuse. Tokens are stored as SHA-256 hashes
+type UserSession struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID        primitive.ObjectID `json:"-" bson:"userId"`
+	RefreshToken  string             `json:"-" bson:"refreshToken"`
+	RotatedTokens []string           `json:"-" bson:"rotatedTokens"`
+	UserAgent     string             `json:"userAgent" bson:"userAgent"`
+	IP            string             `json:"ip" bson:"ip"`
+	CreatedAt     time.Time          `json:"createdAt" bson:"createdAt"`
+	LastUsedAt    time.Time          `json:"lastUsedAt" bson:"lastUsedAt"`
+	ExpiresAt     time.Time          `json:"expiresAt" bson:"expiresAt"`
+	Current       bool               `json:"current" bson:"-"`
 }
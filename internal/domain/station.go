@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const (
+	StationStatusActive         = "active"
+	StationStatusMaintenance    = "maintenance"
+	StationStatusDecommissioned = "decommissioned"
+
+	StationTypeSynoptic  = "synoptic"
+	StationTypeAviation  = "aviation"
+	StationTypeAutomatic = "automatic"
+	StationTypeClimate   = "climate"
+)
+
+// Station is a meteo station. Observations reference station by its WMO or ICAO identifier.
+// ReportInterval is expected interval between observations in seconds, zero means default one.
+// Location duplicates Latitude and Longitude as GeoJSON point for geospatial queries
+type Station struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	WMO       string             `json:"wmo,omitempty" bson:"wmo,omitempty"`
+	ICAO      string             `json:"icao,omitempty" bson:"icao,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	Latitude  float64            `json:"latitude" bson:"latitude"`
+	Longitude float64            `json:"longitude" bson:"longitude"`
+	Elevation float64            `json:"elevation" bson:"elevation"`
+	Location  GeoPoint           `json:"-" bson:"location"`
+	Type      string             `json:"type" bson:"type"`
+	Sensors   []StationSensor    `json:"sensors" bson:"sensors"`
+	Status    string             `json:"status" bson:"status"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+
+	ReportInterval int64         `json:"reportInterval" bson:"reportInterval"`
+	Health         StationHealth `json:"health" bson:"health"`
+}
+
+// Identifier returns identifier observations of the station are reported with, WMO one is preferred
+func (s Station) Identifier() string {
+	if s.WMO != "" {
+		return s.WMO
+	}
+
+	return s.ICAO
+}
+
+// Identifiers returns all non-empty identifiers of the station
+func (s Station) Identifiers() []string {
+	identifiers := make([]string, 0, 2)
+	if s.WMO != "" {
+		identifiers = append(identifiers, s.WMO)
+	}
+
+	if s.ICAO != "" {
+		identifiers = append(identifiers, s.ICAO)
+	}
+
+	return identifiers
+}
+
+type StationSensor struct {
+	Type        string    `json:"type" bson:"type"`
+	Model       string    `json:"model" bson:"model"`
+	Serial      string    `json:"serial" bson:"serial"`
+	InstalledAt time.Time `json:"installedAt" bson:"installedAt"`
+}
+
+// StationDistance is a station found by geospatial search with distance to the searched point in km
+type StationDistance struct {
+	Station  `bson:",inline"`
+	Distance *float64 `json:"distance,omitempty" bson:"distance,omitempty"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const (
+	StationHealthUnknown = "unknown"
+	StationHealthOnline  = "online"
+	StationHealthLate    = "late"
+	StationHealthOffline = "offline"
+)
+
+// StationHealth is reporting state of the station, updated by health monitor.
+// Empty State means the station wasn't checked yet
+type StationHealth struct {
+	State             string    `json:"state,omitempty" bson:"state,omitempty"`
+	LastObservationAt time.Time `json:"lastObservationAt,omitempty" bson:"lastObservationAt,omitempty"`
+	ChangedAt         time.Time `json:"changedAt,omitempty" bson:"changedAt,omitempty"`
+}
+
+// StationHealthEvent records transition of the station to State. Uptime is computed from these events
+type StationHealthEvent struct {
+	ID                primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	StationID         primitive.ObjectID `json:"stationId" bson:"stationId"`
+	Identifier        string             `json:"identifier" bson:"identifier"`
+	State             string             `json:"state" bson:"state"`
+	PreviousState     string             `json:"previousState,omitempty" bson:"previousState,omitempty"`
+	LastObservationAt time.Time          `json:"lastObservationAt,omitempty" bson:"lastObservationAt,omitempty"`
+	CreatedAt         time.Time          `json:"createdAt" bson:"createdAt"`
+}
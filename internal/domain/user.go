@@ -13,10 +13,19 @@ type User struct {
 	RegisteredAt time.Time          `json:"registeredAt" bson:"registeredAt"`
 	LastVisitAt  time.Time          `json:"lastVisitAt" bson:"lastVisitAt"`
 	Verification Verification       `json:"verification" bson:"verification"`
-	Session      Session            `json:"session" bson:"session,omitempty"`
+	Preferences  Preferences        `json:"preferences" bson:"preferences"`
+	Grants       []Grant            `json:"grants" bson:"grants,omitempty"`
 }
 
 type Verification struct {
 	Code     string `json:"code" bson:"code"`
 	Verified bool   `json:"verified" bson:"verified"`
 }
+
+// Preferences of observations presentation. Units is a unit system name, Timezone is IANA
+// time zone name and Locale is BCP 47 language tag. Empty values mean metric units and UTC
+type Preferences struct {
+	Units    string `json:"units" bson:"units,omitempty"`
+	Timezone string `json:"timezone" bson:"timezone,omitempty"`
+	Locale   string `json:"locale" bson:"locale,omitempty"`
+}
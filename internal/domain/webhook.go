@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const (
+	WebhookEventAlertFired         = "alert.fired"
+	WebhookEventAlertResolved      = "alert.resolved"
+	WebhookEventStationOffline     = "station.offline"
+	WebhookEventStationLate        = "station.late"
+	WebhookEventStationOnline      = "station.online"
+	WebhookEventObservationCreated = "observation.created"
+	WebhookEventPing               = "ping"
+)
+
+// WebhookEvents lists event types webhooks can subscribe to
+var WebhookEvents = []string{
+	WebhookEventAlertFired,
+	WebhookEventAlertResolved,
+	WebhookEventStationOffline,
+	WebhookEventStationLate,
+	WebhookEventStationOnline,
+	WebhookEventObservationCreated,
+}
+
+// Webhook is user endpoint receiving events of subscribed types. Empty StationIDs means all stations.
+// Secret signs deliveries, it's shown to the user only once on creation
+type Webhook struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"userId" bson:"userId"`
+	URL        string             `json:"url" bson:"url"`
+	Secret     string             `json:"-" bson:"secret"`
+	Events     []string           `json:"events" bson:"events"`
+	StationIDs []string           `json:"stationIds" bson:"stationIds"`
+	Enabled    bool               `json:"enabled" bson:"enabled"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt  time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// WebhookDelivery is a single attempt to deliver an event. All attempts of the event share DeliveryID
+type WebhookDelivery struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	WebhookID   primitive.ObjectID `json:"webhookId" bson:"webhookId"`
+	UserID      primitive.ObjectID `json:"userId" bson:"userId"`
+	DeliveryID  string             `json:"deliveryId" bson:"deliveryId"`
+	Event       string             `json:"event" bson:"event"`
+	URL         string             `json:"url" bson:"url"`
+	Payload     string             `json:"payload" bson:"payload"`
+	Attempt     int                `json:"attempt" bson:"attempt"`
+	Success     bool               `json:"success" bson:"success"`
+	StatusCode  int                `json:"statusCode,omitempty" bson:"statusCode,omitempty"`
+	Response    string             `json:"response,omitempty" bson:"response,omitempty"`
+	Error       string             `json:"error,omitempty" bson:"error,omitempty"`
+	DurationMs  int64              `json:"durationMs" bson:"durationMs"`
+	NextRetryAt *time.Time         `json:"nextRetryAt,omitempty" bson:"nextRetryAt,omitempty"`
+	AttemptedAt time.Time          `json:"attemptedAt" bson:"attemptedAt"`
+}
+
+func IsWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,9 +3,11 @@ package repository
 import (
 	"context"
 	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/database/mongodb"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
 )
 
@@ -17,18 +19,73 @@ func NewAdminsRepo(db *mongo.Database) *AdminsRepo {
 	return &AdminsRepo{db: db.Collection(adminsCollection)}
 }
 
-func (r *AdminsRepo) GetByCredentials(ctx context.Context, email, password string) (domain.Admin, error) {
+func (r *AdminsRepo) Create(ctx context.Context, admin domain.Admin) (primitive.ObjectID, error) {
+	res, err := r.db.InsertOne(ctx, admin)
+	if err != nil {
+		if mongodb.IsDuplicate(err) {
+			return primitive.ObjectID{}, ErrAdminAlreadyExists
+		}
+
+		return primitive.ObjectID{}, err
+	}
+
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *AdminsRepo) GetAll(ctx context.Context) ([]domain.Admin, error) {
+	cur, err := r.db.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "email", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	admins := make([]domain.Admin, 0)
+	err = cur.All(ctx, &admins)
+
+	return admins, err
+}
+
+// GetByEmail returns admin, password is checked by the service against the stored hash
+func (r *AdminsRepo) GetByEmail(ctx context.Context, email string) (domain.Admin, error) {
 	var admin domain.Admin
-	err := r.db.FindOne(ctx, bson.M{"email": email, "password": password}).Decode(&admin)
+	if err := r.db.FindOne(ctx, bson.M{"email": email}).Decode(&admin); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Admin{}, ErrAdminNotFound
+		}
 
-	return admin, err
+		return domain.Admin{}, err
+	}
+
+	return admin, nil
 }
 
 func (r *AdminsRepo) GetByRefreshToken(ctx context.Context, refreshToken string) (domain.Admin, error) {
 	var admin domain.Admin
-	err := r.db.FindOne(ctx, bson.M{"session.refreshToken": refreshToken, "session.expiresAt": bson.M{"$gt": time.Now()}}).Decode(&admin)
+	if err := r.db.FindOne(ctx, bson.M{"session.refreshToken": refreshToken, "session.expiresAt": bson.M{"$gt": time.Now()}}).Decode(&admin); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Admin{}, ErrAdminNotFound
+		}
+
+		return domain.Admin{}, err
+	}
+
+	return admin, nil
+}
+
+func (r *AdminsRepo) GetById(ctx context.Context, id primitive.ObjectID) (domain.Admin, error) {
+	var admin domain.Admin
+	if err := r.db.FindOne(ctx, bson.M{"_id": id}).Decode(&admin); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Admin{}, ErrAdminNotFound
+		}
+
+		return domain.Admin{}, err
+	}
 
-	return admin, err
+	return admin, nil
+}
+
+func (r *AdminsRepo) CountSuperAdmins(ctx context.Context) (int64, error) {
+	return r.db.CountDocuments(ctx, bson.M{"superAdmin": true})
 }
 
 func (r *AdminsRepo) SetSession(ctx context.Context, id primitive.ObjectID, session domain.Session) error {
@@ -36,6 +93,50 @@ func (r *AdminsRepo) SetSession(ctx context.Context, id primitive.ObjectID, sess
 	return err
 }
 
-func (r *AdminsRepo) GetById(ctx context.Context, id primitive.ObjectID) (domain.Admin, error) {
-	return domain.Admin{}, nil
+func (r *AdminsRepo) Update(ctx context.Context, inp UpdateAdminInput) error {
+	updateQuery := bson.M{}
+
+	if inp.Name != nil {
+		updateQuery["name"] = *inp.Name
+	}
+
+	if inp.Email != nil {
+		updateQuery["email"] = *inp.Email
+	}
+
+	if inp.Password != nil {
+		updateQuery["password"] = *inp.Password
+	}
+
+	if inp.SuperAdmin != nil {
+		updateQuery["superAdmin"] = *inp.SuperAdmin
+	}
+
+	res, err := r.db.UpdateOne(ctx, bson.M{"_id": inp.ID}, bson.M{"$set": updateQuery})
+	if err != nil {
+		if mongodb.IsDuplicate(err) {
+			return ErrAdminAlreadyExists
+		}
+
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrAdminNotFound
+	}
+
+	return nil
+}
+
+func (r *AdminsRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.db.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		return ErrAdminNotFound
+	}
+
+	return nil
 }
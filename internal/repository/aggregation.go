@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math"
+	"time"
+)
+
+const (
+	windDirectionSin = "windDirection_sin"
+	windDirectionCos = "windDirection_cos"
+)
+
+// Aggregate groups observations into time buckets and calculates statistics of requested fields
+func (r *ObservationsRepo) Aggregate(ctx context.Context, query ObservationsAggregationQuery) ([]domain.ObservationAggregate, error) {
+	group := bson.M{
+		"_id":   bson.M{"stationId": "$stationId", "start": bucketExpression(query)},
+		"count": bson.M{"$sum": 1},
+	}
+
+	for _, field := range query.Fields {
+		var ref interface{} = "$" + field
+		if query.SkipBad {
+			ref = bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$qc." + field + ".flag", domain.QualityBad}}, nil, ref}}
+		}
+
+		group[field+"_min"] = bson.M{"$min": ref}
+		group[field+"_max"] = bson.M{"$max": ref}
+
+		switch field {
+		case domain.FieldWindDirection:
+			radians := bson.M{"$degreesToRadians": ref}
+			group[windDirectionSin] = bson.M{"$avg": bson.M{"$sin": radians}}
+			group[windDirectionCos] = bson.M{"$avg": bson.M{"$cos": radians}}
+		case domain.FieldPrecipitation:
+			group[field+"_avg"] = bson.M{"$avg": ref}
+			group[field+"_sum"] = bson.M{"$sum": ref}
+		default:
+			group[field+"_avg"] = bson.M{"$avg": ref}
+		}
+	}
+
+	pipeline := []bson.M{
+		{"$match": observationsFilter(ObservationsQuery{StationIDs: query.StationIDs, From: query.From, To: query.To})},
+		{"$group": group},
+		{"$sort": bson.D{{Key: "_id.stationId", Value: 1}, {Key: "_id.start", Value: 1}}},
+	}
+
+	cur, err := r.db.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	aggregates := make([]domain.ObservationAggregate, 0)
+	for cur.Next(ctx) {
+		var doc bson.M
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		aggregates = append(aggregates, toObservationAggregate(doc, query.Fields))
+	}
+
+	return aggregates, cur.Err()
+}
+
+// bucketExpression returns expression evaluating to the start of observation's bucket
+func bucketExpression(query ObservationsAggregationQuery) interface{} {
+	if query.Monthly {
+		return bson.M{"$dateFromParts": bson.M{
+			"year":  bson.M{"$year": "$timestamp"},
+			"month": bson.M{"$month": "$timestamp"},
+		}}
+	}
+
+	millis := bson.M{"$toLong": "$timestamp"}
+	size := query.BucketSize.Milliseconds()
+
+	return bson.M{"$toDate": bson.M{"$subtract": bson.A{millis, bson.M{"$mod": bson.A{millis, size}}}}}
+}
+
+func toObservationAggregate(doc bson.M, fields []string) domain.ObservationAggregate {
+	id, _ := doc["_id"].(bson.M)
+	stationId, _ := id["stationId"].(string)
+
+	aggregate := domain.ObservationAggregate{
+		StationID: stationId,
+		Start:     toTime(id["start"]),
+		Count:     int64(toFloat(doc["count"])),
+		Fields:    make(map[string]domain.FieldStats, len(fields)),
+	}
+
+	for _, field := range fields {
+		stats := domain.FieldStats{
+			Min: toFloatPtr(doc[field+"_min"]),
+			Max: toFloatPtr(doc[field+"_max"]),
+		}
+
+		switch field {
+		case domain.FieldWindDirection:
+			stats.Mean = circularMean(toFloatPtr(doc[windDirectionSin]), toFloatPtr(doc[windDirectionCos]))
+		case domain.FieldPrecipitation:
+			stats.Mean = toFloatPtr(doc[field+"_avg"])
+			if stats.Min != nil {
+				stats.Sum = toFloatPtr(doc[field+"_sum"])
+			}
+		default:
+			stats.Mean = toFloatPtr(doc[field+"_avg"])
+		}
+
+		aggregate.Fields[field] = stats
+	}
+
+	return aggregate
+}
+
+// circularMean returns direction in degrees [0, 360) of the mean unit vector
+func circularMean(sin, cos *float64) *float64 {
+	if sin == nil || cos == nil {
+		return nil
+	}
+
+	degrees := math.Atan2(*sin, *cos) * 180 / math.Pi
+	if degrees < 0 {
+		degrees += 360
+	}
+
+	return &degrees
+}
+
+func toFloatPtr(value interface{}) *float64 {
+	if value == nil {
+		return nil
+	}
+
+	res := toFloat(value)
+
+	return &res
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	default:
+		return 0
+	}
+}
+
+func toTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC()
+	case primitive.DateTime:
+		return v.Time().UTC()
+	default:
+		return time.Time{}
+	}
+}
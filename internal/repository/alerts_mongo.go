@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type AlertRulesRepo struct {
+	db *mongo.Collection
+}
+
+func NewAlertRulesRepo(db *mongo.Database) *AlertRulesRepo {
+	return &AlertRulesRepo{
+		db: db.Collection(alertRulesCollection),
+	}
+}
+
+func (r *AlertRulesRepo) Create(ctx context.Context, rule domain.AlertRule) (primitive.ObjectID, error) {
+	res, err := r.db.InsertOne(ctx, rule)
+	if err != nil {
+		return primitive.ObjectID{}, err
+	}
+
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *AlertRulesRepo) GetByUser(ctx context.Context, userId primitive.ObjectID) ([]domain.AlertRule, error) {
+	return r.find(ctx, bson.M{"userId": userId})
+}
+
+func (r *AlertRulesRepo) GetById(ctx context.Context, userId, id primitive.ObjectID) (domain.AlertRule, error) {
+	var rule domain.AlertRule
+	if err := r.db.FindOne(ctx, bson.M{"_id": id, "userId": userId}).Decode(&rule); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.AlertRule{}, ErrAlertRuleNotFound
+		}
+
+		return domain.AlertRule{}, err
+	}
+
+	return rule, nil
+}
+
+func (r *AlertRulesRepo) GetEnabledByStation(ctx context.Context, stationId string) ([]domain.AlertRule, error) {
+	return r.find(ctx, bson.M{"stationId": stationId, "enabled": true})
+}
+
+func (r *AlertRulesRepo) Update(ctx context.Context, inp UpdateAlertRuleInput) error {
+	updateQuery := bson.M{"updatedAt": time.Now()}
+
+	if inp.Name != nil {
+		updateQuery["name"] = *inp.Name
+	}
+
+	if inp.Operator != nil {
+		updateQuery["operator"] = *inp.Operator
+	}
+
+	if inp.Threshold != nil {
+		updateQuery["threshold"] = *inp.Threshold
+	}
+
+	if inp.Hysteresis != nil {
+		updateQuery["hysteresis"] = *inp.Hysteresis
+	}
+
+	if inp.ForSeconds != nil {
+		updateQuery["forSeconds"] = *inp.ForSeconds
+	}
+
+	if inp.Enabled != nil {
+		updateQuery["enabled"] = *inp.Enabled
+	}
+
+	res, err := r.db.UpdateOne(ctx, bson.M{"_id": inp.ID, "userId": inp.UserID}, bson.M{"$set": updateQuery})
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrAlertRuleNotFound
+	}
+
+	return nil
+}
+
+func (r *AlertRulesRepo) SetStatus(ctx context.Context, id primitive.ObjectID, status domain.AlertRuleStatus) error {
+	_, err := r.db.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"status": status}})
+
+	return err
+}
+
+func (r *AlertRulesRepo) Delete(ctx context.Context, userId, id primitive.ObjectID) error {
+	res, err := r.db.DeleteOne(ctx, bson.M{"_id": id, "userId": userId})
+	if err != nil {
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		return ErrAlertRuleNotFound
+	}
+
+	return nil
+}
+
+func (r *AlertRulesRepo) find(ctx context.Context, filter bson.M) ([]domain.AlertRule, error) {
+	cur, err := r.db.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]domain.AlertRule, 0)
+	err = cur.All(ctx, &rules)
+
+	return rules, err
+}
+
+type AlertEventsRepo struct {
+	db *mongo.Collection
+}
+
+func NewAlertEventsRepo(db *mongo.Database) *AlertEventsRepo {
+	return &AlertEventsRepo{
+		db: db.Collection(alertEventsCollection),
+	}
+}
+
+func (r *AlertEventsRepo) Create(ctx context.Context, event domain.AlertEvent) error {
+	_, err := r.db.InsertOne(ctx, event)
+
+	return err
+}
+
+// GetByUser returns the latest user alert events, newest first
+func (r *AlertEventsRepo) GetByUser(ctx context.Context, userId primitive.ObjectID, limit int64) ([]domain.AlertEvent, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(limit)
+
+	cur, err := r.db.Find(ctx, bson.M{"userId": userId}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.AlertEvent, 0)
+	err = cur.All(ctx, &events)
+
+	return events, err
+}
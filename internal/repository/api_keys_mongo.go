@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type APIKeysRepo struct {
+	db *mongo.Collection
+}
+
+func NewAPIKeysRepo(db *mongo.Database) *APIKeysRepo {
+	return &APIKeysRepo{
+		db: db.Collection(apiKeysCollection),
+	}
+}
+
+func (r *APIKeysRepo) Create(ctx context.Context, key domain.APIKey) (primitive.ObjectID, error) {
+	res, err := r.db.InsertOne(ctx, key)
+	if err != nil {
+		return primitive.ObjectID{}, err
+	}
+
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *APIKeysRepo) GetByOwner(ctx context.Context, owner domain.APIKeyOwner) ([]domain.APIKey, error) {
+	cur, err := r.db.Find(ctx, ownerFilter(owner), options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]domain.APIKey, 0)
+	err = cur.All(ctx, &keys)
+
+	return keys, err
+}
+
+func (r *APIKeysRepo) GetByHash(ctx context.Context, hash string) (domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.db.FindOne(ctx, bson.M{"hash": hash}).Decode(&key); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.APIKey{}, ErrAPIKeyNotFound
+		}
+
+		return domain.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+func (r *APIKeysRepo) Delete(ctx context.Context, owner domain.APIKeyOwner, id primitive.ObjectID) error {
+	filter := ownerFilter(owner)
+	filter["_id"] = id
+
+	res, err := r.db.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *APIKeysRepo) TrackUsage(ctx context.Context, id primitive.ObjectID, ip string, usedAt time.Time) error {
+	_, err := r.db.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$inc": bson.M{"usage.requests": 1},
+		"$set": bson.M{"usage.lastUsedAt": usedAt, "usage.lastUsedIp": ip},
+	})
+
+	return err
+}
+
+func ownerFilter(owner domain.APIKeyOwner) bson.M {
+	return bson.M{"owner.id": owner.ID, "owner.type": owner.Type}
+}
@@ -3,4 +3,18 @@ package repository
 const (
 	usersCollection = "users"
 	adminsCollection = "admins"
+	observationsCollection = "observations"
+	stationsCollection = "stations"
+	terminalForecastsCollection = "terminalForecasts"
+	alertRulesCollection        = "alertRules"
+	alertEventsCollection       = "alertEvents"
+	webhooksCollection          = "webhooks"
+	webhookDeliveriesCollection = "webhookDeliveries"
+	stationHealthEventsCollection = "stationHealthEvents"
+	modelRunsCollection           = "modelRuns"
+	modelFieldsCollection         = "modelFields"
+	normalsCollection             = "normals"
+	normalsJobsCollection         = "normalsJobs"
+	apiKeysCollection             = "apiKeys"
+	userSessionsCollection        = "userSessions"
 )
@@ -3,10 +3,28 @@ package repository
 import "errors"
 
 var (
-	ErrUserNotFound            = errors.New("user doesn't exists")
-	ErrVerificationCodeInvalid = errors.New("verification code is invalid")
-	ErrOfferNotFound           = errors.New("offer doesn't exists")
-	ErrPromoNotFound           = errors.New("promocode doesn't exists")
-	ErrCourseNotFound          = errors.New("course not found")
-	ErrUserAlreadyExists       = errors.New("user with such email already exists")
+	ErrUserNotFound             = errors.New("user doesn't exists")
+	ErrVerificationCodeInvalid  = errors.New("verification code is invalid")
+	ErrOfferNotFound            = errors.New("offer doesn't exists")
+	ErrPromoNotFound            = errors.New("promocode doesn't exists")
+	ErrCourseNotFound           = errors.New("course not found")
+	ErrUserAlreadyExists        = errors.New("user with such email already exists")
+	ErrAdminNotFound            = errors.New("admin doesn't exists")
+	ErrAdminAlreadyExists       = errors.New("admin with such email already exists")
+	ErrInvalidFrame             = errors.New("invalid sensor frame")
+	ErrStationIdMissing         = errors.New("station id is missing")
+	ErrEmptyReport              = errors.New("report contains no observation")
+	ErrObservationAlreadyExists = errors.New("observation of the station at this time is already stored")
+	ErrObservationNotFound      = errors.New("observation doesn't exists")
+	ErrStationNotFound          = errors.New("station doesn't exists")
+	ErrStationAlreadyExists     = errors.New("station with such identifier already exists")
+	ErrStationHealthNotFound    = errors.New("station health wasn't recorded yet")
+	ErrModelRunNotFound         = errors.New("model run doesn't exists")
+	ErrForecastNotFound         = errors.New("forecast doesn't exists")
+	ErrForecastAlreadyExists    = errors.New("forecast is already stored")
+	ErrAlertRuleNotFound        = errors.New("alert rule doesn't exists")
+	ErrWebhookNotFound          = errors.New("webhook doesn't exists")
+	ErrNormalsJobNotFound       = errors.New("normals job doesn't exists")
+	ErrAPIKeyNotFound           = errors.New("api key doesn't exists")
+	ErrSessionNotFound          = errors.New("session doesn't exists")
 )
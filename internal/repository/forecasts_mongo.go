@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type TerminalForecastsRepo struct {
+	db *mongo.Collection
+}
+
+func NewTerminalForecastsRepo(db *mongo.Database) *TerminalForecastsRepo {
+	return &TerminalForecastsRepo{
+		db: db.Collection(terminalForecastsCollection),
+	}
+}
+
+func (r *TerminalForecastsRepo) Create(ctx context.Context, forecast domain.TerminalForecast) (primitive.ObjectID, error) {
+	res, err := r.db.InsertOne(ctx, forecast)
+	if err != nil {
+		if mongodb.IsDuplicate(err) {
+			return primitive.ObjectID{}, ErrForecastAlreadyExists
+		}
+
+		return primitive.ObjectID{}, err
+	}
+
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+// GetValidAt returns the latest forecast issued before the given time and valid at that time
+func (r *TerminalForecastsRepo) GetValidAt(ctx context.Context, stationId string, at time.Time) (domain.TerminalForecast, error) {
+	var forecast domain.TerminalForecast
+
+	filter := bson.M{
+		"stationId": stationId,
+		"issuedAt":  bson.M{"$lte": at},
+		"validFrom": bson.M{"$lte": at},
+		"validTo":   bson.M{"$gt": at},
+	}
+	opts := options.FindOne().SetSort(bson.M{"issuedAt": -1})
+
+	if err := r.db.FindOne(ctx, filter, opts).Decode(&forecast); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.TerminalForecast{}, ErrForecastNotFound
+		}
+
+		return domain.TerminalForecast{}, err
+	}
+
+	return forecast, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// indexes lists indexes required by repositories per collection
+var indexes = map[string][]mongo.IndexModel{
+	adminsCollection: {
+		{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+	},
+	observationsCollection: {
+		{Keys: bson.D{{Key: "stationId", Value: 1}, {Key: "timestamp", Value: 1}}, Options: options.Index().SetUnique(true)},
+	},
+	terminalForecastsCollection: {
+		{Keys: bson.D{{Key: "stationId", Value: 1}, {Key: "issuedAt", Value: -1}}, Options: options.Index().SetUnique(true)},
+	},
+	alertRulesCollection: {
+		{Keys: bson.D{{Key: "stationId", Value: 1}, {Key: "enabled", Value: 1}}},
+		{Keys: bson.D{{Key: "userId", Value: 1}}},
+	},
+	alertEventsCollection: {
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}},
+	},
+	webhooksCollection: {
+		{Keys: bson.D{{Key: "events", Value: 1}, {Key: "enabled", Value: 1}}},
+		{Keys: bson.D{{Key: "userId", Value: 1}}},
+	},
+	webhookDeliveriesCollection: {
+		{Keys: bson.D{{Key: "webhookId", Value: 1}, {Key: "attemptedAt", Value: -1}}},
+	},
+	stationHealthEventsCollection: {
+		{Keys: bson.D{{Key: "stationId", Value: 1}, {Key: "createdAt", Value: 1}}},
+	},
+	modelRunsCollection: {
+		{Keys: bson.D{{Key: "model", Value: 1}, {Key: "runTime", Value: -1}}, Options: options.Index().SetUnique(true)},
+	},
+	modelFieldsCollection: {
+		{Keys: bson.D{{Key: "runId", Value: 1}, {Key: "row", Value: 1}, {Key: "validTime", Value: 1}}},
+		{Keys: bson.D{{Key: "runId", Value: 1}, {Key: "parameter", Value: 1}, {Key: "validTime", Value: 1}}},
+	},
+	normalsCollection: {
+		{Keys: bson.D{{Key: "stationId", Value: 1}, {Key: "period", Value: 1}, {Key: "month", Value: 1}, {Key: "day", Value: 1}, {Key: "element", Value: 1}}, Options: options.Index().SetUnique(true)},
+	},
+	apiKeysCollection: {
+		{Keys: bson.D{{Key: "hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "owner.id", Value: 1}}},
+	},
+	userSessionsCollection: {
+		{Keys: bson.D{{Key: "refreshToken", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "rotatedTokens", Value: 1}}},
+		{Keys: bson.D{{Key: "userId", Value: 1}}},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	},
+	stationsCollection: {
+		{Keys: bson.D{{Key: "wmo", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+		{Keys: bson.D{{Key: "icao", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+		{Keys: bson.D{{Key: "location", Value: "2dsphere"}}},
+	},
+}
+
+// CreateIndexes creates indexes used by repositories. Existing indexes with the same key and options are left
+// untouched, an index whose options changed has to be dropped by Migrate first or creation fails
+func CreateIndexes(ctx context.Context, db *mongo.Database) error {
+	for collection, models := range indexes {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/metar"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/meteo"
+	"math"
+	"time"
+)
+
+const (
+	FormatMETAR = "metar"
+
+	knotsInMeterPerSecond = 1.943844
+)
+
+// parseMETAR decodes METAR/SPECI frame into observation. Pressure is QNH,
+// humidity is calculated from temperature and dew point
+func parseMETAR(data []byte) (domain.Observation, error) {
+	report, err := metar.Decode(string(data))
+	if err != nil {
+		return domain.Observation{}, err
+	}
+
+	if report.Nil {
+		return domain.Observation{}, ErrEmptyReport
+	}
+
+	observation := domain.Observation{
+		StationID: report.Station,
+		Timestamp: report.Time(time.Now()),
+	}
+
+	if report.Wind != nil {
+		speed := report.Wind.MetersPerSecond(report.Wind.Speed)
+		observation.WindSpeed = &speed
+
+		if report.Wind.Gust > 0 {
+			gust := report.Wind.MetersPerSecond(report.Wind.Gust)
+			observation.WindGust = &gust
+		}
+
+		if !report.Wind.Variable && !report.Wind.DirectionUnknown && report.Wind.Speed > 0 {
+			direction := float64(report.Wind.Direction)
+			observation.WindDirection = &direction
+		}
+	}
+
+	if report.Temperature != nil {
+		temperature := float64(*report.Temperature)
+		observation.Temperature = &temperature
+
+		if report.DewPoint != nil {
+			humidity := meteo.RelativeHumidity(temperature, float64(*report.DewPoint))
+			observation.Humidity = &humidity
+		}
+	}
+
+	if report.Pressure != nil {
+		pressure := report.Pressure.HPa()
+		observation.Pressure = &pressure
+	}
+
+	return observation, nil
+}
+
+// EncodeMETAR formats observation as METAR report of the station with given ICAO identifier.
+// Wind speed is encoded in knots, dew point is calculated from temperature and humidity
+func EncodeMETAR(icao string, observation domain.Observation) string {
+	ts := observation.Timestamp.UTC()
+	report := metar.Report{
+		Type:    metar.TypeMETAR,
+		Station: icao,
+		Day:     ts.Day(),
+		Hour:    ts.Hour(),
+		Minute:  ts.Minute(),
+		Auto:    true,
+	}
+
+	if observation.WindSpeed != nil {
+		wind := &metar.Wind{Unit: metar.UnitKnots, Speed: int(math.Round(*observation.WindSpeed * knotsInMeterPerSecond))}
+		if observation.WindGust != nil {
+			wind.Gust = int(math.Round(*observation.WindGust * knotsInMeterPerSecond))
+		}
+		if observation.WindDirection != nil {
+			wind.Direction = int(math.Round(*observation.WindDirection/10)*10) % 360
+			if wind.Direction == 0 && wind.Speed > 0 {
+				wind.Direction = 360
+			}
+		} else if wind.Speed > 0 {
+			wind.DirectionUnknown = true
+		}
+
+		report.Wind = wind
+	}
+
+	if observation.Temperature != nil {
+		temperature := int(math.Round(*observation.Temperature))
+		report.Temperature = &temperature
+
+		if observation.Humidity != nil && *observation.Humidity > 0 {
+			dewPoint := int(math.Round(meteo.DewPoint(*observation.Temperature, *observation.Humidity)))
+			report.DewPoint = &dewPoint
+		}
+	}
+
+	if observation.Pressure != nil {
+		report.Pressure = &metar.Pressure{Value: *observation.Pressure, Unit: metar.UnitHectopascals}
+	}
+
+	return metar.Encode(report)
+}
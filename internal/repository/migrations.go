@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/database/mongodb"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/hash"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+	"time"
+)
+
+type indexSpecification struct {
+	Name   string `bson:"name"`
+	Key    bson.D `bson:"key"`
+	Unique bool   `bson:"unique"`
+}
+
+// Migrate fills fields added to documents stored before them and prepares data for indexes whose options changed.
+// It runs before CreateIndexes and is idempotent, so it's safe to run on every start
+func Migrate(ctx context.Context, db *mongo.Database) error {
+	if err := migrateObservationsIndex(ctx, db); err != nil {
+		return err
+	}
+
+	if err := migrateUserSessions(ctx, db); err != nil {
+		return err
+	}
+
+	// session fields of admins used to be snake_case
+	if _, err := db.Collection(adminsCollection).UpdateMany(ctx, bson.M{"session.refresh_token": bson.M{"$exists": true}},
+		bson.M{"$rename": bson.M{"session.refresh_token": "session.refreshToken", "session.expires_at": "session.expiresAt"}}); err != nil {
+		return err
+	}
+
+	if err := migrateAdminPasswords(ctx, db); err != nil {
+		return err
+	}
+
+	stations := db.Collection(stationsCollection)
+
+	// stations stored before geospatial search have no GeoJSON location
+	if _, err := stations.UpdateMany(ctx, bson.M{"location": bson.M{"$exists": false}}, []bson.M{
+		{"$set": bson.M{"location": bson.M{
+			"type":        domain.GeoTypePoint,
+			"coordinates": bson.A{"$longitude", "$latitude"},
+		}}},
+	}); err != nil {
+		return err
+	}
+
+	// and no type, stations with ICAO identifier only are airfields
+	if _, err := stations.UpdateMany(ctx, bson.M{"type": bson.M{"$exists": false}, "wmo": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"type": domain.StationTypeAviation}}); err != nil {
+		return err
+	}
+
+	_, err := stations.UpdateMany(ctx, bson.M{"type": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"type": domain.StationTypeSynoptic}})
+
+	return err
+}
+
+// legacySession is session embedded into user, its fields used to be snake_case
+type legacySession struct {
+	RefreshToken       string    `bson:"refreshToken"`
+	ExpiresAt          time.Time `bson:"expiresAt"`
+	LegacyRefreshToken string    `bson:"refresh_token"`
+	LegacyExpiresAt    time.Time `bson:"expires_at"`
+}
+
+// migrateUserSessions moves sessions embedded into users to own collection, so users stay signed in.
+// Expired sessions are dropped. Sessions are upserted by token, so interrupted migration can be rerun
+func migrateUserSessions(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection(usersCollection)
+	sessions := db.Collection(userSessionsCollection)
+
+	cur, err := users.Find(ctx, bson.M{"session": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"session": 1}))
+	if err != nil {
+		return err
+	}
+
+	defer cur.Close(ctx)
+
+	now := time.Now()
+	for cur.Next(ctx) {
+		var user struct {
+			ID      primitive.ObjectID `bson:"_id"`
+			Session legacySession      `bson:"session"`
+		}
+
+		if err := cur.Decode(&user); err != nil {
+			return err
+		}
+
+		token, expiresAt := user.Session.RefreshToken, user.Session.ExpiresAt
+		if token == "" {
+			token, expiresAt = user.Session.LegacyRefreshToken, user.Session.LegacyExpiresAt
+		}
+
+		if token == "" || !expiresAt.After(now) {
+			continue
+		}
+
+		// hashed like refresh tokens of new sessions
+		sum := sha256.Sum256([]byte(token))
+		session := domain.UserSession{
+			UserID:        user.ID,
+			RefreshToken:  hex.EncodeToString(sum[:]),
+			RotatedTokens: make([]string, 0),
+			CreatedAt:     now,
+			LastUsedAt:    now,
+			ExpiresAt:     expiresAt,
+		}
+
+		if _, err := sessions.UpdateOne(ctx, bson.M{"refreshToken": session.RefreshToken}, bson.M{"$setOnInsert": session},
+			options.Update().SetUpsert(true)); err != nil {
+			return err
+		}
+	}
+
+	if err := cur.Err(); err != nil {
+		return err
+	}
+
+	_, err = users.UpdateMany(ctx, bson.M{"session": bson.M{"$exists": true}}, bson.M{"$unset": bson.M{"session": ""}})
+
+	return err
+}
+
+// migrateAdminPasswords hashes passwords of admins created before hashing was introduced, they were stored
+// in plaintext. Bcrypt of default cost is used as the configured hasher isn't known here,
+// sign in upgrades the hashes to the configured algorithm. Empty passwords are left, no password matches them
+func migrateAdminPasswords(ctx context.Context, db *mongo.Database) error {
+	admins := db.Collection(adminsCollection)
+
+	cur, err := admins.Find(ctx, bson.M{"password": bson.M{
+		"$type": "string",
+		"$ne":   "",
+		"$not":  primitive.Regex{Pattern: `^\$(argon2id|2[aby])\$`},
+	}}, options.Find().SetProjection(bson.M{"password": 1}))
+	if err != nil {
+		return err
+	}
+
+	defer cur.Close(ctx)
+
+	hasher := hash.NewBCryptHasher(bcrypt.DefaultCost)
+	for cur.Next(ctx) {
+		var admin struct {
+			ID       primitive.ObjectID `bson:"_id"`
+			Password string             `bson:"password"`
+		}
+
+		if err := cur.Decode(&admin); err != nil {
+			return err
+		}
+
+		passwordHash, err := hasher.Hash(admin.Password)
+		if err != nil {
+			return err
+		}
+
+		// the password is matched too, so a password changed meanwhile isn't overwritten
+		if _, err := admins.UpdateOne(ctx, bson.M{"_id": admin.ID, "password": admin.Password},
+			bson.M{"$set": bson.M{"password": passwordHash}}); err != nil {
+			return err
+		}
+	}
+
+	return cur.Err()
+}
+
+// migrateObservationsIndex makes index of observations by station and time unique. The index used to be
+// created not unique, so duplicates stored meanwhile are removed keeping the first one, and the old index
+// is dropped to be recreated by CreateIndexes
+func migrateObservationsIndex(ctx context.Context, db *mongo.Database) error {
+	observations := db.Collection(observationsCollection)
+
+	cur, err := observations.Indexes().List(ctx)
+	if err != nil {
+		if mongodb.IsNamespaceNotFound(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	var specs []indexSpecification
+	if err := cur.All(ctx, &specs); err != nil {
+		return err
+	}
+
+	var stale []string
+	for _, spec := range specs {
+		if len(spec.Key) != 2 || spec.Key[0].Key != "stationId" || spec.Key[1].Key != "timestamp" {
+			continue
+		}
+
+		if spec.Unique {
+			return nil
+		}
+
+		stale = append(stale, spec.Name)
+	}
+
+	duplicates, err := observations.Aggregate(ctx, []bson.M{
+		{"$sort": bson.M{"_id": 1}},
+		{"$group": bson.M{
+			"_id":   bson.M{"stationId": "$stationId", "timestamp": "$timestamp"},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}},
+		{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+
+	defer duplicates.Close(ctx)
+
+	for duplicates.Next(ctx) {
+		var group struct {
+			IDs []primitive.ObjectID `bson:"ids"`
+		}
+
+		if err := duplicates.Decode(&group); err != nil {
+			return err
+		}
+
+		if _, err := observations.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": group.IDs[1:]}}); err != nil {
+			return err
+		}
+	}
+
+	if err := duplicates.Err(); err != nil {
+		return err
+	}
+
+	for _, name := range stale {
+		if _, err := observations.Indexes().DropOne(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type ModelRunsRepo struct {
+	db *mongo.Collection
+}
+
+func NewModelRunsRepo(db *mongo.Database) *ModelRunsRepo {
+	return &ModelRunsRepo{
+		db: db.Collection(modelRunsCollection),
+	}
+}
+
+func (r *ModelRunsRepo) Create(ctx context.Context, run domain.ModelRun) (primitive.ObjectID, error) {
+	res, err := r.db.InsertOne(ctx, run)
+	if err != nil {
+		return primitive.ObjectID{}, err
+	}
+
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *ModelRunsRepo) GetByRunTime(ctx context.Context, model string, runTime time.Time) (domain.ModelRun, error) {
+	return r.findOne(ctx, bson.M{"model": model, "runTime": runTime}, nil)
+}
+
+// GetLatest returns the latest run of the model having forecasts valid at the time. Empty model matches any model
+func (r *ModelRunsRepo) GetLatest(ctx context.Context, model string, validAt time.Time) (domain.ModelRun, error) {
+	filter := bson.M{"validFrom": bson.M{"$lte": validAt}, "validTo": bson.M{"$gte": validAt}}
+	if model != "" {
+		filter["model"] = model
+	}
+
+	return r.findOne(ctx, filter, options.FindOne().SetSort(bson.D{{Key: "runTime", Value: -1}}))
+}
+
+// Extend adds loaded file and its parameters to the run and widens its validity
+func (r *ModelRunsRepo) Extend(ctx context.Context, inp ExtendModelRunInput) error {
+	res, err := r.db.UpdateOne(ctx, bson.M{"_id": inp.ID}, bson.M{
+		"$addToSet": bson.M{"parameters": bson.M{"$each": inp.Parameters}, "files": inp.File},
+		"$min":      bson.M{"validFrom": inp.ValidFrom},
+		"$max":      bson.M{"validTo": inp.ValidTo},
+		"$set":      bson.M{"loadedAt": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrModelRunNotFound
+	}
+
+	return nil
+}
+
+func (r *ModelRunsRepo) findOne(ctx context.Context, filter bson.M, opts *options.FindOneOptions) (domain.ModelRun, error) {
+	if opts == nil {
+		opts = options.FindOne()
+	}
+
+	var run domain.ModelRun
+	if err := r.db.FindOne(ctx, filter, opts).Decode(&run); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.ModelRun{}, ErrModelRunNotFound
+		}
+
+		return domain.ModelRun{}, err
+	}
+
+	return run, nil
+}
+
+type ModelFieldsRepo struct {
+	db *mongo.Collection
+}
+
+func NewModelFieldsRepo(db *mongo.Database) *ModelFieldsRepo {
+	return &ModelFieldsRepo{
+		db: db.Collection(modelFieldsCollection),
+	}
+}
+
+// Replace stores rows of the field replacing the previously loaded ones, so that files can be loaded again.
+// All rows must belong to the same field
+func (r *ModelFieldsRepo) Replace(ctx context.Context, rows []domain.ModelFieldRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	field := rows[0]
+	if _, err := r.db.DeleteMany(ctx, bson.M{
+		"runId":           field.RunID,
+		"parameter":       field.Parameter,
+		"validTime":       field.ValidTime,
+		"accumulatedFrom": accumulatedFromFilter(field.AccumulatedFrom),
+	}); err != nil {
+		return err
+	}
+
+	docs := make([]interface{}, len(rows))
+	for i := range rows {
+		docs[i] = rows[i]
+	}
+
+	_, err := r.db.InsertMany(ctx, docs)
+
+	return err
+}
+
+// GetRows returns rows of the run fields valid in [From, To], ordered by valid time
+func (r *ModelFieldsRepo) GetRows(ctx context.Context, query ModelRowsQuery) ([]domain.ModelFieldRow, error) {
+	filter := bson.M{
+		"runId":     query.RunID,
+		"row":       bson.M{"$in": query.Rows},
+		"validTime": bson.M{"$gte": query.From, "$lte": query.To},
+	}
+
+	if len(query.Parameters) > 0 {
+		filter["parameter"] = bson.M{"$in": query.Parameters}
+	}
+
+	cur, err := r.db.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "validTime", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]domain.ModelFieldRow, 0)
+	err = cur.All(ctx, &rows)
+
+	return rows, err
+}
+
+// accumulatedFromFilter matches zero time with missing field as it's omitted on insert
+func accumulatedFromFilter(t time.Time) interface{} {
+	if t.IsZero() {
+		return bson.M{"$exists": false}
+	}
+
+	return t
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type NormalsRepo struct {
+	db *mongo.Collection
+}
+
+func NewNormalsRepo(db *mongo.Database) *NormalsRepo {
+	return &NormalsRepo{
+		db: db.Collection(normalsCollection),
+	}
+}
+
+// ReplaceByStation replaces all normals of the station, so normals of the previous reference period don't stay
+func (r *NormalsRepo) ReplaceByStation(ctx context.Context, stationId string, normals []domain.Normal) error {
+	if _, err := r.db.DeleteMany(ctx, bson.M{"stationId": stationId}); err != nil {
+		return err
+	}
+
+	if len(normals) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, len(normals))
+	for i := range normals {
+		docs[i] = normals[i]
+	}
+
+	_, err := r.db.InsertMany(ctx, docs)
+
+	return err
+}
+
+// Get returns normals of the station ordered by month and day
+func (r *NormalsRepo) Get(ctx context.Context, query NormalsQuery) ([]domain.Normal, error) {
+	filter := bson.M{"stationId": query.StationID, "period": query.Period}
+
+	if len(query.Elements) > 0 {
+		filter["element"] = bson.M{"$in": query.Elements}
+	}
+
+	if query.Month != 0 {
+		filter["month"] = query.Month
+	}
+
+	if query.Day != 0 {
+		filter["day"] = query.Day
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "month", Value: 1}, {Key: "day", Value: 1}, {Key: "element", Value: 1}})
+
+	cur, err := r.db.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	normals := make([]domain.Normal, 0)
+	err = cur.All(ctx, &normals)
+
+	return normals, err
+}
+
+type NormalsJobsRepo struct {
+	db *mongo.Collection
+}
+
+func NewNormalsJobsRepo(db *mongo.Database) *NormalsJobsRepo {
+	return &NormalsJobsRepo{
+		db: db.Collection(normalsJobsCollection),
+	}
+}
+
+func (r *NormalsJobsRepo) Create(ctx context.Context, job domain.NormalsJob) (primitive.ObjectID, error) {
+	res, err := r.db.InsertOne(ctx, job)
+	if err != nil {
+		return primitive.ObjectID{}, err
+	}
+
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *NormalsJobsRepo) GetById(ctx context.Context, id primitive.ObjectID) (domain.NormalsJob, error) {
+	var job domain.NormalsJob
+	if err := r.db.FindOne(ctx, bson.M{"_id": id}).Decode(&job); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.NormalsJob{}, ErrNormalsJobNotFound
+		}
+
+		return domain.NormalsJob{}, err
+	}
+
+	return job, nil
+}
+
+func (r *NormalsJobsRepo) Update(ctx context.Context, inp UpdateNormalsJobInput) error {
+	updateQuery := bson.M{}
+
+	if inp.Status != nil {
+		updateQuery["status"] = *inp.Status
+	}
+
+	if inp.Total != nil {
+		updateQuery["total"] = *inp.Total
+	}
+
+	if inp.Processed != nil {
+		updateQuery["processed"] = *inp.Processed
+	}
+
+	if inp.FailedStations != nil {
+		updateQuery["failedStations"] = inp.FailedStations
+	}
+
+	if inp.Error != nil {
+		updateQuery["error"] = *inp.Error
+	}
+
+	if inp.StartedAt != nil {
+		updateQuery["startedAt"] = *inp.StartedAt
+	}
+
+	if inp.FinishedAt != nil {
+		updateQuery["finishedAt"] = *inp.FinishedAt
+	}
+
+	res, err := r.db.UpdateOne(ctx, bson.M{"_id": inp.ID}, bson.M{"$set": updateQuery})
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrNormalsJobNotFound
+	}
+
+	return nil
+}
+
+// FailUnfinished marks queued and running jobs created before the time as failed,
+// it's used on start when queue of the previous process is lost
+func (r *NormalsJobsRepo) FailUnfinished(ctx context.Context, before time.Time, reason string) error {
+	_, err := r.db.UpdateMany(ctx,
+		bson.M{"status": bson.M{"$in": []string{domain.NormalsJobQueued, domain.NormalsJobRunning}}, "createdAt": bson.M{"$lt": before}},
+		bson.M{"$set": bson.M{"status": domain.NormalsJobFailed, "error": reason, "finishedAt": time.Now()}})
+
+	return err
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ObservationsRepo struct {
+	db *mongo.Collection
+}
+
+func NewObservationsRepo(db *mongo.Database) *ObservationsRepo {
+	return &ObservationsRepo{
+		db: db.Collection(observationsCollection),
+	}
+}
+
+func (r *ObservationsRepo) Create(ctx context.Context, observation domain.Observation) error {
+	_, err := r.db.InsertOne(ctx, observation)
+	if mongodb.IsDuplicate(err) {
+		return ErrObservationAlreadyExists
+	}
+
+	return err
+}
+
+// CreateMany inserts observations skipping the ones already stored for the same station and timestamp.
+// It returns the number of inserted observations
+func (r *ObservationsRepo) CreateMany(ctx context.Context, observations []domain.Observation) (int, error) {
+	if len(observations) == 0 {
+		return 0, nil
+	}
+
+	docs := make([]interface{}, len(observations))
+	for i := range observations {
+		docs[i] = observations[i]
+	}
+
+	res, err := r.db.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return len(res.InsertedIDs), nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return 0, err
+	}
+
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != mongodb.DuplicateKeyCode {
+			return 0, err
+		}
+	}
+
+	return len(observations) - len(bulkErr.WriteErrors), nil
+}
+
+func (r *ObservationsRepo) Find(ctx context.Context, query ObservationsQuery) ([]domain.Observation, error) {
+	cur, err := r.db.Find(ctx, observationsFilter(query), findObservationsOptions(query))
+	if err != nil {
+		return nil, err
+	}
+
+	observations := make([]domain.Observation, 0)
+	err = cur.All(ctx, &observations)
+
+	return observations, err
+}
+
+// Stream calls fn for every observation matching the query without loading all of them into memory
+func (r *ObservationsRepo) Stream(ctx context.Context, query ObservationsQuery, fn func(observation domain.Observation) error) error {
+	cur, err := r.db.Find(ctx, observationsFilter(query), findObservationsOptions(query))
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var observation domain.Observation
+		if err := cur.Decode(&observation); err != nil {
+			return err
+		}
+
+		if err := fn(observation); err != nil {
+			return err
+		}
+	}
+
+	return cur.Err()
+}
+
+func (r *ObservationsRepo) Count(ctx context.Context, query ObservationsQuery) (int64, error) {
+	return r.db.CountDocuments(ctx, observationsFilter(query))
+}
+
+func (r *ObservationsRepo) SetQualityFlag(ctx context.Context, id primitive.ObjectID, field string, flag domain.QualityFlag) error {
+	res, err := r.db.UpdateOne(ctx, bson.M{"_id": id, field: bson.M{"$exists": true}}, bson.M{"$set": bson.M{"qc." + field: flag}})
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrObservationNotFound
+	}
+
+	return nil
+}
+
+func findObservationsOptions(query ObservationsQuery) *options.FindOptions {
+	order := 1
+	if query.Descending {
+		order = -1
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: order}, {Key: "_id", Value: order}}).
+		SetLimit(query.Limit)
+
+	if len(query.Fields) > 0 {
+		projection := bson.M{"stationId": 1, "timestamp": 1, "receivedAt": 1}
+		for _, field := range query.Fields {
+			projection[field] = 1
+			projection["qc."+field] = 1
+		}
+
+		opts.SetProjection(projection)
+	}
+
+	return opts
+}
+
+func observationsFilter(query ObservationsQuery) bson.M {
+	filter := bson.M{}
+
+	if len(query.StationIDs) > 0 {
+		filter["stationId"] = bson.M{"$in": query.StationIDs}
+	}
+
+	timeFilter := bson.M{}
+	if !query.From.IsZero() {
+		timeFilter["$gte"] = query.From
+	}
+
+	if !query.To.IsZero() {
+		timeFilter["$lt"] = query.To
+	}
+
+	if len(timeFilter) > 0 {
+		filter["timestamp"] = timeFilter
+	}
+
+	if !query.ReceivedBefore.IsZero() {
+		filter["receivedAt"] = bson.M{"$lt": query.ReceivedBefore}
+	}
+
+	if query.After != nil {
+		op := "$gt"
+		if query.Descending {
+			op = "$lt"
+		}
+
+		filter["$or"] = []bson.M{
+			{"timestamp": bson.M{op: query.After.Timestamp}},
+			{"timestamp": query.After.Timestamp, "_id": bson.M{op: query.After.ID}},
+		}
+	}
+
+	return filter
+}
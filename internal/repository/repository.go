@@ -5,38 +5,332 @@ import (
 	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"time"
 )
 
 type Users interface {
 	Create(ctx context.Context, user domain.User) error
-	GetByCredentials(ctx context.Context, email, password string) (domain.User, error)
-	GetByRefreshToken(ctx context.Context, refreshToken string) (domain.User, error)
-	SetSession(ctx context.Context, id primitive.ObjectID, session domain.Session) error
+	GetByEmail(ctx context.Context, email string) (domain.User, error)
+	SetPassword(ctx context.Context, id primitive.ObjectID, password string) error
+	SetLastVisit(ctx context.Context, id primitive.ObjectID, at time.Time) error
 	GetById(ctx context.Context, id primitive.ObjectID) (domain.User, error)
 	Verify(ctx context.Context, code string) error
+	SetPreferences(ctx context.Context, id primitive.ObjectID, preferences domain.Preferences) error
+	SetGrants(ctx context.Context, id primitive.ObjectID, grants []domain.Grant) error
+}
+
+// RotateSessionInput replaces RefreshToken of the session with NewRefreshToken, tokens are hashes
+type RotateSessionInput struct {
+	ID              primitive.ObjectID
+	RefreshToken    string
+	NewRefreshToken string
+	UserAgent       string
+	IP              string
+	UsedAt          time.Time
+	ExpiresAt       time.Time
+}
+
+type UserSessions interface {
+	Create(ctx context.Context, session domain.UserSession) error
+	GetByRefreshToken(ctx context.Context, refreshToken string) (domain.UserSession, error)
+	GetByRotatedToken(ctx context.Context, refreshToken string) (domain.UserSession, error)
+	GetByUser(ctx context.Context, userId primitive.ObjectID) ([]domain.UserSession, error)
+	Rotate(ctx context.Context, inp RotateSessionInput) error
+	Delete(ctx context.Context, userId, id primitive.ObjectID) error
+	DeleteByUser(ctx context.Context, userId primitive.ObjectID) error
+}
+
+type UpdateAdminInput struct {
+	ID         primitive.ObjectID
+	Name       *string
+	Email      *string
+	Password   *string
+	SuperAdmin *bool
 }
 
 type Admins interface {
-	GetByCredentials(ctx context.Context, email, password string) (domain.Admin, error)
+	Create(ctx context.Context, admin domain.Admin) (primitive.ObjectID, error)
+	GetAll(ctx context.Context) ([]domain.Admin, error)
+	GetByEmail(ctx context.Context, email string) (domain.Admin, error)
 	GetByRefreshToken(ctx context.Context, refreshToken string) (domain.Admin, error)
 	SetSession(ctx context.Context, id primitive.ObjectID, session domain.Session) error
 	GetById(ctx context.Context, id primitive.ObjectID) (domain.Admin, error)
+	CountSuperAdmins(ctx context.Context) (int64, error)
+	Update(ctx context.Context, inp UpdateAdminInput) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
 }
 
 type Sensors interface {
 	Read() ([]byte, error)
 	Write(data []byte) error
-	Parse(data []byte) error
+	Parse(data []byte) (domain.Observation, error)
+	Close() error
+}
+
+type UpdateStationInput struct {
+	ID        primitive.ObjectID
+	WMO       *string
+	ICAO      *string
+	Name      *string
+	Latitude  *float64
+	Longitude *float64
+	Elevation *float64
+	Type      *string
+	Sensors   []domain.StationSensor
+	Status    *string
+
+	ReportInterval *int64
+}
+
+// StationsNearQuery selects stations ordered by distance to the point.
+// MaxDistance is in meters, zero means unlimited. Empty Types and Statuses match any
+type StationsNearQuery struct {
+	Latitude    float64
+	Longitude   float64
+	MaxDistance float64
+	Types       []string
+	Statuses    []string
+	Limit       int64
+}
+
+// StationsBoxQuery selects stations within the bounding box.
+// MinLongitude greater than MaxLongitude means the box crosses the antimeridian
+type StationsBoxQuery struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+	Types        []string
+	Statuses     []string
+	Limit        int64
+}
+
+type Stations interface {
+	Create(ctx context.Context, station domain.Station) (primitive.ObjectID, error)
+	GetAll(ctx context.Context) ([]domain.Station, error)
+	GetById(ctx context.Context, id primitive.ObjectID) (domain.Station, error)
+	GetByIdentifier(ctx context.Context, identifier string) (domain.Station, error)
+	GetNear(ctx context.Context, query StationsNearQuery) ([]domain.StationDistance, error)
+	GetWithinBox(ctx context.Context, query StationsBoxQuery) ([]domain.Station, error)
+	Update(ctx context.Context, inp UpdateStationInput) error
+	SetHealth(ctx context.Context, id primitive.ObjectID, health domain.StationHealth) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type StationHealthEvents interface {
+	Create(ctx context.Context, event domain.StationHealthEvent) error
+	GetByStation(ctx context.Context, stationId primitive.ObjectID, from, to time.Time) ([]domain.StationHealthEvent, error)
+	GetLatestBefore(ctx context.Context, stationId primitive.ObjectID, before time.Time) (domain.StationHealthEvent, error)
+}
+
+// ObservationsCursor points to the last observation of the previous page
+type ObservationsCursor struct {
+	Timestamp time.Time
+	ID        primitive.ObjectID
+}
+
+// ObservationsQuery filters observations. Zero values aren't used for filtering, zero Limit means no limit
+type ObservationsQuery struct {
+	StationIDs     []string
+	From           time.Time
+	To             time.Time
+	ReceivedBefore time.Time
+	Fields         []string
+	After          *ObservationsCursor
+	Descending     bool
+	Limit          int64
+}
+
+// ObservationsAggregationQuery describes time buckets observations are grouped into.
+// Buckets are either BucketSize long and aligned to Unix epoch or calendar months if Monthly is set.
+// Values flagged bad by quality control are left out if SkipBad is set
+type ObservationsAggregationQuery struct {
+	StationIDs []string
+	From       time.Time
+	To         time.Time
+	Fields     []string
+	BucketSize time.Duration
+	Monthly    bool
+	SkipBad    bool
+}
+
+type Observations interface {
+	Create(ctx context.Context, observation domain.Observation) error
+	CreateMany(ctx context.Context, observations []domain.Observation) (int, error)
+	Find(ctx context.Context, query ObservationsQuery) ([]domain.Observation, error)
+	Stream(ctx context.Context, query ObservationsQuery, fn func(observation domain.Observation) error) error
+	Count(ctx context.Context, query ObservationsQuery) (int64, error)
+	SetQualityFlag(ctx context.Context, id primitive.ObjectID, field string, flag domain.QualityFlag) error
+	Aggregate(ctx context.Context, query ObservationsAggregationQuery) ([]domain.ObservationAggregate, error)
+}
+
+type TerminalForecasts interface {
+	Create(ctx context.Context, forecast domain.TerminalForecast) (primitive.ObjectID, error)
+	GetValidAt(ctx context.Context, stationId string, at time.Time) (domain.TerminalForecast, error)
+}
+
+type UpdateAlertRuleInput struct {
+	ID         primitive.ObjectID
+	UserID     primitive.ObjectID
+	Name       *string
+	Operator   *string
+	Threshold  *float64
+	Hysteresis *float64
+	ForSeconds *int64
+	Enabled    *bool
+}
+
+type AlertRules interface {
+	Create(ctx context.Context, rule domain.AlertRule) (primitive.ObjectID, error)
+	GetByUser(ctx context.Context, userId primitive.ObjectID) ([]domain.AlertRule, error)
+	GetById(ctx context.Context, userId, id primitive.ObjectID) (domain.AlertRule, error)
+	GetEnabledByStation(ctx context.Context, stationId string) ([]domain.AlertRule, error)
+	Update(ctx context.Context, inp UpdateAlertRuleInput) error
+	SetStatus(ctx context.Context, id primitive.ObjectID, status domain.AlertRuleStatus) error
+	Delete(ctx context.Context, userId, id primitive.ObjectID) error
+}
+
+type AlertEvents interface {
+	Create(ctx context.Context, event domain.AlertEvent) error
+	GetByUser(ctx context.Context, userId primitive.ObjectID, limit int64) ([]domain.AlertEvent, error)
+}
+
+type UpdateWebhookInput struct {
+	ID         primitive.ObjectID
+	UserID     primitive.ObjectID
+	URL        *string
+	Events     []string
+	StationIDs []string
+	Enabled    *bool
+}
+
+// WebhooksSubscription selects enabled webhooks subscribed to Event of the station.
+// Nil UserID selects webhooks of all users
+type WebhooksSubscription struct {
+	Event     string
+	StationID string
+	UserID    *primitive.ObjectID
+}
+
+type Webhooks interface {
+	Create(ctx context.Context, webhook domain.Webhook) (primitive.ObjectID, error)
+	GetByUser(ctx context.Context, userId primitive.ObjectID) ([]domain.Webhook, error)
+	GetById(ctx context.Context, userId, id primitive.ObjectID) (domain.Webhook, error)
+	GetSubscribed(ctx context.Context, subscription WebhooksSubscription) ([]domain.Webhook, error)
+	Update(ctx context.Context, inp UpdateWebhookInput) error
+	Delete(ctx context.Context, userId, id primitive.ObjectID) error
+}
+
+type WebhookDeliveries interface {
+	Create(ctx context.Context, delivery domain.WebhookDelivery) error
+	GetByWebhook(ctx context.Context, webhookId primitive.ObjectID, limit int64) ([]domain.WebhookDelivery, error)
+}
+
+type ExtendModelRunInput struct {
+	ID         primitive.ObjectID
+	File       string
+	Parameters []string
+	ValidFrom  time.Time
+	ValidTo    time.Time
+}
+
+type ModelRuns interface {
+	Create(ctx context.Context, run domain.ModelRun) (primitive.ObjectID, error)
+	GetByRunTime(ctx context.Context, model string, runTime time.Time) (domain.ModelRun, error)
+	GetLatest(ctx context.Context, model string, validAt time.Time) (domain.ModelRun, error)
+	Extend(ctx context.Context, inp ExtendModelRunInput) error
+}
+
+// ModelRowsQuery selects Rows of the run fields valid in [From, To]. Empty Parameters selects all of them
+type ModelRowsQuery struct {
+	RunID      primitive.ObjectID
+	Rows       []int
+	Parameters []string
+	From       time.Time
+	To         time.Time
+}
+
+type ModelFields interface {
+	Replace(ctx context.Context, rows []domain.ModelFieldRow) error
+	GetRows(ctx context.Context, query ModelRowsQuery) ([]domain.ModelFieldRow, error)
+}
+
+// NormalsQuery selects normals of the station for the period. Zero Month or Day and empty Elements match any
+type NormalsQuery struct {
+	StationID string
+	Period    string
+	Elements  []string
+	Month     int
+	Day       int
+}
+
+type Normals interface {
+	ReplaceByStation(ctx context.Context, stationId string, normals []domain.Normal) error
+	Get(ctx context.Context, query NormalsQuery) ([]domain.Normal, error)
+}
+
+type UpdateNormalsJobInput struct {
+	ID             primitive.ObjectID
+	Status         *string
+	Total          *int
+	Processed      *int
+	FailedStations []string
+	Error          *string
+	StartedAt      *time.Time
+	FinishedAt     *time.Time
+}
+
+type NormalsJobs interface {
+	Create(ctx context.Context, job domain.NormalsJob) (primitive.ObjectID, error)
+	GetById(ctx context.Context, id primitive.ObjectID) (domain.NormalsJob, error)
+	Update(ctx context.Context, inp UpdateNormalsJobInput) error
+	FailUnfinished(ctx context.Context, before time.Time, reason string) error
+}
+
+type APIKeys interface {
+	Create(ctx context.Context, key domain.APIKey) (primitive.ObjectID, error)
+	GetByOwner(ctx context.Context, owner domain.APIKeyOwner) ([]domain.APIKey, error)
+	GetByHash(ctx context.Context, hash string) (domain.APIKey, error)
+	Delete(ctx context.Context, owner domain.APIKeyOwner, id primitive.ObjectID) error
+	TrackUsage(ctx context.Context, id primitive.ObjectID, ip string, usedAt time.Time) error
 }
 
 type Repositories struct {
-	Users  Users
-	Admins Admins
+	Users               Users
+	UserSessions        UserSessions
+	Admins              Admins
+	Observations        Observations
+	Stations            Stations
+	StationHealthEvents StationHealthEvents
+	TerminalForecasts   TerminalForecasts
+	AlertRules          AlertRules
+	AlertEvents         AlertEvents
+	Webhooks            Webhooks
+	WebhookDeliveries   WebhookDeliveries
+	ModelRuns           ModelRuns
+	ModelFields         ModelFields
+	Normals             Normals
+	NormalsJobs         NormalsJobs
+	APIKeys             APIKeys
 }
 
 func NewRepositories(db *mongo.Database) *Repositories {
 	return &Repositories{
-		Users:  NewUsersRepo(db),
-		Admins: NewAdminsRepo(db),
+		Users:               NewUsersRepo(db),
+		UserSessions:        NewUserSessionsRepo(db),
+		Admins:              NewAdminsRepo(db),
+		Observations:        NewObservationsRepo(db),
+		Stations:            NewStationsRepo(db),
+		StationHealthEvents: NewStationHealthEventsRepo(db),
+		TerminalForecasts:   NewTerminalForecastsRepo(db),
+		AlertRules:          NewAlertRulesRepo(db),
+		AlertEvents:         NewAlertEventsRepo(db),
+		Webhooks:            NewWebhooksRepo(db),
+		WebhookDeliveries:   NewWebhookDeliveriesRepo(db),
+		ModelRuns:           NewModelRunsRepo(db),
+		ModelFields:         NewModelFieldsRepo(db),
+		Normals:             NewNormalsRepo(db),
+		NormalsJobs:         NewNormalsJobsRepo(db),
+		APIKeys:             NewAPIKeysRepo(db),
 	}
 }
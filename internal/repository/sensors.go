@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"fmt"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/sensor"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const FormatKeyValue = "kv"
+
+type SensorsRepo struct {
+	sync.Mutex
+
+	config    sensor.Config
+	format    string
+	stationId string
+
+	source sensor.Source
+	synop  synopParser
+}
+
+// NewSensorsRepo creates sensors repository reading frames of the given format (kv, metar or synop)
+// from the configured source. stationId is used for frames that don't carry station identifier
+func NewSensorsRepo(config sensor.Config, format, stationId string) *SensorsRepo {
+	if format == "" {
+		format = FormatKeyValue
+	}
+
+	if format == FormatSYNOP && config.Delimiter == 0 {
+		config.Delimiter = synopDelimiter
+	}
+
+	return &SensorsRepo{
+		config:    config,
+		format:    format,
+		stationId: stationId,
+	}
+}
+
+// Read returns next raw frame, (re)opening the source if needed.
+// On failure source is closed and will be reopened on the next call
+func (r *SensorsRepo) Read() ([]byte, error) {
+	source, err := r.getSource()
+	if err != nil {
+		return nil, err
+	}
+
+	frame, err := source.ReadFrame()
+	if err != nil {
+		r.reset(source)
+		return nil, err
+	}
+
+	return frame, nil
+}
+
+func (r *SensorsRepo) Write(data []byte) error {
+	source, err := r.getSource()
+	if err != nil {
+		return err
+	}
+
+	if err := source.Write(data); err != nil {
+		if err != sensor.ErrReadOnly {
+			r.reset(source)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *SensorsRepo) Parse(data []byte) (domain.Observation, error) {
+	var (
+		observation domain.Observation
+		err         error
+	)
+
+	switch r.format {
+	case FormatKeyValue:
+		observation, err = parseKeyValue(data)
+	case FormatMETAR:
+		observation, err = parseMETAR(data)
+	case FormatSYNOP:
+		r.Lock()
+		observation, err = r.synop.parse(data)
+		r.Unlock()
+	default:
+		return domain.Observation{}, fmt.Errorf("unknown frame format: %q", r.format)
+	}
+
+	if err != nil {
+		return domain.Observation{}, err
+	}
+
+	if observation.StationID == "" {
+		observation.StationID = r.stationId
+	}
+
+	if observation.StationID == "" {
+		return domain.Observation{}, ErrStationIdMissing
+	}
+
+	observation.ReceivedAt = time.Now().UTC()
+	if observation.Timestamp.IsZero() {
+		observation.Timestamp = observation.ReceivedAt
+	}
+
+	return observation, nil
+}
+
+func (r *SensorsRepo) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.source == nil {
+		return nil
+	}
+
+	err := r.source.Close()
+	r.source = nil
+
+	return err
+}
+
+func (r *SensorsRepo) getSource() (sensor.Source, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.source != nil {
+		return r.source, nil
+	}
+
+	source, err := sensor.Open(r.config)
+	if err != nil {
+		return nil, err
+	}
+
+	r.source = source
+
+	return source, nil
+}
+
+func (r *SensorsRepo) reset(source sensor.Source) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.source == source {
+		r.source.Close()
+		r.source = nil
+	}
+}
+
+// parseKeyValue parses frames like "station=26850 time=2021-05-01T12:00:00Z t=12.3 rh=65 p=1013.2 ws=3.4 wd=270 rr=0"
+func parseKeyValue(data []byte) (domain.Observation, error) {
+	var observation domain.Observation
+
+	fields := strings.FieldsFunc(string(data), func(r rune) bool {
+		return r == ' ' || r == ',' || r == ';' || r == '\t'
+	})
+
+	for _, field := range fields {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return domain.Observation{}, fmt.Errorf("%w: %q", ErrInvalidFrame, field)
+		}
+
+		key, value := strings.ToLower(parts[0]), parts[1]
+
+		switch key {
+		case "station", "id":
+			observation.StationID = value
+			continue
+		case "time", "ts":
+			ts, err := parseFrameTime(value)
+			if err != nil {
+				return domain.Observation{}, fmt.Errorf("%w: %q", ErrInvalidFrame, field)
+			}
+
+			observation.Timestamp = ts
+			continue
+		}
+
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return domain.Observation{}, fmt.Errorf("%w: %q", ErrInvalidFrame, field)
+		}
+
+		switch key {
+		case "t", "temp", "temperature":
+			observation.Temperature = &number
+		case "rh", "humidity":
+			observation.Humidity = &number
+		case "p", "pressure":
+			observation.Pressure = &number
+		case "ps", "station_pressure":
+			observation.StationPressure = &number
+		case "ws", "wind_speed":
+			observation.WindSpeed = &number
+		case "wg", "wind_gust":
+			observation.WindGust = &number
+		case "wd", "wind_dir":
+			observation.WindDirection = &number
+		case "rr", "precipitation":
+			observation.Precipitation = &number
+		}
+	}
+
+	return observation, nil
+}
+
+func parseFrameTime(value string) (time.Time, error) {
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return ts.UTC(), nil
+	}
+
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(unix, 0).UTC(), nil
+}
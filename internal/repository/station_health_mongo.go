@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type StationHealthEventsRepo struct {
+	db *mongo.Collection
+}
+
+func NewStationHealthEventsRepo(db *mongo.Database) *StationHealthEventsRepo {
+	return &StationHealthEventsRepo{
+		db: db.Collection(stationHealthEventsCollection),
+	}
+}
+
+func (r *StationHealthEventsRepo) Create(ctx context.Context, event domain.StationHealthEvent) error {
+	_, err := r.db.InsertOne(ctx, event)
+
+	return err
+}
+
+// GetByStation returns station health events created in [from, to), oldest first
+func (r *StationHealthEventsRepo) GetByStation(ctx context.Context, stationId primitive.ObjectID, from, to time.Time) ([]domain.StationHealthEvent, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+
+	cur, err := r.db.Find(ctx, bson.M{"stationId": stationId, "createdAt": bson.M{"$gte": from, "$lt": to}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.StationHealthEvent, 0)
+	err = cur.All(ctx, &events)
+
+	return events, err
+}
+
+// GetLatestBefore returns the last station health event created before the time, so the state at that time is known
+func (r *StationHealthEventsRepo) GetLatestBefore(ctx context.Context, stationId primitive.ObjectID, before time.Time) (domain.StationHealthEvent, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	var event domain.StationHealthEvent
+	if err := r.db.FindOne(ctx, bson.M{"stationId": stationId, "createdAt": bson.M{"$lt": before}}, opts).Decode(&event); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.StationHealthEvent{}, ErrStationHealthNotFound
+		}
+
+		return domain.StationHealthEvent{}, err
+	}
+
+	return event, nil
+}
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"math"
+	"time"
+)
+
+// bigPolygonCRS allows polygons larger than a hemisphere, ring winding must be counter-clockwise
+const bigPolygonCRS = "urn:x-mongodb:crs:strictwinding:EPSG:4326"
+
+type StationsRepo struct {
+	db *mongo.Collection
+}
+
+func NewStationsRepo(db *mongo.Database) *StationsRepo {
+	return &StationsRepo{
+		db: db.Collection(stationsCollection),
+	}
+}
+
+func (r *StationsRepo) Create(ctx context.Context, station domain.Station) (primitive.ObjectID, error) {
+	res, err := r.db.InsertOne(ctx, station)
+	if err != nil {
+		if mongodb.IsDuplicate(err) {
+			return primitive.ObjectID{}, ErrStationAlreadyExists
+		}
+
+		return primitive.ObjectID{}, err
+	}
+
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *StationsRepo) GetAll(ctx context.Context) ([]domain.Station, error) {
+	cur, err := r.db.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	stations := make([]domain.Station, 0)
+	err = cur.All(ctx, &stations)
+
+	return stations, err
+}
+
+func (r *StationsRepo) GetById(ctx context.Context, id primitive.ObjectID) (domain.Station, error) {
+	var station domain.Station
+	if err := r.db.FindOne(ctx, bson.M{"_id": id}).Decode(&station); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Station{}, ErrStationNotFound
+		}
+
+		return domain.Station{}, err
+	}
+
+	return station, nil
+}
+
+// GetByIdentifier finds station by its WMO or ICAO identifier
+func (r *StationsRepo) GetByIdentifier(ctx context.Context, identifier string) (domain.Station, error) {
+	var station domain.Station
+	if err := r.db.FindOne(ctx, bson.M{"$or": []bson.M{{"wmo": identifier}, {"icao": identifier}}}).Decode(&station); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Station{}, ErrStationNotFound
+		}
+
+		return domain.Station{}, err
+	}
+
+	return station, nil
+}
+
+// GetNear returns stations ordered by distance to the point. Distance is in meters
+func (r *StationsRepo) GetNear(ctx context.Context, query StationsNearQuery) ([]domain.StationDistance, error) {
+	geoNear := bson.M{
+		"near":          domain.NewGeoPoint(query.Latitude, query.Longitude),
+		"distanceField": "distance",
+		"spherical":     true,
+		"query":         stationsFilter(query.Types, query.Statuses),
+	}
+
+	if query.MaxDistance > 0 {
+		geoNear["maxDistance"] = query.MaxDistance
+	}
+
+	pipeline := []bson.M{{"$geoNear": geoNear}}
+	if query.Limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": query.Limit})
+	}
+
+	cur, err := r.db.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	stations := make([]domain.StationDistance, 0)
+	err = cur.All(ctx, &stations)
+
+	return stations, err
+}
+
+// GetWithinBox returns stations within the bounding box sorted by name
+func (r *StationsRepo) GetWithinBox(ctx context.Context, query StationsBoxQuery) ([]domain.Station, error) {
+	filter := stationsFilter(query.Types, query.Statuses)
+
+	width := query.MaxLongitude - query.MinLongitude
+	if width < 0 {
+		width += 360
+	}
+
+	if width >= 360 {
+		// box spans all longitudes, so it is a latitude band which can't be described by a polygon ring
+		filter["latitude"] = bson.M{"$gte": query.MinLatitude, "$lte": query.MaxLatitude}
+	} else {
+		filter["location"] = bson.M{"$geoWithin": bson.M{"$geometry": bson.M{
+			"type":        "Polygon",
+			"coordinates": [][][2]float64{boxRing(query.MinLatitude, query.MinLongitude, query.MaxLatitude, width)},
+			"crs":         bson.M{"type": "name", "properties": bson.M{"name": bigPolygonCRS}},
+		}}}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	if query.Limit > 0 {
+		opts.SetLimit(query.Limit)
+	}
+
+	cur, err := r.db.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	stations := make([]domain.Station, 0)
+	err = cur.All(ctx, &stations)
+
+	return stations, err
+}
+
+func (r *StationsRepo) Update(ctx context.Context, inp UpdateStationInput) error {
+	updateQuery := bson.M{"updatedAt": time.Now()}
+	// empty identifiers are removed rather than stored, unique indexes on them are sparse
+	unsetQuery := bson.M{}
+
+	if inp.WMO != nil && *inp.WMO != "" {
+		updateQuery["wmo"] = *inp.WMO
+	} else if inp.WMO != nil {
+		unsetQuery["wmo"] = ""
+	}
+
+	if inp.ICAO != nil && *inp.ICAO != "" {
+		updateQuery["icao"] = *inp.ICAO
+	} else if inp.ICAO != nil {
+		unsetQuery["icao"] = ""
+	}
+
+	if inp.Name != nil {
+		updateQuery["name"] = *inp.Name
+	}
+
+	if inp.Latitude != nil {
+		updateQuery["latitude"] = *inp.Latitude
+	}
+
+	if inp.Longitude != nil {
+		updateQuery["longitude"] = *inp.Longitude
+	}
+
+	if inp.Latitude != nil && inp.Longitude != nil {
+		updateQuery["location"] = domain.NewGeoPoint(*inp.Latitude, *inp.Longitude)
+	}
+
+	if inp.Elevation != nil {
+		updateQuery["elevation"] = *inp.Elevation
+	}
+
+	if inp.Type != nil {
+		updateQuery["type"] = *inp.Type
+	}
+
+	if inp.Sensors != nil {
+		updateQuery["sensors"] = inp.Sensors
+	}
+
+	if inp.Status != nil {
+		updateQuery["status"] = *inp.Status
+	}
+
+	if inp.ReportInterval != nil {
+		updateQuery["reportInterval"] = *inp.ReportInterval
+	}
+
+	update := bson.M{"$set": updateQuery}
+	if len(unsetQuery) > 0 {
+		update["$unset"] = unsetQuery
+	}
+
+	res, err := r.db.UpdateOne(ctx, bson.M{"_id": inp.ID}, update)
+	if err != nil {
+		if mongodb.IsDuplicate(err) {
+			return ErrStationAlreadyExists
+		}
+
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrStationNotFound
+	}
+
+	return nil
+}
+
+func (r *StationsRepo) SetHealth(ctx context.Context, id primitive.ObjectID, health domain.StationHealth) error {
+	_, err := r.db.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"health": health}})
+
+	return err
+}
+
+func (r *StationsRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.db.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		return ErrStationNotFound
+	}
+
+	return nil
+}
+
+func stationsFilter(types, statuses []string) bson.M {
+	filter := bson.M{}
+
+	if len(types) > 0 {
+		filter["type"] = bson.M{"$in": types}
+	}
+
+	if len(statuses) > 0 {
+		filter["status"] = bson.M{"$in": statuses}
+	}
+
+	return filter
+}
+
+// boxRing returns counter-clockwise ring of the box starting at its south-west corner.
+// Edges along parallels are split into steps of at most one degree, otherwise they'd be
+// treated as great circle arcs bulging towards the pole
+func boxRing(minLat, minLon, maxLat, width float64) [][2]float64 {
+	steps := int(math.Ceil(width))
+	ring := make([][2]float64, 0, 2*steps+3)
+
+	for i := 0; i <= steps; i++ {
+		ring = append(ring, [2]float64{normalizeLongitude(minLon + width*float64(i)/float64(steps)), minLat})
+	}
+
+	for i := steps; i >= 0; i-- {
+		ring = append(ring, [2]float64{normalizeLongitude(minLon + width*float64(i)/float64(steps)), maxLat})
+	}
+
+	return append(ring, ring[0])
+}
+
+func normalizeLongitude(lon float64) float64 {
+	if lon > 180 {
+		return lon - 360
+	}
+
+	return lon
+}
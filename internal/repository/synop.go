@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/meteo"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/synop"
+	"strings"
+	"time"
+)
+
+const (
+	FormatSYNOP = "synop"
+
+	synopDelimiter = '='
+	synopMarker    = "AAXX"
+)
+
+// synopParser decodes SYNOP frames. Bulletins carry "AAXX YYGGiw" header only before the first report,
+// so the last seen header is prepended to the following reports
+type synopParser struct {
+	header string
+}
+
+func (p *synopParser) parse(data []byte) (domain.Observation, error) {
+	groups := strings.Fields(string(data))
+
+	for i, group := range groups {
+		if group == synopMarker && i+1 < len(groups) {
+			p.header = synopMarker + " " + groups[i+1]
+			groups = groups[i+2:]
+
+			break
+		}
+	}
+
+	if p.header == "" {
+		return domain.Observation{}, &synop.ParseError{Group: string(data), Msg: "report without preceding AAXX header"}
+	}
+
+	report, err := synop.Decode(p.header + " " + strings.Join(groups, " "))
+	if err != nil {
+		if err == synop.ErrNil {
+			return domain.Observation{}, ErrEmptyReport
+		}
+
+		return domain.Observation{}, err
+	}
+
+	return synopToObservation(report), nil
+}
+
+// synopToObservation converts SYNOP report into observation. Sea-level pressure isn't reported by high-level
+// stations, humidity is either reported or calculated from temperature and dew point
+func synopToObservation(report synop.Report) domain.Observation {
+	observation := domain.Observation{
+		StationID:       report.Station,
+		Timestamp:       report.Time(time.Now()),
+		Temperature:     report.Temperature,
+		Humidity:        report.Humidity,
+		Pressure:        report.SeaLevelPressure,
+		StationPressure: report.StationPressure,
+		WindSpeed:       report.WindSpeedMetersPerSecond(),
+	}
+
+	if report.Humidity == nil && report.Temperature != nil && report.DewPoint != nil {
+		humidity := meteo.RelativeHumidity(*report.Temperature, *report.DewPoint)
+		observation.Humidity = &humidity
+	}
+
+	if report.WindDirection != nil {
+		direction := float64(*report.WindDirection)
+		observation.WindDirection = &direction
+	}
+
+	if report.Precipitation != nil {
+		observation.Precipitation = &report.Precipitation.Amount
+	}
+
+	return observation
+}
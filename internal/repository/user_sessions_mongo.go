@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// maxRotatedTokens limits how many previous refresh tokens of a session are kept for reuse detection
+const maxRotatedTokens = 50
+
+type UserSessionsRepo struct {
+	db *mongo.Collection
+}
+
+func NewUserSessionsRepo(db *mongo.Database) *UserSessionsRepo {
+	return &UserSessionsRepo{
+		db: db.Collection(userSessionsCollection),
+	}
+}
+
+func (r *UserSessionsRepo) Create(ctx context.Context, session domain.UserSession) error {
+	if session.RotatedTokens == nil {
+		session.RotatedTokens = make([]string, 0)
+	}
+
+	_, err := r.db.InsertOne(ctx, session)
+
+	return err
+}
+
+func (r *UserSessionsRepo) GetByRefreshToken(ctx context.Context, refreshToken string) (domain.UserSession, error) {
+	return r.findOne(ctx, bson.M{"refreshToken": refreshToken, "expiresAt": bson.M{"$gt": time.Now()}})
+}
+
+// GetByRotatedToken returns session the refresh token was rotated out of
+func (r *UserSessionsRepo) GetByRotatedToken(ctx context.Context, refreshToken string) (domain.UserSession, error) {
+	return r.findOne(ctx, bson.M{"rotatedTokens": refreshToken})
+}
+
+func (r *UserSessionsRepo) GetByUser(ctx context.Context, userId primitive.ObjectID) ([]domain.UserSession, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "lastUsedAt", Value: -1}})
+
+	cur, err := r.db.Find(ctx, bson.M{"userId": userId, "expiresAt": bson.M{"$gt": time.Now()}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]domain.UserSession, 0)
+	err = cur.All(ctx, &sessions)
+
+	return sessions, err
+}
+
+// Rotate replaces refresh token of the session only if it's still the current one, so of two concurrent
+// refreshes with the same token just one succeeds
+func (r *UserSessionsRepo) Rotate(ctx context.Context, inp RotateSessionInput) error {
+	res, err := r.db.UpdateOne(ctx, bson.M{"_id": inp.ID, "refreshToken": inp.RefreshToken}, bson.M{
+		"$set": bson.M{
+			"refreshToken": inp.NewRefreshToken,
+			"userAgent":    inp.UserAgent,
+			"ip":           inp.IP,
+			"lastUsedAt":   inp.UsedAt,
+			"expiresAt":    inp.ExpiresAt,
+		},
+		"$push": bson.M{"rotatedTokens": bson.M{"$each": bson.A{inp.RefreshToken}, "$slice": -maxRotatedTokens}},
+	})
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *UserSessionsRepo) Delete(ctx context.Context, userId, id primitive.ObjectID) error {
+	res, err := r.db.DeleteOne(ctx, bson.M{"_id": id, "userId": userId})
+	if err != nil {
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *UserSessionsRepo) DeleteByUser(ctx context.Context, userId primitive.ObjectID) error {
+	_, err := r.db.DeleteMany(ctx, bson.M{"userId": userId})
+
+	return err
+}
+
+func (r *UserSessionsRepo) findOne(ctx context.Context, filter bson.M) (domain.UserSession, error) {
+	var session domain.UserSession
+	if err := r.db.FindOne(ctx, filter).Decode(&session); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.UserSession{}, ErrSessionNotFound
+		}
+
+		return domain.UserSession{}, err
+	}
+
+	return session, nil
+}
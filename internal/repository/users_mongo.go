@@ -29,9 +29,10 @@ func (r *UsersRepo) Create(ctx context.Context, user domain.User) error {
 	return err
 }
 
-func (r *UsersRepo) GetByCredentials(ctx context.Context, email, password string) (domain.User, error) {
+// GetByEmail returns verified user, password is checked by the service against the stored hash
+func (r *UsersRepo) GetByEmail(ctx context.Context, email string) (domain.User, error) {
 	var user domain.User
-	if err := r.db.FindOne(ctx, bson.M{"email": email, "password": password, "verification.verified": true}).
+	if err := r.db.FindOne(ctx, bson.M{"email": email, "verification.verified": true}).
 		Decode(&user); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return domain.User{}, ErrUserNotFound
@@ -43,19 +44,6 @@ func (r *UsersRepo) GetByCredentials(ctx context.Context, email, password string
 	return user, nil
 }
 
-func (r *UsersRepo) GetByRefreshToken(ctx context.Context, refreshToken string) (domain.User, error) {
-	var user domain.User
-	if err := r.db.FindOne(ctx, bson.M{"session.refreshToken": refreshToken, "session.expiresAt": bson.M{"$gt": time.Now()}}).Decode(&user); err != nil {
-		if err == mongo.ErrNoDocuments {
-			return domain.User{}, ErrUserNotFound
-		}
-
-		return domain.User{}, err
-	}
-
-	return user, nil
-}
-
 func (r *UsersRepo) GetById(ctx context.Context, id primitive.ObjectID) (domain.User, error) {
 	var user domain.User
 	err := r.db.FindOne(ctx, bson.M{"_id": id, "verification.verified": true}).Decode(&user)
@@ -70,8 +58,14 @@ func (r *UsersRepo) GetById(ctx context.Context, id primitive.ObjectID) (domain.
 	return user, nil
 }
 
-func (r *UsersRepo) SetSession(ctx context.Context, id primitive.ObjectID, session domain.Session) error {
-	_, err := r.db.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"session": session, "lastVisitAt": time.Now()}})
+func (r *UsersRepo) SetLastVisit(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	_, err := r.db.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"lastVisitAt": at}})
+	return err
+}
+
+func (r *UsersRepo) SetPassword(ctx context.Context, id primitive.ObjectID, password string) error {
+	_, err := r.db.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"password": password}})
+
 	return err
 }
 
@@ -89,3 +83,29 @@ func (r *UsersRepo) Verify(ctx context.Context, code string) error {
 
 	return nil
 }
+
+func (r *UsersRepo) SetPreferences(ctx context.Context, id primitive.ObjectID, preferences domain.Preferences) error {
+	res, err := r.db.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"preferences": preferences}})
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *UsersRepo) SetGrants(ctx context.Context, id primitive.ObjectID, grants []domain.Grant) error {
+	res, err := r.db.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"grants": grants}})
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
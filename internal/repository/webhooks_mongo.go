@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type WebhooksRepo struct {
+	db *mongo.Collection
+}
+
+func NewWebhooksRepo(db *mongo.Database) *WebhooksRepo {
+	return &WebhooksRepo{
+		db: db.Collection(webhooksCollection),
+	}
+}
+
+func (r *WebhooksRepo) Create(ctx context.Context, webhook domain.Webhook) (primitive.ObjectID, error) {
+	res, err := r.db.InsertOne(ctx, webhook)
+	if err != nil {
+		return primitive.ObjectID{}, err
+	}
+
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *WebhooksRepo) GetByUser(ctx context.Context, userId primitive.ObjectID) ([]domain.Webhook, error) {
+	return r.find(ctx, bson.M{"userId": userId})
+}
+
+func (r *WebhooksRepo) GetById(ctx context.Context, userId, id primitive.ObjectID) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	if err := r.db.FindOne(ctx, bson.M{"_id": id, "userId": userId}).Decode(&webhook); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Webhook{}, ErrWebhookNotFound
+		}
+
+		return domain.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhooksRepo) GetSubscribed(ctx context.Context, subscription WebhooksSubscription) ([]domain.Webhook, error) {
+	filter := bson.M{
+		"enabled": true,
+		"events":  subscription.Event,
+		"$or": []bson.M{
+			{"stationIds": bson.M{"$size": 0}},
+			{"stationIds": subscription.StationID},
+		},
+	}
+
+	if subscription.UserID != nil {
+		filter["userId"] = *subscription.UserID
+	}
+
+	return r.find(ctx, filter)
+}
+
+func (r *WebhooksRepo) Update(ctx context.Context, inp UpdateWebhookInput) error {
+	updateQuery := bson.M{"updatedAt": time.Now()}
+
+	if inp.URL != nil {
+		updateQuery["url"] = *inp.URL
+	}
+
+	if inp.Events != nil {
+		updateQuery["events"] = inp.Events
+	}
+
+	if inp.StationIDs != nil {
+		updateQuery["stationIds"] = inp.StationIDs
+	}
+
+	if inp.Enabled != nil {
+		updateQuery["enabled"] = *inp.Enabled
+	}
+
+	res, err := r.db.UpdateOne(ctx, bson.M{"_id": inp.ID, "userId": inp.UserID}, bson.M{"$set": updateQuery})
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhooksRepo) Delete(ctx context.Context, userId, id primitive.ObjectID) error {
+	res, err := r.db.DeleteOne(ctx, bson.M{"_id": id, "userId": userId})
+	if err != nil {
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhooksRepo) find(ctx context.Context, filter bson.M) ([]domain.Webhook, error) {
+	cur, err := r.db.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]domain.Webhook, 0)
+	err = cur.All(ctx, &webhooks)
+
+	return webhooks, err
+}
+
+type WebhookDeliveriesRepo struct {
+	db *mongo.Collection
+}
+
+func NewWebhookDeliveriesRepo(db *mongo.Database) *WebhookDeliveriesRepo {
+	return &WebhookDeliveriesRepo{
+		db: db.Collection(webhookDeliveriesCollection),
+	}
+}
+
+func (r *WebhookDeliveriesRepo) Create(ctx context.Context, delivery domain.WebhookDelivery) error {
+	_, err := r.db.InsertOne(ctx, delivery)
+
+	return err
+}
+
+// GetByWebhook returns the latest delivery attempts of the webhook, newest first
+func (r *WebhookDeliveriesRepo) GetByWebhook(ctx context.Context, webhookId primitive.ObjectID, limit int64) ([]domain.WebhookDelivery, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "attemptedAt", Value: -1}}).SetLimit(limit)
+
+	cur, err := r.db.Find(ctx, bson.M{"webhookId": webhookId}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	err = cur.All(ctx, &deliveries)
+
+	return deliveries, err
+}
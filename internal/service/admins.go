@@ -6,6 +6,7 @@ import (
 	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
 	"gitlab.com/peleng-meteo/meteo-go/pkg/auth"
 	"gitlab.com/peleng-meteo/meteo-go/pkg/hash"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/logger"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"time"
 )
@@ -31,22 +32,163 @@ func NewAdminsService(hasher hash.PasswordHasher, tokenManager auth.TokenManager
 }
 
 func (s *AdminsService) SignIn(ctx context.Context, input SignInInput) (Tokens, error) {
-	// TODO: implement password hashing
-	user, err := s.repo.GetByCredentials(ctx, input.Email, input.Password)
+	admin, err := s.repo.GetByEmail(ctx, input.Email)
+	if err != nil {
+		if err == repository.ErrAdminNotFound {
+			// hashing takes as long as verification, so response time doesn't tell whether the email is registered
+			_, _ = s.hasher.Hash(input.Password)
+			return Tokens{}, ErrAdminNotFound
+		}
+
+		return Tokens{}, err
+	}
+
+	ok, err := s.hasher.Verify(admin.Password, input.Password)
 	if err != nil {
 		return Tokens{}, err
 	}
 
-	return s.createSession(ctx, user.ID)
+	if !ok {
+		return Tokens{}, ErrAdminNotFound
+	}
+
+	if s.hasher.NeedsRehash(admin.Password) {
+		if err := s.setPassword(ctx, admin.ID, input.Password); err != nil {
+			logger.Errorf("failed to rehash password of admin %s: %s", admin.ID.Hex(), err.Error())
+		}
+	}
+
+	return s.createSession(ctx, admin.ID)
 }
 
 func (s *AdminsService) RefreshTokens(ctx context.Context, refreshToken string) (Tokens, error) {
-	student, err := s.repo.GetByRefreshToken(ctx, refreshToken)
+	admin, err := s.repo.GetByRefreshToken(ctx, refreshToken)
 	if err != nil {
+		if err == repository.ErrAdminNotFound {
+			return Tokens{}, ErrAdminNotFound
+		}
+
 		return Tokens{}, err
 	}
 
-	return s.createSession(ctx, student.ID)
+	return s.createSession(ctx, admin.ID)
+}
+
+func (s *AdminsService) Create(ctx context.Context, inp CreateAdminInput) (primitive.ObjectID, error) {
+	passwordHash, err := s.hasher.Hash(inp.Password)
+	if err != nil {
+		return primitive.ObjectID{}, err
+	}
+
+	id, err := s.repo.Create(ctx, domain.Admin{
+		Name:         inp.Name,
+		Email:        inp.Email,
+		Password:     passwordHash,
+		SuperAdmin:   inp.SuperAdmin,
+		RegisteredAt: time.Now().Unix(),
+	})
+	if err != nil {
+		if err == repository.ErrAdminAlreadyExists {
+			return primitive.ObjectID{}, ErrAdminAlreadyExists
+		}
+
+		return primitive.ObjectID{}, err
+	}
+
+	return id, nil
+}
+
+func (s *AdminsService) GetAll(ctx context.Context) ([]domain.Admin, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *AdminsService) GetById(ctx context.Context, id primitive.ObjectID) (domain.Admin, error) {
+	admin, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		if err == repository.ErrAdminNotFound {
+			return domain.Admin{}, ErrAdminNotFound
+		}
+
+		return domain.Admin{}, err
+	}
+
+	return admin, nil
+}
+
+// Update changes admin account. The last super admin can't be demoted, otherwise nobody could manage admins
+func (s *AdminsService) Update(ctx context.Context, inp UpdateAdminInput) error {
+	if inp.SuperAdmin != nil && !*inp.SuperAdmin {
+		if err := s.checkNotLastSuperAdmin(ctx, inp.ID); err != nil {
+			return err
+		}
+	}
+
+	update := repository.UpdateAdminInput{
+		ID:         inp.ID,
+		Name:       inp.Name,
+		Email:      inp.Email,
+		SuperAdmin: inp.SuperAdmin,
+	}
+
+	if inp.Password != nil {
+		passwordHash, err := s.hasher.Hash(*inp.Password)
+		if err != nil {
+			return err
+		}
+
+		update.Password = &passwordHash
+	}
+
+	err := s.repo.Update(ctx, update)
+
+	switch err {
+	case repository.ErrAdminNotFound:
+		return ErrAdminNotFound
+	case repository.ErrAdminAlreadyExists:
+		return ErrAdminAlreadyExists
+	default:
+		return err
+	}
+}
+
+func (s *AdminsService) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if err := s.checkNotLastSuperAdmin(ctx, id); err != nil {
+		return err
+	}
+
+	err := s.repo.Delete(ctx, id)
+	if err == repository.ErrAdminNotFound {
+		return ErrAdminNotFound
+	}
+
+	return err
+}
+
+func (s *AdminsService) checkNotLastSuperAdmin(ctx context.Context, id primitive.ObjectID) error {
+	admin, err := s.GetById(ctx, id)
+	if err != nil || !admin.SuperAdmin {
+		return err
+	}
+
+	count, err := s.repo.CountSuperAdmins(ctx)
+	if err != nil {
+		return err
+	}
+
+	if count <= 1 {
+		return ErrLastSuperAdmin
+	}
+
+	return nil
+}
+
+func (s *AdminsService) setPassword(ctx context.Context, id primitive.ObjectID, password string) error {
+	passwordHash, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, repository.UpdateAdminInput{ID: id, Password: &passwordHash})
 }
 
 func (s *AdminsService) createSession(ctx context.Context, adminId primitive.ObjectID) (Tokens, error) {
@@ -55,7 +197,7 @@ func (s *AdminsService) createSession(ctx context.Context, adminId primitive.Obj
 		err error
 	)
 
-	res.AccessToken, err = s.tokenManager.NewJWT(adminId.Hex(), s.accessTokenTTL)
+	res.AccessToken, err = s.tokenManager.NewJWT(TokenClaims(adminId.Hex(), []domain.Grant{{Role: domain.RoleAdmin}}), s.accessTokenTTL)
 	if err != nil {
 		return res, err
 	}
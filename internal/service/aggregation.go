@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"time"
+)
+
+const (
+	Interval1Minute   = "1m"
+	Interval10Minutes = "10m"
+	Interval1Hour     = "1h"
+	Interval1Day      = "1d"
+	Interval1Month    = "1mo"
+
+	maxAggregationBuckets = 10000
+)
+
+// aggregationIntervals maps interval to bucket size. Months are calendar ones,
+// their size is used only to limit the number of buckets
+var aggregationIntervals = map[string]time.Duration{
+	Interval1Minute:   time.Minute,
+	Interval10Minutes: 10 * time.Minute,
+	Interval1Hour:     time.Hour,
+	Interval1Day:      24 * time.Hour,
+	Interval1Month:    31 * 24 * time.Hour,
+}
+
+// Aggregate returns per-interval statistics of station observations within the requested time range.
+// Values flagged bad by quality control are left out
+func (s *ObservationsService) Aggregate(ctx context.Context, inp AggregateObservationsInput) ([]domain.ObservationAggregate, error) {
+	bucketSize, ok := aggregationIntervals[inp.Interval]
+	if !ok {
+		return nil, ErrInvalidInterval
+	}
+
+	if inp.From.IsZero() || inp.To.IsZero() || !inp.From.Before(inp.To) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	if inp.To.Sub(inp.From)/bucketSize > maxAggregationBuckets {
+		return nil, ErrTooManyBuckets
+	}
+
+	if len(inp.Fields) == 0 {
+		inp.Fields = domain.ObservationFields
+	}
+
+	for _, field := range inp.Fields {
+		if !domain.IsObservationField(field) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownObservationField, field)
+		}
+	}
+
+	return s.repo.Aggregate(ctx, repository.ObservationsAggregationQuery{
+		StationIDs: inp.StationIDs,
+		From:       inp.From,
+		To:         inp.To,
+		Fields:     inp.Fields,
+		BucketSize: bucketSize,
+		Monthly:    inp.Interval == Interval1Month,
+		SkipBad:    true,
+	})
+}
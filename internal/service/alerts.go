@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type AlertsService struct {
+	rulesRepo  repository.AlertRules
+	eventsRepo repository.AlertEvents
+	usersRepo  repository.Users
+	emails     Emails
+	webhooks   Webhooks
+
+	notifications chan alertNotification
+}
+
+type alertNotification struct {
+	rule  domain.AlertRule
+	event domain.AlertEvent
+}
+
+func NewAlertsService(rulesRepo repository.AlertRules, eventsRepo repository.AlertEvents, usersRepo repository.Users,
+	emails Emails, webhooks Webhooks, queueSize int) *AlertsService {
+	return &AlertsService{
+		rulesRepo:     rulesRepo,
+		eventsRepo:    eventsRepo,
+		usersRepo:     usersRepo,
+		emails:        emails,
+		webhooks:      webhooks,
+		notifications: make(chan alertNotification, queueSize),
+	}
+}
+
+func (s *AlertsService) Create(ctx context.Context, inp CreateAlertRuleInput) (primitive.ObjectID, error) {
+	if !domain.IsObservationField(inp.Field) {
+		return primitive.ObjectID{}, fmt.Errorf("%w: %s", ErrUnknownObservationField, inp.Field)
+	}
+
+	if !domain.IsAlertOperator(inp.Operator) {
+		return primitive.ObjectID{}, ErrInvalidAlertOperator
+	}
+
+	if inp.Hysteresis < 0 || inp.ForSeconds < 0 {
+		return primitive.ObjectID{}, ErrInvalidAlertRule
+	}
+
+	return s.rulesRepo.Create(ctx, domain.AlertRule{
+		UserID:     inp.UserID,
+		Name:       inp.Name,
+		StationID:  inp.StationID,
+		Field:      inp.Field,
+		Operator:   inp.Operator,
+		Threshold:  inp.Threshold,
+		Hysteresis: inp.Hysteresis,
+		ForSeconds: inp.ForSeconds,
+		Enabled:    true,
+		Status: domain.AlertRuleStatus{
+			State:     domain.AlertStateInactive,
+			ChangedAt: time.Now(),
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+}
+
+func (s *AlertsService) GetByUser(ctx context.Context, userId primitive.ObjectID) ([]domain.AlertRule, error) {
+	return s.rulesRepo.GetByUser(ctx, userId)
+}
+
+func (s *AlertsService) GetById(ctx context.Context, userId, id primitive.ObjectID) (domain.AlertRule, error) {
+	rule, err := s.rulesRepo.GetById(ctx, userId, id)
+	if err != nil {
+		if err == repository.ErrAlertRuleNotFound {
+			return domain.AlertRule{}, ErrAlertRuleNotFound
+		}
+
+		return domain.AlertRule{}, err
+	}
+
+	return rule, nil
+}
+
+func (s *AlertsService) Update(ctx context.Context, inp UpdateAlertRuleInput) error {
+	if inp.Operator != nil && !domain.IsAlertOperator(*inp.Operator) {
+		return ErrInvalidAlertOperator
+	}
+
+	if (inp.Hysteresis != nil && *inp.Hysteresis < 0) || (inp.ForSeconds != nil && *inp.ForSeconds < 0) {
+		return ErrInvalidAlertRule
+	}
+
+	err := s.rulesRepo.Update(ctx, repository.UpdateAlertRuleInput{
+		ID:         inp.ID,
+		UserID:     inp.UserID,
+		Name:       inp.Name,
+		Operator:   inp.Operator,
+		Threshold:  inp.Threshold,
+		Hysteresis: inp.Hysteresis,
+		ForSeconds: inp.ForSeconds,
+		Enabled:    inp.Enabled,
+	})
+	if err == repository.ErrAlertRuleNotFound {
+		return ErrAlertRuleNotFound
+	}
+
+	return err
+}
+
+func (s *AlertsService) Delete(ctx context.Context, userId, id primitive.ObjectID) error {
+	err := s.rulesRepo.Delete(ctx, userId, id)
+	if err == repository.ErrAlertRuleNotFound {
+		return ErrAlertRuleNotFound
+	}
+
+	return err
+}
+
+func (s *AlertsService) GetEvents(ctx context.Context, userId primitive.ObjectID, limit int64) ([]domain.AlertEvent, error) {
+	return s.eventsRepo.GetByUser(ctx, userId, limit)
+}
+
+// Evaluate moves enabled rules of the observation station through their states.
+// Observations older than the last evaluated one are ignored, so late reports can't flip the state back
+func (s *AlertsService) Evaluate(ctx context.Context, observation domain.Observation) error {
+	rules, err := s.rulesRepo.GetEnabledByStation(ctx, observation.StationID)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		value := observation.Value(rule.Field)
+		if value == nil || !observation.Timestamp.After(rule.Status.LastTimestamp) {
+			continue
+		}
+
+		// values failed range check would fire and resolve rules spuriously
+		if observation.Quality(rule.Field) == domain.QualityBad {
+			continue
+		}
+
+		status := nextAlertRuleStatus(rule, *value, observation.Timestamp)
+		if err := s.rulesRepo.SetStatus(ctx, rule.ID, status); err != nil {
+			return err
+		}
+
+		if status.State == rule.Status.State || status.State == domain.AlertStatePending || status.State == domain.AlertStateInactive {
+			continue
+		}
+
+		if err := s.fire(ctx, rule, domain.AlertEvent{
+			RuleID:    rule.ID,
+			UserID:    rule.UserID,
+			RuleName:  rule.Name,
+			StationID: rule.StationID,
+			Field:     rule.Field,
+			State:     status.State,
+			Value:     *value,
+			Threshold: rule.Threshold,
+			Timestamp: observation.Timestamp,
+			CreatedAt: time.Now(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fire records alert event and notifies rule owner by webhooks and email. Both are queued, so that a slow
+// mail server doesn't hold up ingestion, notification failures are only logged
+func (s *AlertsService) fire(ctx context.Context, rule domain.AlertRule, event domain.AlertEvent) error {
+	if err := s.eventsRepo.Create(ctx, event); err != nil {
+		return err
+	}
+
+	webhookEvent := domain.WebhookEventAlertFired
+	if event.State == domain.AlertStateResolved {
+		webhookEvent = domain.WebhookEventAlertResolved
+	}
+
+	if err := s.webhooks.Dispatch(ctx, WebhookEvent{
+		Type:      webhookEvent,
+		StationID: event.StationID,
+		UserID:    &rule.UserID,
+		Data:      event,
+	}); err != nil {
+		logger.Errorf("failed to dispatch alert rule %s webhooks: %s", rule.ID.Hex(), err.Error())
+	}
+
+	select {
+	case s.notifications <- alertNotification{rule: rule, event: event}:
+	default:
+		logger.Errorf("alert notifications queue is full, email for alert rule %s dropped", rule.ID.Hex())
+	}
+
+	return nil
+}
+
+// Run emails queued alert notifications until ctx is cancelled
+func (s *AlertsService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-s.notifications:
+			s.notify(ctx, n)
+		}
+	}
+}
+
+func (s *AlertsService) notify(ctx context.Context, n alertNotification) {
+	user, err := s.usersRepo.GetById(ctx, n.rule.UserID)
+	if err != nil {
+		logger.Errorf("failed to get owner of alert rule %s: %s", n.rule.ID.Hex(), err.Error())
+		return
+	}
+
+	if err := s.emails.SendAlertEmail(SendAlertEmailInput{
+		Email: user.Email,
+		Name:  user.Name,
+		Rule:  n.rule,
+		Event: n.event,
+	}); err != nil {
+		logger.Errorf("failed to send alert email to %s: %s", user.Email, err.Error())
+	}
+}
+
+// nextAlertRuleStatus applies observed value to the rule status.
+// Condition has to hold for rule For duration before the rule fires,
+// firing rule resolves only when the value clears the threshold with hysteresis
+func nextAlertRuleStatus(rule domain.AlertRule, value float64, ts time.Time) domain.AlertRuleStatus {
+	status := rule.Status
+	status.LastValue = &value
+	status.LastTimestamp = ts
+
+	next := status.State
+	switch status.State {
+	case domain.AlertStateFiring:
+		if rule.Clears(value) {
+			next = domain.AlertStateResolved
+		}
+	case domain.AlertStatePending:
+		if !rule.Matches(value) {
+			next = domain.AlertStateInactive
+		} else if ts.Sub(*status.PendingSince) >= rule.For() {
+			next = domain.AlertStateFiring
+		}
+	default:
+		if rule.Matches(value) {
+			next = domain.AlertStatePending
+			status.PendingSince = &ts
+
+			if rule.For() == 0 {
+				next = domain.AlertStateFiring
+			}
+		}
+	}
+
+	if next != status.State {
+		status.State = next
+		status.ChangedAt = time.Now()
+
+		if next != domain.AlertStatePending {
+			status.PendingSince = nil
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyTag          = "mk_"
+	apiKeyLength       = 32
+	apiKeyPrefixLength = len(apiKeyTag) + 8
+)
+
+type APIKeysService struct {
+	repo       repository.APIKeys
+	usersRepo  repository.Users
+	adminsRepo repository.Admins
+}
+
+func NewAPIKeysService(repo repository.APIKeys, usersRepo repository.Users, adminsRepo repository.Admins) *APIKeysService {
+	return &APIKeysService{
+		repo:       repo,
+		usersRepo:  usersRepo,
+		adminsRepo: adminsRepo,
+	}
+}
+
+// Create stores hash of a new key and returns the key, it can't be recovered later
+func (s *APIKeysService) Create(ctx context.Context, inp CreateAPIKeyInput) (domain.APIKey, string, error) {
+	if err := validateAPIKey(inp); err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	if _, err := s.ownerGrants(ctx, inp.Owner); err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	secret, err := generateAPIKey()
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	if inp.AllowedIPs == nil {
+		inp.AllowedIPs = make([]string, 0)
+	}
+
+	key := domain.APIKey{
+		Owner:      inp.Owner,
+		Name:       inp.Name,
+		Prefix:     secret[:apiKeyPrefixLength],
+		Hash:       hashToken(secret),
+		Scopes:     inp.Scopes,
+		AllowedIPs: inp.AllowedIPs,
+		ExpiresAt:  inp.ExpiresAt,
+		CreatedAt:  time.Now(),
+	}
+
+	key.ID, err = s.repo.Create(ctx, key)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	return key, secret, nil
+}
+
+func (s *APIKeysService) GetByOwner(ctx context.Context, owner domain.APIKeyOwner) ([]domain.APIKey, error) {
+	return s.repo.GetByOwner(ctx, owner)
+}
+
+func (s *APIKeysService) Delete(ctx context.Context, owner domain.APIKeyOwner, id primitive.ObjectID) error {
+	if err := s.repo.Delete(ctx, owner, id); err != nil {
+		if err == repository.ErrAPIKeyNotFound {
+			return ErrAPIKeyNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Authenticate resolves the key used from ip. Grants of the owner are looked up on every request,
+// so revoked roles and removed owners take effect immediately
+func (s *APIKeysService) Authenticate(ctx context.Context, secret, ip string) (APIKeyIdentity, error) {
+	key, err := s.repo.GetByHash(ctx, hashToken(secret))
+	if err != nil {
+		if err == repository.ErrAPIKeyNotFound {
+			return APIKeyIdentity{}, ErrInvalidAPIKey
+		}
+
+		return APIKeyIdentity{}, err
+	}
+
+	now := time.Now()
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return APIKeyIdentity{}, ErrInvalidAPIKey
+	}
+
+	if !ipAllowed(key.AllowedIPs, ip) {
+		return APIKeyIdentity{}, ErrAPIKeyIPNotAllowed
+	}
+
+	grants, err := s.ownerGrants(ctx, key.Owner)
+	if err != nil {
+		if err == ErrUserNotFound || err == ErrAdminNotFound {
+			return APIKeyIdentity{}, ErrInvalidAPIKey
+		}
+
+		return APIKeyIdentity{}, err
+	}
+
+	if err := s.repo.TrackUsage(ctx, key.ID, ip, now); err != nil {
+		logger.Errorf("failed to track usage of api key %s: %s", key.ID.Hex(), err.Error())
+	}
+
+	return APIKeyIdentity{Key: key, Grants: grants}, nil
+}
+
+func (s *APIKeysService) ownerGrants(ctx context.Context, owner domain.APIKeyOwner) ([]domain.Grant, error) {
+	switch owner.Type {
+	case domain.APIKeyOwnerUser:
+		user, err := s.usersRepo.GetById(ctx, owner.ID)
+		if err != nil {
+			if err == repository.ErrUserNotFound {
+				return nil, ErrUserNotFound
+			}
+
+			return nil, err
+		}
+
+		return userGrants(user), nil
+	case domain.APIKeyOwnerAdmin:
+		if _, err := s.adminsRepo.GetById(ctx, owner.ID); err != nil {
+			if err == repository.ErrAdminNotFound {
+				return nil, ErrAdminNotFound
+			}
+
+			return nil, err
+		}
+
+		return []domain.Grant{{Role: domain.RoleAdmin}}, nil
+	default:
+		return nil, ErrInvalidAPIKey
+	}
+}
+
+func validateAPIKey(inp CreateAPIKeyInput) error {
+	if len(inp.Scopes) == 0 {
+		return ErrUnknownAPIKeyScope
+	}
+
+	for _, scope := range inp.Scopes {
+		if !domain.IsAPIKeyScope(scope) {
+			return ErrUnknownAPIKeyScope
+		}
+	}
+
+	for _, allowed := range inp.AllowedIPs {
+		if !isIPOrCIDR(allowed) {
+			return ErrInvalidAllowedIP
+		}
+	}
+
+	if inp.ExpiresAt != nil && !inp.ExpiresAt.After(time.Now()) {
+		return ErrInvalidAPIKeyExpiry
+	}
+
+	return nil
+}
+
+func isIPOrCIDR(s string) bool {
+	if strings.Contains(s, "/") {
+		_, _, err := net.ParseCIDR(s)
+		return err == nil
+	}
+
+	return net.ParseIP(s) != nil
+}
+
+func ipAllowed(allowed []string, ip string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	for _, a := range allowed {
+		if _, network, err := net.ParseCIDR(a); err == nil {
+			if network.Contains(addr) {
+				return true
+			}
+
+			continue
+		}
+
+		if allowedAddr := net.ParseIP(a); allowedAddr != nil && allowedAddr.Equal(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func generateAPIKey() (string, error) {
+	b := make([]byte, apiKeyLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return apiKeyTag + hex.EncodeToString(b), nil
+}
+
+// hashToken hashes API keys and refresh tokens. They are long random strings, so unlike passwords
+// they need neither salt nor slow hashing
+func hashToken(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
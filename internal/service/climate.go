@@ -0,0 +1,659 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gitlab.com/peleng-meteo/meteo-go/internal/config"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	normalsQueueSize = 16
+	maxAnomalyDays   = 3660
+	minReferenceYear = 1850
+
+	// percentileStep is step between percentiles stored with normals
+	percentileStep = 5
+	// leapYear is used to index calendar days, so February 29 has its own normal
+	leapYear = 2000
+)
+
+// normalSource describes how daily value of the element is taken from daily statistics of observation field
+type normalSource struct {
+	field string
+	value func(stats domain.FieldStats) *float64
+}
+
+var normalSources = map[string]normalSource{
+	domain.FieldTemperature:     {domain.FieldTemperature, statsMean},
+	domain.NormalTemperatureMin: {domain.FieldTemperature, statsMin},
+	domain.NormalTemperatureMax: {domain.FieldTemperature, statsMax},
+	domain.FieldHumidity:        {domain.FieldHumidity, statsMean},
+	domain.FieldPressure:        {domain.FieldPressure, statsMean},
+	domain.FieldWindSpeed:       {domain.FieldWindSpeed, statsMean},
+	domain.FieldWindGust:        {domain.FieldWindGust, statsMax},
+	domain.FieldPrecipitation:   {domain.FieldPrecipitation, statsSum},
+}
+
+// normalFields lists observation fields normal elements are computed from
+var normalFields = []string{
+	domain.FieldTemperature,
+	domain.FieldHumidity,
+	domain.FieldPressure,
+	domain.FieldWindSpeed,
+	domain.FieldWindGust,
+	domain.FieldPrecipitation,
+}
+
+func statsMean(stats domain.FieldStats) *float64 { return stats.Mean }
+func statsMin(stats domain.FieldStats) *float64  { return stats.Min }
+func statsMax(stats domain.FieldStats) *float64  { return stats.Max }
+func statsSum(stats domain.FieldStats) *float64  { return stats.Sum }
+
+// dayValues holds daily values of normal elements observed in the UTC day starting at date
+type dayValues struct {
+	date   time.Time
+	count  int64
+	values map[string]float64
+}
+
+// monthValues holds monthly values of normal elements, precipitation is monthly sum, others are means of daily values
+type monthValues struct {
+	start  time.Time
+	values map[string]float64
+}
+
+type ClimateService struct {
+	normalsRepo      repository.Normals
+	jobsRepo         repository.NormalsJobs
+	observationsRepo repository.Observations
+	stationsRepo     repository.Stations
+
+	queue     chan primitive.ObjectID
+	createdAt time.Time
+	config    config.ClimateConfig
+}
+
+func NewClimateService(normalsRepo repository.Normals, jobsRepo repository.NormalsJobs, observationsRepo repository.Observations,
+	stationsRepo repository.Stations, config config.ClimateConfig) *ClimateService {
+	return &ClimateService{
+		normalsRepo:      normalsRepo,
+		jobsRepo:         jobsRepo,
+		observationsRepo: observationsRepo,
+		stationsRepo:     stationsRepo,
+		queue:            make(chan primitive.ObjectID, normalsQueueSize),
+		createdAt:        time.Now(),
+		config:           config,
+	}
+}
+
+// Run processes queued normals jobs one by one until ctx is cancelled.
+// Jobs left unfinished by the previous process are marked as failed first
+func (s *ClimateService) Run(ctx context.Context) {
+	if err := s.jobsRepo.FailUnfinished(ctx, s.createdAt, "interrupted by restart"); err != nil {
+		logger.Errorf("failed to fail unfinished normals jobs: %s", err.Error())
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.runJob(ctx, id)
+		}
+	}
+}
+
+// Recompute queues recomputation of normals and returns id of the job to follow its progress
+func (s *ClimateService) Recompute(ctx context.Context, inp RecomputeNormalsInput) (primitive.ObjectID, error) {
+	now := time.Now().UTC()
+
+	if inp.ToYear == 0 {
+		inp.ToYear = now.Year() - 1
+	}
+
+	if inp.FromYear == 0 {
+		inp.FromYear = inp.ToYear - s.config.Years + 1
+	}
+
+	if inp.FromYear < minReferenceYear || inp.FromYear > inp.ToYear || inp.ToYear > now.Year() {
+		return primitive.ObjectID{}, ErrInvalidReferencePeriod
+	}
+
+	for _, stationId := range inp.StationIDs {
+		if _, err := s.stationsRepo.GetByIdentifier(ctx, stationId); err != nil {
+			if err == repository.ErrStationNotFound {
+				return primitive.ObjectID{}, ErrStationNotFound
+			}
+
+			return primitive.ObjectID{}, err
+		}
+	}
+
+	if len(s.queue) == cap(s.queue) {
+		return primitive.ObjectID{}, ErrNormalsQueueFull
+	}
+
+	id, err := s.jobsRepo.Create(ctx, domain.NormalsJob{
+		Status:     domain.NormalsJobQueued,
+		StationIDs: inp.StationIDs,
+		FromYear:   inp.FromYear,
+		ToYear:     inp.ToYear,
+		CreatedAt:  now,
+	})
+	if err != nil {
+		return primitive.ObjectID{}, err
+	}
+
+	select {
+	case s.queue <- id:
+		return id, nil
+	default:
+		s.finishJob(id, domain.NormalsJobFailed, ErrNormalsQueueFull.Error())
+		return primitive.ObjectID{}, ErrNormalsQueueFull
+	}
+}
+
+func (s *ClimateService) GetJob(ctx context.Context, id primitive.ObjectID) (domain.NormalsJob, error) {
+	job, err := s.jobsRepo.GetById(ctx, id)
+	if err != nil {
+		if err == repository.ErrNormalsJobNotFound {
+			return domain.NormalsJob{}, ErrNormalsJobNotFound
+		}
+
+		return domain.NormalsJob{}, err
+	}
+
+	return job, nil
+}
+
+func (s *ClimateService) Normals(ctx context.Context, inp NormalsInput) ([]domain.Normal, error) {
+	station, err := s.normalsStation(ctx, inp.StationID, inp.Period, inp.Elements)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.normalsRepo.Get(ctx, repository.NormalsQuery{
+		StationID: station.Identifier(),
+		Period:    inp.Period,
+		Elements:  inp.Elements,
+		Month:     inp.Month,
+		Day:       inp.Day,
+	})
+}
+
+// Anomalies compares values observed in every day or month of the time range with normals. Partial days
+// and months are compared as they are, e.g. today's precipitation so far with normal of the whole day
+func (s *ClimateService) Anomalies(ctx context.Context, inp AnomaliesInput) ([]Anomaly, error) {
+	if inp.Period == "" {
+		inp.Period = domain.NormalPeriodDaily
+	}
+
+	station, err := s.normalsStation(ctx, inp.StationID, inp.Period, inp.Elements)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if inp.To.IsZero() {
+		inp.To = now
+	}
+
+	if inp.From.IsZero() {
+		inp.From = now
+	}
+
+	// time range is extended to the start of the first day or month
+	from := inp.From.UTC()
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	if inp.Period == domain.NormalPeriodMonthly {
+		from = from.AddDate(0, 0, 1-from.Day())
+	}
+
+	if !from.Before(inp.To) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	if inp.To.Sub(from) > maxAnomalyDays*24*time.Hour {
+		return nil, ErrTooLongTimeRange
+	}
+
+	normals, err := s.normalsRepo.Get(ctx, repository.NormalsQuery{
+		StationID: station.Identifier(),
+		Period:    inp.Period,
+		Elements:  inp.Elements,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[[2]int][]domain.Normal)
+	for _, normal := range normals {
+		key := [2]int{normal.Month, normal.Day}
+		byDate[key] = append(byDate[key], normal)
+	}
+
+	days, err := s.dailyValues(ctx, station, from, inp.To, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies := make([]Anomaly, 0)
+	compare := func(start time.Time, values map[string]float64, key [2]int) {
+		anomaly := Anomaly{Start: start, Elements: make(map[string]ElementAnomaly)}
+		for _, normal := range byDate[key] {
+			if observed, ok := values[normal.Element]; ok {
+				anomaly.Elements[normal.Element] = ElementAnomaly{
+					Observed:   observed,
+					Normal:     normal.Mean,
+					Anomaly:    observed - normal.Mean,
+					Percentile: percentileRank(normal.Percentiles, observed),
+				}
+			}
+		}
+
+		if len(anomaly.Elements) > 0 {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	if inp.Period == domain.NormalPeriodMonthly {
+		for _, month := range monthlyValues(days, 0) {
+			compare(month.start, month.values, [2]int{int(month.start.Month()), 0})
+		}
+	} else {
+		for _, day := range days {
+			compare(day.date, day.values, [2]int{int(day.date.Month()), day.date.Day()})
+		}
+	}
+
+	return anomalies, nil
+}
+
+// normalsStation validates normals query and returns the station
+func (s *ClimateService) normalsStation(ctx context.Context, stationId, period string, elements []string) (domain.Station, error) {
+	if period != domain.NormalPeriodDaily && period != domain.NormalPeriodMonthly {
+		return domain.Station{}, ErrInvalidNormalsPeriod
+	}
+
+	for _, element := range elements {
+		if !domain.IsNormalElement(element) {
+			return domain.Station{}, fmt.Errorf("%w: %s", ErrUnknownNormalElement, element)
+		}
+	}
+
+	station, err := s.stationsRepo.GetByIdentifier(ctx, stationId)
+	if err != nil {
+		if err == repository.ErrStationNotFound {
+			return domain.Station{}, ErrStationNotFound
+		}
+
+		return domain.Station{}, err
+	}
+
+	return station, nil
+}
+
+func (s *ClimateService) runJob(ctx context.Context, id primitive.ObjectID) {
+	job, err := s.jobsRepo.GetById(ctx, id)
+	if err != nil {
+		logger.Errorf("failed to get normals job %s: %s", id.Hex(), err.Error())
+		return
+	}
+
+	stations, err := s.jobStations(ctx, job)
+	if err != nil {
+		s.finishJob(id, domain.NormalsJobFailed, err.Error())
+		return
+	}
+
+	status, total, startedAt := domain.NormalsJobRunning, len(stations), time.Now()
+	if err := s.jobsRepo.Update(ctx, repository.UpdateNormalsJobInput{ID: id, Status: &status, Total: &total, StartedAt: &startedAt}); err != nil {
+		logger.Errorf("failed to start normals job %s: %s", id.Hex(), err.Error())
+	}
+
+	failed := make([]string, 0)
+	for i, station := range stations {
+		if err := s.recomputeStation(ctx, station, job.FromYear, job.ToYear); err != nil {
+			if ctx.Err() != nil {
+				s.finishJob(id, domain.NormalsJobFailed, "interrupted by shutdown")
+				return
+			}
+
+			logger.Errorf("failed to compute normals of station %s: %s", station.Identifier(), err.Error())
+			failed = append(failed, station.Identifier())
+		}
+
+		processed := i + 1
+		if err := s.jobsRepo.Update(ctx, repository.UpdateNormalsJobInput{ID: id, Processed: &processed, FailedStations: failed}); err != nil {
+			logger.Errorf("failed to update normals job %s: %s", id.Hex(), err.Error())
+		}
+	}
+
+	s.finishJob(id, domain.NormalsJobDone, "")
+}
+
+func (s *ClimateService) jobStations(ctx context.Context, job domain.NormalsJob) ([]domain.Station, error) {
+	if len(job.StationIDs) == 0 {
+		return s.stationsRepo.GetAll(ctx)
+	}
+
+	stations := make([]domain.Station, 0, len(job.StationIDs))
+	for _, stationId := range job.StationIDs {
+		station, err := s.stationsRepo.GetByIdentifier(ctx, stationId)
+		if err != nil {
+			return nil, err
+		}
+
+		stations = append(stations, station)
+	}
+
+	return stations, nil
+}
+
+// finishJob doesn't use job context, so the result is saved on shutdown too
+func (s *ClimateService) finishJob(id primitive.ObjectID, status, reason string) {
+	finishedAt := time.Now()
+
+	inp := repository.UpdateNormalsJobInput{ID: id, Status: &status, FinishedAt: &finishedAt}
+	if reason != "" {
+		inp.Error = &reason
+	}
+
+	if err := s.jobsRepo.Update(context.Background(), inp); err != nil {
+		logger.Errorf("failed to finish normals job %s: %s", id.Hex(), err.Error())
+	}
+}
+
+func (s *ClimateService) recomputeStation(ctx context.Context, station domain.Station, fromYear, toYear int) error {
+	days, err := s.dailyValues(ctx, station,
+		time.Date(fromYear, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(toYear+1, time.January, 1, 0, 0, 0, 0, time.UTC),
+		s.config.MinDailyObservations)
+	if err != nil {
+		return err
+	}
+
+	normals := append(dailyNormals(days, s.config.DailyWindow, s.config.MinYears),
+		monthlyNormals(monthlyValues(days, s.config.MinMonthCoverage), s.config.MinYears)...)
+
+	now := time.Now()
+	for i := range normals {
+		normals[i].StationID = station.Identifier()
+		normals[i].FromYear = fromYear
+		normals[i].ToYear = toYear
+		normals[i].ComputedAt = now
+	}
+
+	return s.normalsRepo.ReplaceByStation(ctx, station.Identifier(), normals)
+}
+
+// dailyValues returns values of normal elements for every UTC day of [from, to) with at least minObservations observations.
+// Observations of the station may be reported with any of its identifiers, the day is taken from the one reported more
+func (s *ClimateService) dailyValues(ctx context.Context, station domain.Station, from, to time.Time, minObservations int64) ([]dayValues, error) {
+	aggregates, err := s.observationsRepo.Aggregate(ctx, repository.ObservationsAggregationQuery{
+		StationIDs: station.Identifiers(),
+		From:       from,
+		To:         to,
+		Fields:     normalFields,
+		BucketSize: 24 * time.Hour,
+		SkipBad:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[time.Time]dayValues)
+	for _, aggregate := range aggregates {
+		date := aggregate.Start.UTC()
+		if aggregate.Count < minObservations || aggregate.Count <= byDate[date].count {
+			continue
+		}
+
+		day := dayValues{date: date, count: aggregate.Count, values: make(map[string]float64, len(normalSources))}
+		for element, source := range normalSources {
+			if value := source.value(aggregate.Fields[source.field]); value != nil {
+				day.values[element] = *value
+			}
+		}
+
+		byDate[date] = day
+	}
+
+	days := make([]dayValues, 0, len(byDate))
+	for _, day := range byDate {
+		days = append(days, day)
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].date.Before(days[j].date)
+	})
+
+	return days, nil
+}
+
+// dailyNormals computes normal of every calendar day from values of days within window days of it in all years
+func dailyNormals(days []dayValues, window, minYears int) []domain.Normal {
+	const calendarDays = 366
+
+	type sample struct {
+		year  int
+		value float64
+	}
+
+	samples := make(map[string][][]sample, len(normalSources))
+	for element := range normalSources {
+		samples[element] = make([][]sample, calendarDays)
+	}
+
+	for _, day := range days {
+		index := calendarDay(day.date)
+		for element, value := range day.values {
+			samples[element][index] = append(samples[element][index], sample{year: day.date.Year(), value: value})
+		}
+	}
+
+	normals := make([]domain.Normal, 0)
+	for index := 0; index < calendarDays; index++ {
+		date := time.Date(leapYear, time.January, 1+index, 0, 0, 0, 0, time.UTC)
+
+		for _, element := range domain.NormalElements {
+			values := make([]float64, 0)
+			years := make(map[int]struct{})
+
+			for offset := -window; offset <= window; offset++ {
+				for _, sample := range samples[element][(index+offset+calendarDays)%calendarDays] {
+					values = append(values, sample.value)
+					years[sample.year] = struct{}{}
+				}
+			}
+
+			if len(years) < minYears || len(values) == 0 {
+				continue
+			}
+
+			normal := newNormal(values)
+			normal.Element = element
+			normal.Period = domain.NormalPeriodDaily
+			normal.Month = int(date.Month())
+			normal.Day = date.Day()
+			normal.Years = len(years)
+
+			normals = append(normals, normal)
+		}
+	}
+
+	return normals
+}
+
+// monthlyValues aggregates daily values into calendar months, months of elements covered by less than
+// minCoverage share of days are skipped
+func monthlyValues(days []dayValues, minCoverage float64) []monthValues {
+	type monthSamples struct {
+		start  time.Time
+		values map[string][]float64
+	}
+
+	months := make([]*monthSamples, 0)
+	for _, day := range days {
+		start := time.Date(day.date.Year(), day.date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if len(months) == 0 || !months[len(months)-1].start.Equal(start) {
+			months = append(months, &monthSamples{start: start, values: make(map[string][]float64)})
+		}
+
+		month := months[len(months)-1]
+		for element, value := range day.values {
+			month.values[element] = append(month.values[element], value)
+		}
+	}
+
+	res := make([]monthValues, 0, len(months))
+	for _, month := range months {
+		daysInMonth := month.start.AddDate(0, 1, -1).Day()
+		values := make(map[string]float64, len(month.values))
+
+		for element, daily := range month.values {
+			if float64(len(daily)) < minCoverage*float64(daysInMonth) {
+				continue
+			}
+
+			var sum float64
+			for _, value := range daily {
+				sum += value
+			}
+
+			if element == domain.FieldPrecipitation {
+				values[element] = sum
+			} else {
+				values[element] = sum / float64(len(daily))
+			}
+		}
+
+		if len(values) > 0 {
+			res = append(res, monthValues{start: month.start, values: values})
+		}
+	}
+
+	return res
+}
+
+// monthlyNormals computes normal of every calendar month from its values in all years
+func monthlyNormals(months []monthValues, minYears int) []domain.Normal {
+	samples := make(map[string][12][]float64, len(normalSources))
+	for _, month := range months {
+		for element, value := range month.values {
+			byMonth := samples[element]
+			byMonth[month.start.Month()-1] = append(byMonth[month.start.Month()-1], value)
+			samples[element] = byMonth
+		}
+	}
+
+	normals := make([]domain.Normal, 0)
+	for month := 0; month < 12; month++ {
+		for _, element := range domain.NormalElements {
+			values := samples[element][month]
+			if len(values) < minYears || len(values) == 0 {
+				continue
+			}
+
+			normal := newNormal(values)
+			normal.Element = element
+			normal.Period = domain.NormalPeriodMonthly
+			normal.Month = month + 1
+			normal.Years = len(values)
+
+			normals = append(normals, normal)
+		}
+	}
+
+	return normals
+}
+
+// newNormal returns normal with statistics of values, percentiles are linearly interpolated between sorted values
+func newNormal(values []float64) domain.Normal {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, value := range sorted {
+		sum += value
+	}
+
+	mean := sum / float64(len(sorted))
+
+	var variance float64
+	for _, value := range sorted {
+		variance += (value - mean) * (value - mean)
+	}
+
+	if len(sorted) > 1 {
+		variance /= float64(len(sorted) - 1)
+	}
+
+	percentiles := make([]float64, 100/percentileStep+1)
+	for i := range percentiles {
+		position := float64(i*percentileStep) / 100 * float64(len(sorted)-1)
+		lower := int(math.Floor(position))
+		upper := int(math.Ceil(position))
+		percentiles[i] = sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+	}
+
+	return domain.Normal{
+		Mean:        mean,
+		StdDev:      math.Sqrt(variance),
+		Percentiles: percentiles,
+		Samples:     len(sorted),
+	}
+}
+
+// percentileRank returns percentile rank of the value among percentiles of the normal.
+// Value equal to several percentiles, e.g. no precipitation, gets the middle of their ranks
+func percentileRank(percentiles []float64, value float64) float64 {
+	last := len(percentiles) - 1
+	if last < 0 {
+		return 0
+	}
+
+	if value < percentiles[0] {
+		return 0
+	}
+
+	if value > percentiles[last] {
+		return 100
+	}
+
+	lower, upper := -1, -1
+	for i, p := range percentiles {
+		if p == value {
+			if lower < 0 {
+				lower = i
+			}
+			upper = i
+		}
+	}
+
+	if lower >= 0 {
+		return float64((lower+upper)*percentileStep) / 2
+	}
+
+	for i := 0; i < last; i++ {
+		if value > percentiles[i] && value < percentiles[i+1] {
+			share := (value - percentiles[i]) / (percentiles[i+1] - percentiles[i])
+			return (float64(i) + share) * percentileStep
+		}
+	}
+
+	return 100
+}
+
+// calendarDay returns index of the date's month and day in a leap year
+func calendarDay(date time.Time) int {
+	return time.Date(leapYear, date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).YearDay() - 1
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/meteo"
+)
+
+const (
+	DerivedDewPoint            = "dewPoint"
+	DerivedHeatIndex           = "heatIndex"
+	DerivedWindChill           = "windChill"
+	DerivedHumidex             = "humidex"
+	DerivedApparentTemperature = "apparentTemperature"
+	DerivedSeaLevelPressure    = "seaLevelPressure"
+	DerivedAbsoluteHumidity    = "absoluteHumidity"
+	DerivedMixingRatio         = "mixingRatio"
+)
+
+// derivedSources lists observation fields each derived quantity is computed from
+var derivedSources = map[string][]string{
+	DerivedDewPoint:            {domain.FieldTemperature, domain.FieldHumidity},
+	DerivedHeatIndex:           {domain.FieldTemperature, domain.FieldHumidity},
+	DerivedWindChill:           {domain.FieldTemperature, domain.FieldWindSpeed},
+	DerivedHumidex:             {domain.FieldTemperature, domain.FieldHumidity},
+	DerivedApparentTemperature: {domain.FieldTemperature, domain.FieldHumidity, domain.FieldWindSpeed},
+	DerivedSeaLevelPressure:    {domain.FieldTemperature, domain.FieldStationPressure},
+	DerivedAbsoluteHumidity:    {domain.FieldTemperature, domain.FieldHumidity},
+	DerivedMixingRatio:         {domain.FieldTemperature, domain.FieldHumidity, domain.FieldStationPressure},
+}
+
+func validateDerived(names []string) error {
+	for _, name := range names {
+		if _, ok := derivedSources[name]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownComputedField, name)
+		}
+	}
+
+	return nil
+}
+
+// withDerivedSources adds fields required to compute derived quantities to projected fields.
+// Nil fields mean all fields are projected already
+func withDerivedSources(fields, derived []string) []string {
+	if len(fields) == 0 {
+		return fields
+	}
+
+	res := append([]string{}, fields...)
+	for _, name := range derived {
+		for _, source := range derivedSources[name] {
+			if !containsString(res, source) {
+				res = append(res, source)
+			}
+		}
+	}
+
+	return res
+}
+
+// computeDerived sets computed quantities of the observation. Quantities lacking source values are skipped.
+// Sea level pressure and mixing ratio are computed from station pressure only, observation pressure
+// is reduced to sea level already. Sea level pressure also needs station elevation
+func computeDerived(observation *domain.Observation, derived []string, elevation *float64) {
+	t, rh := observation.Temperature, observation.Humidity
+
+	for _, name := range derived {
+		if !hasValues(observation, derivedSources[name]) {
+			continue
+		}
+
+		var value float64
+		switch name {
+		case DerivedDewPoint:
+			if *rh <= 0 {
+				continue
+			}
+
+			value = meteo.DewPoint(*t, *rh)
+		case DerivedHeatIndex:
+			value = meteo.HeatIndex(*t, *rh)
+		case DerivedWindChill:
+			value = meteo.WindChill(*t, *observation.WindSpeed)
+		case DerivedHumidex:
+			if *rh <= 0 {
+				continue
+			}
+
+			value = meteo.Humidex(*t, meteo.DewPoint(*t, *rh))
+		case DerivedApparentTemperature:
+			value = meteo.ApparentTemperature(*t, *rh, *observation.WindSpeed)
+		case DerivedSeaLevelPressure:
+			if elevation == nil {
+				continue
+			}
+
+			value = meteo.SeaLevelPressure(*observation.StationPressure, *elevation, *t)
+		case DerivedAbsoluteHumidity:
+			value = meteo.AbsoluteHumidity(*t, *rh)
+		case DerivedMixingRatio:
+			value = meteo.MixingRatio(*t, *rh, *observation.StationPressure)
+		}
+
+		if observation.Computed == nil {
+			observation.Computed = make(map[string]float64, len(derived))
+		}
+
+		observation.Computed[name] = value
+	}
+}
+
+// stationElevations returns elevations of registered stations among observations ones
+func stationElevations(ctx context.Context, repo repository.Stations, observations []domain.Observation) (map[string]*float64, error) {
+	elevations := make(map[string]*float64)
+
+	for _, observation := range observations {
+		if _, ok := elevations[observation.StationID]; ok {
+			continue
+		}
+
+		station, err := repo.GetByIdentifier(ctx, observation.StationID)
+		if err != nil {
+			if err == repository.ErrStationNotFound {
+				elevations[observation.StationID] = nil
+				continue
+			}
+
+			return nil, err
+		}
+
+		elevation := station.Elevation
+		elevations[observation.StationID] = &elevation
+	}
+
+	return elevations, nil
+}
+
+func hasValues(observation *domain.Observation, fields []string) bool {
+	for _, field := range fields {
+		if observation.Value(field) == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsString(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"gitlab.com/peleng-meteo/meteo-go/internal/config"
 	emailProvider "gitlab.com/peleng-meteo/meteo-go/pkg/email"
+	"time"
 )
 
 const (
@@ -23,6 +24,18 @@ type verificationEmailInput struct {
 	VerificationLink string
 }
 
+type alertEmailInput struct {
+	Name      string
+	RuleName  string
+	StationID string
+	Field     string
+	Operator  string
+	Threshold float64
+	Value     float64
+	State     string
+	Time      string
+}
+
 func NewEmailsService(provider emailProvider.Provider, sender emailProvider.Sender, config config.EmailConfig, frontendUrl string) *EmailService {
 	return &EmailService{provider: provider, sender: sender, config: config, frontendUrl: frontendUrl}
 }
@@ -49,6 +62,28 @@ func (s *EmailService) SendVerificationEmail(input SendVerificationEmailInput) e
 	return s.sender.Send(sendInput)
 }
 
+func (s *EmailService) SendAlertEmail(input SendAlertEmailInput) error {
+	subject := fmt.Sprintf(s.config.Subjects.Alert, input.Event.State, input.Rule.Name)
+
+	templateInput := alertEmailInput{
+		Name:      input.Name,
+		RuleName:  input.Rule.Name,
+		StationID: input.Event.StationID,
+		Field:     input.Event.Field,
+		Operator:  input.Rule.Operator,
+		Threshold: input.Event.Threshold,
+		Value:     input.Event.Value,
+		State:     input.Event.State,
+		Time:      input.Event.Timestamp.UTC().Format(time.RFC1123),
+	}
+	sendInput := emailProvider.SendEmailInput{Subject: subject, To: input.Email}
+	if err := sendInput.GenerateBodyFromHTML(s.config.Templates.Alert, templateInput); err != nil {
+		return err
+	}
+
+	return s.sender.Send(sendInput)
+}
+
 func (s *EmailService) createVerificationLink(code string) string {
 	return fmt.Sprintf(verificationLinkTmpl, s.frontendUrl, code)
 }
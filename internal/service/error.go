@@ -7,4 +7,60 @@ var (
 	ErrUnknownCallbackType     = errors.New("unknown callback type")
 	ErrVerificationCodeInvalid = errors.New("verification code is invalid")
 	ErrUserAlreadyExists       = errors.New("user with such email already exists")
+	ErrAdminNotFound           = errors.New("admin doesn't exists")
+	ErrAdminAlreadyExists      = errors.New("admin with such email already exists")
+	ErrLastSuperAdmin          = errors.New("the last super admin can't be removed or demoted")
+	ErrNotSuperAdmin           = errors.New("only super admins can manage admins")
+	ErrUnknownRole             = errors.New("unknown role, use one of viewer, operator, station-owner, admin")
+	ErrInvalidGrant            = errors.New("admin role can't be granted for stations only")
+	ErrSessionNotFound         = errors.New("session doesn't exists")
+	ErrInvalidRefreshToken     = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused      = errors.New("refresh token was already used, the session is revoked")
+	ErrAPIKeyNotFound          = errors.New("api key doesn't exists")
+	ErrInvalidAPIKey           = errors.New("api key is invalid or expired")
+	ErrAPIKeyIPNotAllowed      = errors.New("api key isn't allowed from this address")
+	ErrUnknownAPIKeyScope      = errors.New("api key needs at least one scope of read, ingest, admin")
+	ErrInvalidAllowedIP        = errors.New("allowed ips must be ip addresses or cidr ranges")
+	ErrInvalidAPIKeyExpiry     = errors.New("api key expiry must be in the future")
+	ErrStationNotFound         = errors.New("station doesn't exists")
+	ErrStationAlreadyExists    = errors.New("station with such identifier already exists")
+	ErrStationIdentifierEmpty  = errors.New("station must have WMO or ICAO identifier")
+	ErrInvalidStationStatus    = errors.New("invalid station status")
+	ErrInvalidStationType      = errors.New("invalid station type, use one of synoptic, aviation, automatic, climate")
+	ErrInvalidBoundingBox      = errors.New("invalid bounding box")
+	ErrUnknownObservationField = errors.New("unknown observation field")
+	ErrUnknownComputedField    = errors.New("unknown computed field")
+	ErrUnknownUnitSystem       = errors.New("unknown unit system, use metric or imperial")
+	ErrInvalidTimezone         = errors.New("invalid timezone")
+	ErrInvalidLocale           = errors.New("invalid locale")
+	ErrUnknownQualityFlag      = errors.New("unknown quality flag, use one of good, suspect, bad, unchecked")
+	ErrObservationNotFound     = errors.New("observation doesn't exists or has no such field")
+	ErrModelRunNotFound        = errors.New("there is no model run covering requested time")
+	ErrOutsideModelGrid        = errors.New("point is outside of the model grid")
+	ErrModelGridMismatch       = errors.New("field grid differs from grid of the model run")
+	ErrTooManyForecastHours    = errors.New("too many forecast hours requested")
+	ErrInvalidNormalsPeriod    = errors.New("invalid normals period, use daily or monthly")
+	ErrUnknownNormalElement    = errors.New("unknown normal element")
+	ErrInvalidReferencePeriod  = errors.New("invalid reference period")
+	ErrNormalsJobNotFound      = errors.New("normals job doesn't exists")
+	ErrNormalsQueueFull        = errors.New("too many normals jobs are queued, try again later")
+	ErrInvalidTimeRange        = errors.New("invalid time range")
+	ErrTooLongTimeRange        = errors.New("time range is too long")
+	ErrInvalidCursor           = errors.New("invalid cursor")
+	ErrInvalidInterval         = errors.New("invalid aggregation interval")
+	ErrTooManyBuckets          = errors.New("too many aggregation buckets, use larger interval or shorter time range")
+	ErrInvalidForecast         = errors.New("invalid forecast")
+	ErrForecastNotFound        = errors.New("forecast doesn't exists")
+	ErrForecastAlreadyExists   = errors.New("forecast is already stored")
+	ErrUnknownImportFormat     = errors.New("unknown import format")
+	ErrInvalidColumnMapping    = errors.New("invalid column mapping")
+	ErrEmptyImport             = errors.New("import file is empty")
+	ErrUnknownExportFormat     = errors.New("unknown export format")
+	ErrAlertRuleNotFound       = errors.New("alert rule doesn't exists")
+	ErrInvalidAlertOperator    = errors.New("invalid alert operator, use one of >, >=, <, <=")
+	ErrInvalidAlertRule        = errors.New("hysteresis and duration of alert rule can't be negative")
+	ErrWebhookNotFound         = errors.New("webhook doesn't exists")
+	ErrInvalidWebhookURL       = errors.New("webhook url must be absolute https url")
+	ErrWebhookURLNotPublic     = errors.New("webhook url must resolve to public address")
+	ErrInvalidWebhookEvent     = errors.New("webhook must subscribe to at least one known event type")
 )
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatCFJSON = "cf-json"
+
+	cfConventions = "CF-1.8"
+	cfTimeUnits   = "seconds since 1970-01-01T00:00:00Z"
+)
+
+// cfVariable describes CF standard name and units of observation field
+type cfVariable struct {
+	name         string
+	standardName string
+	units        string
+}
+
+// defaultExportQuality leaves out values flagged bad, exported files don't carry quality flags to tell them apart
+var defaultExportQuality = []string{domain.QualityGood, domain.QualitySuspect, domain.QualityUnchecked}
+
+var cfVariables = map[string]cfVariable{
+	domain.FieldTemperature:     {"air_temperature", "air_temperature", "degC"},
+	domain.FieldHumidity:        {"relative_humidity", "relative_humidity", "%"},
+	domain.FieldPressure:        {"air_pressure_at_mean_sea_level", "air_pressure_at_mean_sea_level", "hPa"},
+	domain.FieldStationPressure: {"surface_air_pressure", "surface_air_pressure", "hPa"},
+	domain.FieldWindSpeed:       {"wind_speed", "wind_speed", "m s-1"},
+	domain.FieldWindGust:        {"wind_speed_of_gust", "wind_speed_of_gust", "m s-1"},
+	domain.FieldWindDirection:   {"wind_from_direction", "wind_from_direction", "degree"},
+	domain.FieldPrecipitation:   {"precipitation_amount", "precipitation_amount", "kg m-2"},
+}
+
+type ExportService struct {
+	repo         repository.Observations
+	stationsRepo repository.Stations
+}
+
+func NewExportService(repo repository.Observations, stationsRepo repository.Stations) *ExportService {
+	return &ExportService{repo: repo, stationsRepo: stationsRepo}
+}
+
+// Export writes station observations in the requested format streaming them from the repository
+func (s *ExportService) Export(ctx context.Context, w io.Writer, inp ExportInput) error {
+	if len(inp.Fields) == 0 {
+		inp.Fields = domain.ObservationFields
+	}
+
+	for _, field := range inp.Fields {
+		if !domain.IsObservationField(field) {
+			return fmt.Errorf("%w: %s", ErrUnknownObservationField, field)
+		}
+	}
+
+	if len(inp.Quality) == 0 {
+		inp.Quality = defaultExportQuality
+	}
+
+	for _, flag := range inp.Quality {
+		if !domain.IsQualityFlag(flag) {
+			return fmt.Errorf("%w: %s", ErrUnknownQualityFlag, flag)
+		}
+	}
+
+	if !inp.From.IsZero() && !inp.To.IsZero() && !inp.From.Before(inp.To) {
+		return ErrInvalidTimeRange
+	}
+
+	// observations received during export are excluded, so every pass over them returns the same set
+	query := repository.ObservationsQuery{
+		StationIDs:     []string{inp.StationID},
+		From:           inp.From,
+		To:             inp.To,
+		ReceivedBefore: time.Now(),
+		Fields:         inp.Fields,
+	}
+
+	buf := bufio.NewWriter(w)
+
+	var err error
+	switch inp.Format {
+	case ExportFormatCSV:
+		err = s.exportCSV(ctx, buf, query, inp.Quality)
+	case ExportFormatNDJSON:
+		err = s.exportNDJSON(ctx, buf, query, inp.Quality)
+	case ExportFormatCFJSON:
+		err = s.exportCFJSON(ctx, buf, query, inp.Quality)
+	default:
+		return ErrUnknownExportFormat
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return buf.Flush()
+}
+
+func (s *ExportService) exportCSV(ctx context.Context, w io.Writer, query repository.ObservationsQuery, quality []string) error {
+	writer := csv.NewWriter(w)
+
+	header := append([]string{fieldStationId, fieldTimestamp}, query.Fields...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(header))
+	err := s.stream(ctx, query, quality, func(observation domain.Observation) error {
+		record[0] = observation.StationID
+		record[1] = observation.Timestamp.UTC().Format(time.RFC3339)
+
+		for i, field := range query.Fields {
+			record[i+2] = formatValue(observation.Value(field))
+		}
+
+		return writer.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func (s *ExportService) exportNDJSON(ctx context.Context, w io.Writer, query repository.ObservationsQuery, quality []string) error {
+	encoder := json.NewEncoder(w)
+
+	return s.stream(ctx, query, quality, func(observation domain.Observation) error {
+		return encoder.Encode(observation)
+	})
+}
+
+// exportCFJSON writes CF-JSON (https://cf-json.org) time series document. Variables are column-oriented,
+// so observations are streamed once per variable instead of being buffered
+func (s *ExportService) exportCFJSON(ctx context.Context, w io.Writer, query repository.ObservationsQuery, quality []string) error {
+	count, err := s.repo.Count(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	stationId := query.StationIDs[0]
+	attributes := map[string]interface{}{
+		"Conventions": cfConventions,
+		"featureType": "timeSeries",
+		"title":       "Observations of station " + stationId,
+	}
+
+	if _, err := fmt.Fprintf(w, `{"attributes":%s,"dimensions":{"time":%d},"variables":{`, mustJSON(attributes), count); err != nil {
+		return err
+	}
+
+	stationVariables, err := s.cfStationVariables(ctx, stationId)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, stationVariables); err != nil {
+		return err
+	}
+
+	timeAttributes := map[string]interface{}{"standard_name": "time", "units": cfTimeUnits, "calendar": "standard"}
+	err = s.writeCFVariable(ctx, w, "time", timeAttributes, query, quality, func(observation domain.Observation) string {
+		return strconv.FormatInt(observation.Timestamp.Unix(), 10)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, field := range query.Fields {
+		variable := cfVariables[field]
+		fieldQuery := query
+		fieldQuery.Fields = []string{field}
+
+		if _, err := io.WriteString(w, ","); err != nil {
+			return err
+		}
+
+		attributes := map[string]interface{}{
+			"standard_name": variable.standardName,
+			"units":         variable.units,
+			"coordinates":   "time latitude longitude",
+		}
+
+		field := field
+		err := s.writeCFVariable(ctx, w, variable.name, attributes, fieldQuery, quality, func(observation domain.Observation) string {
+			if value := formatValue(observation.Value(field)); value != "" {
+				return value
+			}
+
+			return "null"
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, "}}\n")
+
+	return err
+}
+
+// cfStationVariables returns station id and coordinates variables. Coordinates are omitted for unregistered stations
+func (s *ExportService) cfStationVariables(ctx context.Context, stationId string) (string, error) {
+	res := fmt.Sprintf(`"station_id":{"shape":[],"type":"string","attributes":{"cf_role":"timeseries_id"},"data":%s},`, mustJSON(stationId))
+
+	station, err := s.stationsRepo.GetByIdentifier(ctx, stationId)
+	if err != nil {
+		if err == repository.ErrStationNotFound {
+			return res, nil
+		}
+
+		return "", err
+	}
+
+	coordinates := []struct {
+		name, units string
+		value       float64
+	}{
+		{"latitude", "degrees_north", station.Latitude},
+		{"longitude", "degrees_east", station.Longitude},
+		{"altitude", "m", station.Elevation},
+	}
+
+	for _, c := range coordinates {
+		res += fmt.Sprintf(`%s:{"shape":[],"type":"double","attributes":{"standard_name":%s,"units":%s},"data":%s},`,
+			mustJSON(c.name), mustJSON(c.name), mustJSON(c.units), strconv.FormatFloat(c.value, 'f', -1, 64))
+	}
+
+	return res, nil
+}
+
+func (s *ExportService) writeCFVariable(ctx context.Context, w io.Writer, name string, attributes map[string]interface{},
+	query repository.ObservationsQuery, quality []string, value func(observation domain.Observation) string) error {
+	if _, err := fmt.Fprintf(w, `%s:{"shape":["time"],"type":"double","attributes":%s,"data":[`, mustJSON(name), mustJSON(attributes)); err != nil {
+		return err
+	}
+
+	first := true
+	err := s.stream(ctx, query, quality, func(observation domain.Observation) error {
+		separator := ","
+		if first {
+			separator, first = "", false
+		}
+
+		_, err := io.WriteString(w, separator+value(observation))
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]}")
+
+	return err
+}
+
+// stream passes observations to fn with values of other than the given quality flags unset
+func (s *ExportService) stream(ctx context.Context, query repository.ObservationsQuery, quality []string,
+	fn func(observation domain.Observation) error) error {
+	return s.repo.Stream(ctx, query, func(observation domain.Observation) error {
+		filterObservationQuality(&observation, quality)
+
+		return fn(observation)
+	})
+}
+
+func formatValue(value *float64) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+// mustJSON marshals values that can't fail marshalling: strings and maps of strings
+func mustJSON(value interface{}) string {
+	res, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(res)
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/metar"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/taf"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+	"time"
+)
+
+const feetInMeter = 3.28084
+
+type TerminalForecastsService struct {
+	repo repository.TerminalForecasts
+}
+
+func NewTerminalForecastsService(repo repository.TerminalForecasts) *TerminalForecastsService {
+	return &TerminalForecastsService{repo: repo}
+}
+
+// Create decodes raw TAF and stores it
+func (s *TerminalForecastsService) Create(ctx context.Context, raw string) (primitive.ObjectID, error) {
+	decoded, err := taf.Decode(raw)
+	if err != nil {
+		return primitive.ObjectID{}, fmt.Errorf("%w: %s", ErrInvalidForecast, err.Error())
+	}
+
+	if decoded.Nil || decoded.Cancelled {
+		return primitive.ObjectID{}, fmt.Errorf("%w: forecast is NIL or cancelled", ErrInvalidForecast)
+	}
+
+	id, err := s.repo.Create(ctx, toTerminalForecast(decoded, raw, time.Now()))
+	if err != nil {
+		if err == repository.ErrForecastAlreadyExists {
+			return primitive.ObjectID{}, ErrForecastAlreadyExists
+		}
+
+		return primitive.ObjectID{}, err
+	}
+
+	return id, nil
+}
+
+// GetInEffect returns conditions forecast for the station at the given time by the latest valid forecast
+func (s *TerminalForecastsService) GetInEffect(ctx context.Context, stationId string, at time.Time) (domain.ForecastInEffect, error) {
+	forecast, err := s.repo.GetValidAt(ctx, stationId, at)
+	if err != nil {
+		if err == repository.ErrForecastNotFound {
+			return domain.ForecastInEffect{}, ErrForecastNotFound
+		}
+
+		return domain.ForecastInEffect{}, err
+	}
+
+	return forecast.InEffect(at), nil
+}
+
+// toTerminalForecast resolves relative forecast times using ref as the current time
+func toTerminalForecast(decoded taf.Forecast, raw string, ref time.Time) domain.TerminalForecast {
+	issuedAt := decoded.IssueTime(ref)
+
+	forecast := domain.TerminalForecast{
+		StationID: decoded.Station,
+		IssuedAt:  issuedAt,
+		ValidFrom: decoded.ValidFrom.Time(issuedAt),
+		ValidTo:   decoded.ValidTo.Time(issuedAt),
+		Amended:   decoded.Amended,
+		Raw:       strings.TrimSpace(raw),
+		Base:      toForecastConditions(decoded.Base),
+		Changes:   make([]domain.ForecastChange, len(decoded.Changes)),
+		CreatedAt: time.Now(),
+	}
+
+	for i, change := range decoded.Changes {
+		forecast.Changes[i] = domain.ForecastChange{
+			Type:        change.Type,
+			Probability: change.Probability,
+			From:        change.From.Time(issuedAt),
+			To:          change.To.Time(issuedAt),
+			Conditions:  toForecastConditions(change.Conditions),
+		}
+	}
+
+	return forecast
+}
+
+func toForecastConditions(c taf.Conditions) domain.ForecastConditions {
+	res := domain.ForecastConditions{
+		CAVOK:                c.CAVOK,
+		NoSignificantWeather: c.NoSignificantWeather,
+		VerticalVisibility:   feetToMeters(c.VerticalVisibility),
+	}
+
+	if c.Wind != nil {
+		speed := c.Wind.MetersPerSecond(c.Wind.Speed)
+		res.WindSpeed = &speed
+		res.WindVariable = c.Wind.Variable
+
+		if !c.Wind.Variable && !c.Wind.DirectionUnknown {
+			direction := float64(c.Wind.Direction)
+			res.WindDirection = &direction
+		}
+
+		if c.Wind.Gust > 0 {
+			gust := c.Wind.MetersPerSecond(c.Wind.Gust)
+			res.WindGust = &gust
+		}
+	}
+
+	if c.Visibility != nil {
+		visibility := c.Visibility.Meters()
+		res.Visibility = &visibility
+	}
+
+	for _, wx := range c.Weather {
+		res.Weather = append(res.Weather, wx.String())
+	}
+
+	for _, layer := range c.Clouds {
+		res.Clouds = append(res.Clouds, toCloudLayer(layer))
+	}
+
+	return res
+}
+
+func toCloudLayer(layer metar.CloudLayer) domain.CloudLayer {
+	return domain.CloudLayer{
+		Cover:  layer.Cover,
+		Height: feetToMeters(layer.Height),
+		Type:   layer.Type,
+	}
+}
+
+func feetToMeters(feet *int) *float64 {
+	if feet == nil {
+		return nil
+	}
+
+	meters := float64(*feet) / feetInMeter
+
+	return &meters
+}
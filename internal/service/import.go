@@ -0,0 +1,358 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+
+	TimeFormatUnix      = "unix"
+	TimeFormatUnixMilli = "unixms"
+
+	fieldStationId = "stationId"
+	fieldTimestamp = "timestamp"
+
+	defaultImportBatchSize = 1000
+	maxImportBatchSize     = 10000
+	maxImportRowErrors     = 1000
+	maxNDJSONLineSize      = 1024 * 1024
+)
+
+type ImportService struct {
+	repo           repository.Observations
+	qualityControl QualityControl
+}
+
+func NewImportService(repo repository.Observations, qualityControl QualityControl) *ImportService {
+	return &ImportService{repo: repo, qualityControl: qualityControl}
+}
+
+// Import streams observations from CSV (with header row) or NDJSON into the repository in batches.
+// Rows failing validation are reported and skipped, observations already stored are skipped as duplicates.
+// Batches pass quality control like ingested observations do
+func (s *ImportService) Import(ctx context.Context, r io.Reader, inp ImportInput) (ImportReport, error) {
+	if inp.BatchSize <= 0 {
+		inp.BatchSize = defaultImportBatchSize
+	}
+
+	if inp.BatchSize > maxImportBatchSize {
+		inp.BatchSize = maxImportBatchSize
+	}
+
+	mapping, err := importMapping(inp.Mapping)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	var rows rowReader
+	switch inp.Format {
+	case ImportFormatCSV:
+		rows, err = newCSVRowReader(r)
+	case ImportFormatNDJSON:
+		rows = newNDJSONRowReader(r)
+	default:
+		return ImportReport{}, ErrUnknownImportFormat
+	}
+
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	var (
+		report ImportReport
+		batch  = make([]domain.Observation, 0, inp.BatchSize)
+	)
+
+	flush := func() error {
+		if err := s.qualityControl.CheckBatch(ctx, batch); err != nil {
+			return err
+		}
+
+		inserted, err := s.repo.CreateMany(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		report.Inserted += inserted
+		report.Duplicates += len(batch) - inserted
+		batch = batch[:0]
+
+		return nil
+	}
+
+	for {
+		row, rowNumber, err := rows.next()
+		if err == io.EOF {
+			break
+		}
+
+		report.Total++
+
+		if err == nil {
+			var observation domain.Observation
+			observation, err = toImportedObservation(row, mapping, inp)
+			if err == nil {
+				batch = append(batch, observation)
+			}
+		}
+
+		if err != nil {
+			if _, ok := err.(*rowError); !ok {
+				return report, err
+			}
+
+			report.addError(rowNumber, err)
+			continue
+		}
+
+		if len(batch) == inp.BatchSize {
+			if err := flush(); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	return report, flush()
+}
+
+func (r *ImportReport) addError(row int, err error) {
+	r.Failed++
+	if len(r.Errors) < maxImportRowErrors {
+		r.Errors = append(r.Errors, ImportRowError{Row: row, Message: err.Error()})
+	}
+}
+
+// ParseColumnMapping parses mapping like "temperature=t,humidity=rh" of observation fields to columns
+func ParseColumnMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidColumnMapping, pair)
+		}
+
+		mapping[parts[0]] = parts[1]
+	}
+
+	return mapping, nil
+}
+
+// importMapping returns columns of every importable field, columns are named as fields by default
+func importMapping(custom map[string]string) (map[string]string, error) {
+	mapping := map[string]string{
+		fieldStationId: fieldStationId,
+		fieldTimestamp: fieldTimestamp,
+	}
+
+	for _, field := range domain.ObservationFields {
+		mapping[field] = field
+	}
+
+	for field, column := range custom {
+		if _, ok := mapping[field]; !ok {
+			return nil, fmt.Errorf("%w: unknown field %s", ErrInvalidColumnMapping, field)
+		}
+
+		mapping[field] = column
+	}
+
+	return mapping, nil
+}
+
+func toImportedObservation(row map[string]string, mapping map[string]string, inp ImportInput) (domain.Observation, error) {
+	observation := domain.Observation{
+		StationID:  row[mapping[fieldStationId]],
+		ReceivedAt: time.Now().UTC(),
+	}
+
+	if observation.StationID == "" {
+		observation.StationID = inp.StationID
+	}
+
+	if observation.StationID == "" {
+		return domain.Observation{}, &rowError{"station id is missing"}
+	}
+
+	ts, err := parseImportTime(row[mapping[fieldTimestamp]], inp.TimeFormat)
+	if err != nil {
+		return domain.Observation{}, &rowError{fmt.Sprintf("invalid timestamp %q", row[mapping[fieldTimestamp]])}
+	}
+
+	observation.Timestamp = ts
+
+	for _, field := range domain.ObservationFields {
+		value, ok := row[mapping[field]]
+		if !ok || value == "" {
+			continue
+		}
+
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return domain.Observation{}, &rowError{fmt.Sprintf("invalid %s value %q", field, value)}
+		}
+
+		observation.SetValue(field, &number)
+	}
+
+	return observation, nil
+}
+
+func parseImportTime(value, format string) (time.Time, error) {
+	switch format {
+	case "":
+		ts, err := time.Parse(time.RFC3339, value)
+		return ts.UTC(), err
+	case TimeFormatUnix, TimeFormatUnixMilli:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if format == TimeFormatUnixMilli {
+			return time.Unix(0, number*int64(time.Millisecond)).UTC(), nil
+		}
+
+		return time.Unix(number, 0).UTC(), nil
+	default:
+		ts, err := time.Parse(format, value)
+		return ts.UTC(), err
+	}
+}
+
+// rowError is a validation error of a single row
+type rowError struct {
+	msg string
+}
+
+func (e *rowError) Error() string {
+	return e.msg
+}
+
+// rowReader returns rows as column-value maps with their numbers (header is the first row of CSV)
+type rowReader interface {
+	next() (map[string]string, int, error)
+}
+
+type csvRowReader struct {
+	reader *csv.Reader
+	header []string
+	row    int
+}
+
+func newCSVRowReader(r io.Reader) (*csvRowReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, ErrEmptyImport
+		}
+
+		return nil, err
+	}
+
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	return &csvRowReader{reader: reader, header: header, row: 1}, nil
+}
+
+func (r *csvRowReader) next() (map[string]string, int, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, 0, err
+	}
+
+	r.row++
+
+	if err != nil {
+		if parseErr, ok := err.(*csv.ParseError); ok {
+			return nil, r.row, &rowError{parseErr.Err.Error()}
+		}
+
+		return nil, r.row, err
+	}
+
+	if len(record) != len(r.header) {
+		return nil, r.row, &rowError{fmt.Sprintf("expected %d columns, got %d", len(r.header), len(record))}
+	}
+
+	row := make(map[string]string, len(record))
+	for i, value := range record {
+		row[r.header[i]] = strings.TrimSpace(value)
+	}
+
+	return row, r.row, nil
+}
+
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONRowReader(r io.Reader) *ndjsonRowReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxNDJSONLineSize)
+
+	return &ndjsonRowReader{scanner: scanner}
+}
+
+func (r *ndjsonRowReader) next() (map[string]string, int, error) {
+	for r.scanner.Scan() {
+		r.line++
+
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var object map[string]interface{}
+		decoder := json.NewDecoder(strings.NewReader(line))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&object); err != nil {
+			return nil, r.line, &rowError{"invalid json: " + err.Error()}
+		}
+
+		row := make(map[string]string, len(object))
+		for key, value := range object {
+			switch v := value.(type) {
+			case nil:
+			case string:
+				row[key] = v
+			case json.Number:
+				row[key] = v.String()
+			default:
+				return nil, r.line, &rowError{fmt.Sprintf("unsupported value of %s", key)}
+			}
+		}
+
+		return row, r.line, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, r.line, err
+	}
+
+	return nil, 0, io.EOF
+}
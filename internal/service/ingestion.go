@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/logger"
+	"time"
+)
+
+type IngestionService struct {
+	sensors  repository.Sensors
+	repo     repository.Observations
+	qc       QualityControl
+	alerts   Alerts
+	webhooks Webhooks
+	stream   Stream
+
+	retryInterval time.Duration
+}
+
+func NewIngestionService(sensors repository.Sensors, repo repository.Observations, qc QualityControl, alerts Alerts,
+	webhooks Webhooks, stream Stream, retryInterval time.Duration) *IngestionService {
+	return &IngestionService{
+		sensors:       sensors,
+		repo:          repo,
+		qc:            qc,
+		alerts:        alerts,
+		webhooks:      webhooks,
+		stream:        stream,
+		retryInterval: retryInterval,
+	}
+}
+
+// Run reads frames from sensors, parses and stores them until ctx is cancelled
+func (s *IngestionService) Run(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+
+		if err := s.sensors.Close(); err != nil {
+			logger.Errorf("failed to close sensors source: %s", err.Error())
+		}
+	}()
+
+	for ctx.Err() == nil {
+		frame, err := s.sensors.Read()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			logger.Errorf("failed to read sensors frame: %s", err.Error())
+			s.wait(ctx)
+
+			continue
+		}
+
+		if err := s.ingest(ctx, frame); err != nil {
+			logger.Errorf("failed to ingest frame %q: %s", frame, err.Error())
+		}
+	}
+}
+
+func (s *IngestionService) ingest(ctx context.Context, frame []byte) error {
+	observation, err := s.sensors.Parse(frame)
+	if err != nil {
+		return err
+	}
+
+	if err := s.qc.Check(ctx, &observation); err != nil {
+		return err
+	}
+
+	if err := s.repo.Create(ctx, observation); err != nil {
+		return err
+	}
+
+	s.stream.Publish(observation)
+
+	// notification failures shouldn't be reported as failed ingestion, observation is already stored
+	if err := s.alerts.Evaluate(ctx, observation); err != nil {
+		logger.Errorf("failed to evaluate alert rules of station %s: %s", observation.StationID, err.Error())
+	}
+
+	if err := s.webhooks.Dispatch(ctx, WebhookEvent{
+		Type:      domain.WebhookEventObservationCreated,
+		StationID: observation.StationID,
+		Data:      observation,
+	}); err != nil {
+		logger.Errorf("failed to dispatch station %s observation webhooks: %s", observation.StationID, err.Error())
+	}
+
+	return nil
+}
+
+func (s *IngestionService) wait(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(s.retryInterval):
+	}
+}
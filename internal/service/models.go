@@ -0,0 +1,520 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/grib2"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/meteo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	zeroCelsius          = 273.15
+	pascalsInHectopascal = 100
+
+	defaultModelForecastPeriod = 48 * time.Hour
+)
+
+// modelParameter maps GRIB2 parameter at the surface to the stored model parameter
+type modelParameter struct {
+	grib        string
+	surface     grib2.Surface
+	name        string
+	convert     func(v float64) float64
+	accumulated bool
+}
+
+var modelParameters = []modelParameter{
+	{grib: "TMP", surface: grib2.Surface{Type: grib2.SurfaceHeightAboveGround, Value: 2}, name: domain.FieldTemperature,
+		convert: func(v float64) float64 { return v - zeroCelsius }},
+	{grib: "RH", surface: grib2.Surface{Type: grib2.SurfaceHeightAboveGround, Value: 2}, name: domain.FieldHumidity},
+	{grib: "PRMSL", surface: grib2.Surface{Type: grib2.SurfaceMeanSeaLevel}, name: domain.FieldPressure,
+		convert: func(v float64) float64 { return v / pascalsInHectopascal }},
+	{grib: "UGRD", surface: grib2.Surface{Type: grib2.SurfaceHeightAboveGround, Value: 10}, name: domain.ModelParameterWindU},
+	{grib: "VGRD", surface: grib2.Surface{Type: grib2.SurfaceHeightAboveGround, Value: 10}, name: domain.ModelParameterWindV},
+	{grib: "GUST", surface: grib2.Surface{Type: grib2.SurfaceGround}, name: domain.FieldWindGust},
+	{grib: "GUST", surface: grib2.Surface{Type: grib2.SurfaceHeightAboveGround, Value: 10}, name: domain.FieldWindGust},
+	// kg/m² of water is 1 mm
+	{grib: "APCP", surface: grib2.Surface{Type: grib2.SurfaceGround}, name: domain.FieldPrecipitation, accumulated: true},
+}
+
+type ModelsService struct {
+	runsRepo     repository.ModelRuns
+	fieldsRepo   repository.ModelFields
+	stationsRepo repository.Stations
+}
+
+func NewModelsService(runsRepo repository.ModelRuns, fieldsRepo repository.ModelFields, stationsRepo repository.Stations) *ModelsService {
+	return &ModelsService{
+		runsRepo:     runsRepo,
+		fieldsRepo:   fieldsRepo,
+		stationsRepo: stationsRepo,
+	}
+}
+
+// Load stores fields of known parameters from GRIB2 file. Messages that can't be decoded and fields
+// of other parameters are skipped and counted in the report, only read and storage errors abort loading
+func (s *ModelsService) Load(ctx context.Context, r io.Reader, inp LoadModelInput) (ModelLoadReport, error) {
+	report := ModelLoadReport{File: inp.File, Errors: make([]string, 0)}
+	runs := make(map[time.Time]*loadedModelRun)
+
+	reader := grib2.NewReader(r)
+	for {
+		fields, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			if !isGRIBDecodeError(err) {
+				return report, err
+			}
+
+			report.Skipped++
+			report.Errors = append(report.Errors, err.Error())
+
+			continue
+		}
+
+		for i := range fields {
+			stored, err := s.loadField(ctx, inp, &fields[i], runs)
+			if err != nil && err != ErrModelGridMismatch {
+				return report, err
+			}
+
+			if err != nil {
+				report.Errors = append(report.Errors, err.Error())
+			}
+
+			if stored {
+				report.Fields++
+			} else {
+				report.Skipped++
+			}
+		}
+	}
+
+	for _, run := range runs {
+		if err := s.runsRepo.Extend(ctx, run.extension); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// loadedModelRun collects parameters and validity of the run fields loaded from the file
+type loadedModelRun struct {
+	grid      domain.ModelGrid
+	extension repository.ExtendModelRunInput
+}
+
+func (s *ModelsService) loadField(ctx context.Context, inp LoadModelInput, field *grib2.Field, runs map[time.Time]*loadedModelRun) (bool, error) {
+	parameter, ok := lookupModelParameter(field)
+	if !ok {
+		return false, nil
+	}
+
+	run, ok := runs[field.ReferenceTime]
+	if !ok {
+		var err error
+		if run, err = s.loadedRun(ctx, inp, field); err != nil {
+			return false, err
+		}
+
+		runs[field.ReferenceTime] = run
+	}
+
+	if toModelGrid(field.Grid) != run.grid {
+		return false, ErrModelGridMismatch
+	}
+
+	if err := s.fieldsRepo.Replace(ctx, modelFieldRows(run.extension.ID, parameter, field)); err != nil {
+		return false, err
+	}
+
+	ext := &run.extension
+	if !containsString(ext.Parameters, parameter.name) {
+		ext.Parameters = append(ext.Parameters, parameter.name)
+	}
+
+	if validTime := field.ValidTime(); validTime.Before(ext.ValidFrom) {
+		ext.ValidFrom = validTime
+	} else if validTime.After(ext.ValidTo) {
+		ext.ValidTo = validTime
+	}
+
+	return true, nil
+}
+
+// loadedRun finds the model run the field belongs to, the run is created on its first field
+func (s *ModelsService) loadedRun(ctx context.Context, inp LoadModelInput, field *grib2.Field) (*loadedModelRun, error) {
+	run, err := s.runsRepo.GetByRunTime(ctx, inp.Model, field.ReferenceTime)
+	if err == repository.ErrModelRunNotFound {
+		run = domain.ModelRun{
+			Model:      inp.Model,
+			RunTime:    field.ReferenceTime,
+			Grid:       toModelGrid(field.Grid),
+			Parameters: make([]string, 0),
+			ValidFrom:  field.ValidTime(),
+			ValidTo:    field.ValidTime(),
+			Files:      make([]string, 0),
+			LoadedAt:   time.Now(),
+		}
+
+		run.ID, err = s.runsRepo.Create(ctx, run)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &loadedModelRun{
+		grid: run.Grid,
+		extension: repository.ExtendModelRunInput{
+			ID:         run.ID,
+			File:       inp.File,
+			Parameters: make([]string, 0),
+			ValidFrom:  field.ValidTime(),
+			ValidTo:    field.ValidTime(),
+		},
+	}, nil
+}
+
+// AtStation returns forecast of the latest model run interpolated at the station coordinates
+func (s *ModelsService) AtStation(ctx context.Context, inp StationModelForecastInput) (PointModelForecast, error) {
+	station, err := s.stationsRepo.GetByIdentifier(ctx, inp.StationID)
+	if err != nil {
+		if err == repository.ErrStationNotFound {
+			return PointModelForecast{}, ErrStationNotFound
+		}
+
+		return PointModelForecast{}, err
+	}
+
+	forecast, err := s.AtPoint(ctx, PointModelForecastInput{
+		Model:     inp.Model,
+		Latitude:  station.Latitude,
+		Longitude: station.Longitude,
+		From:      inp.From,
+		To:        inp.To,
+	})
+	forecast.StationID = inp.StationID
+
+	return forecast, err
+}
+
+// AtPoint returns hourly or coarser forecast of the latest model run covering From, values are bilinearly
+// interpolated from the surrounding grid points. Empty time range selects forecast for the next 48 hours
+func (s *ModelsService) AtPoint(ctx context.Context, inp PointModelForecastInput) (PointModelForecast, error) {
+	if inp.From.IsZero() {
+		inp.From = time.Now().Truncate(time.Hour)
+	}
+
+	if inp.To.IsZero() {
+		inp.To = inp.From.Add(defaultModelForecastPeriod)
+	}
+
+	if !inp.From.Before(inp.To) {
+		return PointModelForecast{}, ErrInvalidTimeRange
+	}
+
+	run, err := s.runsRepo.GetLatest(ctx, inp.Model, inp.From)
+	if err != nil {
+		if err == repository.ErrModelRunNotFound {
+			return PointModelForecast{}, ErrModelRunNotFound
+		}
+
+		return PointModelForecast{}, err
+	}
+
+	forecasts, err := interpolateModelRun(ctx, s.fieldsRepo, run, &modelPoint{latitude: inp.Latitude, longitude: inp.Longitude}, inp.From, inp.To)
+	if err != nil {
+		return PointModelForecast{}, err
+	}
+
+	return PointModelForecast{
+		Model:     run.Model,
+		RunTime:   run.RunTime,
+		LoadedAt:  run.LoadedAt,
+		Latitude:  inp.Latitude,
+		Longitude: inp.Longitude,
+		Forecasts: forecasts,
+	}, nil
+}
+
+type accumulationKey struct {
+	from, to int64
+}
+
+// modelPoint is a point forecast is interpolated at. If nearest is set, value of the nearest grid point is taken
+// instead of interpolation and the point coordinates are moved to it
+type modelPoint struct {
+	latitude  float64
+	longitude float64
+	nearest   bool
+}
+
+// interpolateModelRun returns forecasts of the run valid in [from, to] at the point
+func interpolateModelRun(ctx context.Context, fieldsRepo repository.ModelFields, run domain.ModelRun, point *modelPoint,
+	from, to time.Time) ([]domain.ModelForecast, error) {
+	grid := toGRIBGrid(run.Grid)
+
+	neighbours, err := grid.Neighbours(point.latitude, point.longitude)
+	if err != nil {
+		if err == grib2.ErrOutsideGrid {
+			return nil, ErrOutsideModelGrid
+		}
+
+		return nil, err
+	}
+
+	if point.nearest {
+		nearest := neighbours[0]
+		for _, n := range neighbours[1:] {
+			if n.Weight > nearest.Weight {
+				nearest = n
+			}
+		}
+
+		nearest.Weight = 1
+		neighbours = []grib2.Neighbour{nearest}
+		point.latitude, point.longitude = grid.Point(nearest.I, nearest.J)
+	}
+
+	rowNumbers := make([]int, 0, 2)
+	for _, n := range neighbours {
+		if !containsInt(rowNumbers, n.J) {
+			rowNumbers = append(rowNumbers, n.J)
+		}
+	}
+
+	// accumulations are differentiated, so fields valid before the range are needed too
+	rows, err := fieldsRepo.GetRows(ctx, repository.ModelRowsQuery{
+		RunID: run.ID,
+		Rows:  rowNumbers,
+		From:  run.RunTime,
+		To:    to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type fieldKey struct {
+		parameter    string
+		accumulation accumulationKey
+	}
+
+	fields := make(map[fieldKey]map[int][]float64)
+	for _, row := range rows {
+		key := fieldKey{parameter: row.Parameter, accumulation: accumulationKey{to: row.ValidTime.Unix()}}
+		if !row.AccumulatedFrom.IsZero() {
+			key.accumulation.from = row.AccumulatedFrom.Unix()
+		}
+
+		if fields[key] == nil {
+			fields[key] = make(map[int][]float64)
+		}
+
+		fields[key][row.Row] = row.Values
+	}
+
+	values := make(map[int64]map[string]float64)
+	accumulations := make(map[accumulationKey]float64)
+
+	for key, field := range fields {
+		v := grib2.Weigh(neighbours, func(i, j int) float64 {
+			if i >= len(field[j]) {
+				return math.NaN()
+			}
+
+			return field[j][i]
+		})
+		if math.IsNaN(v) {
+			continue
+		}
+
+		if key.accumulation.from != 0 {
+			accumulations[key.accumulation] = v
+			continue
+		}
+
+		if values[key.accumulation.to] == nil {
+			values[key.accumulation.to] = make(map[string]float64)
+		}
+
+		values[key.accumulation.to][key.parameter] = v
+	}
+
+	validTimes := make([]int64, 0, len(values))
+	for t := range values {
+		validTimes = append(validTimes, t)
+	}
+
+	for key := range accumulations {
+		if !containsInt64(validTimes, key.to) {
+			validTimes = append(validTimes, key.to)
+		}
+	}
+
+	sort.Slice(validTimes, func(i, j int) bool { return validTimes[i] < validTimes[j] })
+
+	forecasts := make([]domain.ModelForecast, 0, len(validTimes))
+	previous := run.RunTime.Unix()
+
+	for _, t := range validTimes {
+		validTime := time.Unix(t, 0).UTC()
+		if validTime.Before(from) {
+			previous = t
+			continue
+		}
+
+		forecast := domain.ModelForecast{ValidTime: validTime}
+		for parameter, v := range values[t] {
+			v := v
+			forecast.SetValue(parameter, &v)
+		}
+
+		u, hasU := values[t][domain.ModelParameterWindU]
+		v, hasV := values[t][domain.ModelParameterWindV]
+		if hasU && hasV {
+			speed, direction := meteo.Wind(u, v)
+			forecast.WindSpeed, forecast.WindDirection = &speed, &direction
+		}
+
+		if amount, ok := accumulation(accumulations, previous, t); ok {
+			forecast.Precipitation = &amount
+		}
+
+		forecasts = append(forecasts, forecast)
+		previous = t
+	}
+
+	return forecasts, nil
+}
+
+// accumulation returns amount accumulated during (from, to] either directly or as difference
+// of two accumulations starting at the same time
+func accumulation(accumulations map[accumulationKey]float64, from, to int64) (float64, bool) {
+	if v, ok := accumulations[accumulationKey{from: from, to: to}]; ok {
+		return v, true
+	}
+
+	for key, total := range accumulations {
+		if key.to != to {
+			continue
+		}
+
+		if before, ok := accumulations[accumulationKey{from: key.from, to: from}]; ok {
+			return math.Max(total-before, 0), true
+		}
+	}
+
+	return 0, false
+}
+
+func lookupModelParameter(field *grib2.Field) (modelParameter, bool) {
+	name := field.Parameter().Name
+	for _, p := range modelParameters {
+		if p.grib == name && p.surface == field.Product.Surface && p.accumulated == (field.Product.Statistical != nil) {
+			return p, true
+		}
+	}
+
+	return modelParameter{}, false
+}
+
+// modelFieldRows splits field values to grid rows ordered by i regardless of scanning mode
+func modelFieldRows(runID primitive.ObjectID, parameter modelParameter, field *grib2.Field) []domain.ModelFieldRow {
+	grid := field.Grid
+	rows := make([]domain.ModelFieldRow, grid.Nj)
+
+	for j := range rows {
+		values := make([]float64, grid.Ni)
+		for i := range values {
+			v := field.Values[grid.Index(i, j)]
+			if parameter.convert != nil && !math.IsNaN(v) {
+				v = parameter.convert(v)
+			}
+
+			values[i] = v
+		}
+
+		rows[j] = domain.ModelFieldRow{
+			RunID:     runID,
+			Parameter: parameter.name,
+			ValidTime: field.ValidTime(),
+			Row:       j,
+			Values:    values,
+		}
+
+		if parameter.accumulated {
+			rows[j].AccumulatedFrom = field.Product.Statistical.Start
+		}
+	}
+
+	return rows
+}
+
+// toModelGrid stores grid geometry, rows are stored ordered by i so boustrophedon scanning flag is dropped
+func toModelGrid(grid grib2.Grid) domain.ModelGrid {
+	return domain.ModelGrid{
+		Ni:       grid.Ni,
+		Nj:       grid.Nj,
+		La1:      grid.La1,
+		Lo1:      grid.Lo1,
+		La2:      grid.La2,
+		Lo2:      grid.Lo2,
+		Di:       grid.Di,
+		Dj:       grid.Dj,
+		ScanMode: int(grid.ScanMode &^ grib2.ScanBoustrophedon),
+	}
+}
+
+func toGRIBGrid(grid domain.ModelGrid) grib2.Grid {
+	return grib2.Grid{
+		Points:   grid.Ni * grid.Nj,
+		Ni:       grid.Ni,
+		Nj:       grid.Nj,
+		La1:      grid.La1,
+		Lo1:      grid.Lo1,
+		La2:      grid.La2,
+		Lo2:      grid.Lo2,
+		Di:       grid.Di,
+		Dj:       grid.Dj,
+		ScanMode: byte(grid.ScanMode),
+	}
+}
+
+func isGRIBDecodeError(err error) bool {
+	var formatErr *grib2.FormatError
+	var unsupportedErr *grib2.UnsupportedError
+
+	return errors.As(err, &formatErr) || errors.As(err, &unsupportedErr)
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsInt64(values []int64, v int64) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultObservationsLimit = 100
+	maxObservationsLimit     = 1000
+)
+
+type ObservationsService struct {
+	repo         repository.Observations
+	stationsRepo repository.Stations
+}
+
+func NewObservationsService(repo repository.Observations, stationsRepo repository.Stations) *ObservationsService {
+	return &ObservationsService{repo: repo, stationsRepo: stationsRepo}
+}
+
+func (s *ObservationsService) Find(ctx context.Context, inp ObservationsQueryInput) (ObservationsPage, error) {
+	for _, field := range inp.Fields {
+		if !domain.IsObservationField(field) {
+			return ObservationsPage{}, fmt.Errorf("%w: %s", ErrUnknownObservationField, field)
+		}
+	}
+
+	if err := validateDerived(inp.Computed); err != nil {
+		return ObservationsPage{}, err
+	}
+
+	for _, flag := range inp.Quality {
+		if !domain.IsQualityFlag(flag) {
+			return ObservationsPage{}, fmt.Errorf("%w: %s", ErrUnknownQualityFlag, flag)
+		}
+	}
+
+	if !inp.From.IsZero() && !inp.To.IsZero() && !inp.From.Before(inp.To) {
+		return ObservationsPage{}, ErrInvalidTimeRange
+	}
+
+	if inp.Limit <= 0 {
+		inp.Limit = defaultObservationsLimit
+	}
+
+	if inp.Limit > maxObservationsLimit {
+		inp.Limit = maxObservationsLimit
+	}
+
+	query := repository.ObservationsQuery{
+		StationIDs: inp.StationIDs,
+		From:       inp.From,
+		To:         inp.To,
+		Fields:     withDerivedSources(inp.Fields, inp.Computed),
+		Descending: inp.Descending,
+		Limit:      inp.Limit,
+	}
+
+	if inp.Cursor != "" {
+		cursor, err := decodeObservationsCursor(inp.Cursor)
+		if err != nil {
+			return ObservationsPage{}, ErrInvalidCursor
+		}
+
+		query.After = &cursor
+	}
+
+	observations, err := s.repo.Find(ctx, query)
+	if err != nil {
+		return ObservationsPage{}, err
+	}
+
+	// filtered before computing, so derived quantities are based on accepted values only
+	if len(inp.Quality) > 0 {
+		filterQuality(observations, inp.Quality)
+	}
+
+	if len(inp.Computed) > 0 {
+		if err := s.computeDerived(ctx, observations, inp.Fields, inp.Computed); err != nil {
+			return ObservationsPage{}, err
+		}
+	}
+
+	page := ObservationsPage{Observations: observations}
+	if int64(len(observations)) == inp.Limit {
+		last := observations[len(observations)-1]
+		page.NextCursor = encodeObservationsCursor(repository.ObservationsCursor{Timestamp: last.Timestamp, ID: last.ID})
+	}
+
+	return page, nil
+}
+
+// computeDerived computes requested quantities and removes source fields the client didn't ask for
+func (s *ObservationsService) computeDerived(ctx context.Context, observations []domain.Observation, fields, derived []string) error {
+	elevations, err := stationElevations(ctx, s.stationsRepo, observations)
+	if err != nil {
+		return err
+	}
+
+	for i := range observations {
+		computeDerived(&observations[i], derived, elevations[observations[i].StationID])
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		for _, field := range domain.ObservationFields {
+			if !containsString(fields, field) {
+				observations[i].SetValue(field, nil)
+			}
+		}
+	}
+
+	return nil
+}
+
+// filterQuality unsets values flagged other than the given flags. Observations themselves are kept,
+// so cursor pagination isn't affected
+func filterQuality(observations []domain.Observation, flags []string) {
+	for i := range observations {
+		filterObservationQuality(&observations[i], flags)
+	}
+}
+
+func filterObservationQuality(observation *domain.Observation, flags []string) {
+	for _, field := range domain.ObservationFields {
+		if observation.Value(field) != nil && !containsString(flags, observation.Quality(field)) {
+			observation.SetValue(field, nil)
+			delete(observation.QC, field)
+		}
+	}
+}
+
+func encodeObservationsCursor(cursor repository.ObservationsCursor) string {
+	raw := fmt.Sprintf("%d:%s", cursor.Timestamp.UnixNano(), cursor.ID.Hex())
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeObservationsCursor(cursor string) (repository.ObservationsCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return repository.ObservationsCursor{}, err
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return repository.ObservationsCursor{}, ErrInvalidCursor
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return repository.ObservationsCursor{}, err
+	}
+
+	id, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		return repository.ObservationsCursor{}, err
+	}
+
+	return repository.ObservationsCursor{Timestamp: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/config"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"math"
+	"time"
+)
+
+const (
+	ForecastSourceGridPoint = "gridPoint"
+	ForecastSourceStation   = "station"
+
+	defaultForecastHours = 48
+	minBiasSamples       = 3
+	earthRadius          = 6371.0
+)
+
+// biasFields are corrected by mean observed minus forecast difference
+var biasFields = []string{domain.FieldTemperature, domain.FieldHumidity, domain.FieldPressure, domain.FieldWindSpeed}
+
+type PointForecastsService struct {
+	runsRepo         repository.ModelRuns
+	fieldsRepo       repository.ModelFields
+	stationsRepo     repository.Stations
+	observationsRepo repository.Observations
+
+	config config.ForecastConfig
+}
+
+func NewPointForecastsService(runsRepo repository.ModelRuns, fieldsRepo repository.ModelFields, stationsRepo repository.Stations,
+	observationsRepo repository.Observations, config config.ForecastConfig) *PointForecastsService {
+	return &PointForecastsService{
+		runsRepo:         runsRepo,
+		fieldsRepo:       fieldsRepo,
+		stationsRepo:     stationsRepo,
+		observationsRepo: observationsRepo,
+		config:           config,
+	}
+}
+
+// Get returns hourly forecast for the next hours starting with the current one. Values are taken at the station
+// close to the point or the nearest grid point and corrected by recent forecast bias at the nearest station
+func (s *PointForecastsService) Get(ctx context.Context, inp PointForecastInput) (PointForecast, error) {
+	if inp.Hours <= 0 {
+		inp.Hours = defaultForecastHours
+	}
+
+	if inp.Hours > s.config.MaxHours {
+		return PointForecast{}, ErrTooManyForecastHours
+	}
+
+	if inp.Model == "" {
+		inp.Model = s.config.Model
+	}
+
+	now := time.Now().UTC()
+	from := now.Truncate(time.Hour)
+	to := from.Add(time.Duration(inp.Hours) * time.Hour)
+
+	run, err := s.runsRepo.GetLatest(ctx, inp.Model, from)
+	if err != nil {
+		if err == repository.ErrModelRunNotFound {
+			return PointForecast{}, ErrModelRunNotFound
+		}
+
+		return PointForecast{}, err
+	}
+
+	station, distance, err := s.nearestStation(ctx, inp.Latitude, inp.Longitude)
+	if err != nil {
+		return PointForecast{}, err
+	}
+
+	point := &modelPoint{latitude: inp.Latitude, longitude: inp.Longitude, nearest: true}
+	source := ForecastSource{Type: ForecastSourceGridPoint}
+
+	if station != nil && distance <= s.config.StationRadius {
+		point = &modelPoint{latitude: station.Latitude, longitude: station.Longitude}
+		source = ForecastSource{Type: ForecastSourceStation, StationID: station.Identifier()}
+	}
+
+	// steps preceding the first hour are needed to interpolate it
+	forecasts, err := interpolateModelRun(ctx, s.fieldsRepo, run, point, run.RunTime, to)
+	if err != nil {
+		return PointForecast{}, err
+	}
+
+	source.Latitude, source.Longitude = point.latitude, point.longitude
+	source.Distance = haversine(inp.Latitude, inp.Longitude, point.latitude, point.longitude)
+
+	res := PointForecast{
+		Latitude:  inp.Latitude,
+		Longitude: inp.Longitude,
+		Source:    source,
+		Run: ForecastRun{
+			Model:    run.Model,
+			RunTime:  run.RunTime,
+			LoadedAt: run.LoadedAt,
+			AgeHours: now.Sub(run.RunTime).Hours(),
+		},
+		Hourly: hourlyForecasts(forecasts, from, to),
+	}
+
+	if station != nil && distance <= s.config.BiasRadius {
+		bias, err := s.bias(ctx, inp.Model, *station, now)
+		if err != nil {
+			return PointForecast{}, err
+		}
+
+		bias.Distance = distance
+		correctBias(res.Hourly, bias.Corrections)
+		res.Bias = &bias
+	}
+
+	return res, nil
+}
+
+// bias averages observed minus forecast differences at the station during the last hours.
+// Observations flagged by quality control are ignored, fields with few samples aren't corrected
+func (s *PointForecastsService) bias(ctx context.Context, model string, station domain.Station, now time.Time) (ForecastBias, error) {
+	bias := ForecastBias{StationID: station.Identifier(), Hours: s.config.BiasHours, Corrections: make(map[string]BiasCorrection)}
+
+	from := now.Add(-time.Duration(s.config.BiasHours) * time.Hour)
+
+	run, err := s.runsRepo.GetLatest(ctx, model, from)
+	if err != nil {
+		if err == repository.ErrModelRunNotFound {
+			return bias, nil
+		}
+
+		return bias, err
+	}
+
+	forecasts, err := interpolateModelRun(ctx, s.fieldsRepo, run, &modelPoint{latitude: station.Latitude, longitude: station.Longitude},
+		run.RunTime, now)
+	if err != nil {
+		if err == ErrOutsideModelGrid {
+			return bias, nil
+		}
+
+		return bias, err
+	}
+
+	hourly := make(map[int64]domain.ModelForecast)
+	for _, forecast := range hourlyForecasts(forecasts, from.Truncate(time.Hour), now) {
+		hourly[forecast.ValidTime.Unix()] = forecast
+	}
+
+	observations, err := s.observationsRepo.Find(ctx, repository.ObservationsQuery{
+		StationIDs: station.Identifiers(),
+		From:       from,
+		To:         now,
+	})
+	if err != nil {
+		return bias, err
+	}
+
+	sums := make(map[string]float64)
+	for _, observation := range observations {
+		forecast, ok := hourly[observation.Timestamp.Round(time.Hour).Unix()]
+		if !ok {
+			continue
+		}
+
+		for _, field := range biasFields {
+			observed, predicted := observation.Value(field), forecast.Value(field)
+			if observed == nil || predicted == nil || observation.Quality(field) == domain.QualitySuspect ||
+				observation.Quality(field) == domain.QualityBad {
+				continue
+			}
+
+			sums[field] += *observed - *predicted
+
+			correction := bias.Corrections[field]
+			correction.Samples++
+			bias.Corrections[field] = correction
+		}
+	}
+
+	for field, correction := range bias.Corrections {
+		if correction.Samples < minBiasSamples {
+			delete(bias.Corrections, field)
+			continue
+		}
+
+		correction.Value = sums[field] / float64(correction.Samples)
+		bias.Corrections[field] = correction
+	}
+
+	return bias, nil
+}
+
+// nearestStation returns the nearest active station and distance to it in km, nil if there are no stations
+func (s *PointForecastsService) nearestStation(ctx context.Context, lat, lon float64) (*domain.Station, float64, error) {
+	stations, err := s.stationsRepo.GetNear(ctx, repository.StationsNearQuery{
+		Latitude:  lat,
+		Longitude: lon,
+		Statuses:  []string{domain.StationStatusActive},
+		Limit:     1,
+	})
+	if err != nil || len(stations) == 0 {
+		return nil, 0, err
+	}
+
+	return &stations[0].Station, haversine(lat, lon, stations[0].Latitude, stations[0].Longitude), nil
+}
+
+// hourlyForecasts interpolates forecasts linearly in time to every hour of [from, to] covered by forecasts.
+// Precipitation of the forecast step is spread evenly over its hours
+func hourlyForecasts(forecasts []domain.ModelForecast, from, to time.Time) []domain.ModelForecast {
+	res := make([]domain.ModelForecast, 0)
+	if len(forecasts) == 0 {
+		return res
+	}
+
+	k := 0
+	for t := from; !t.After(to); t = t.Add(time.Hour) {
+		if t.Before(forecasts[0].ValidTime) || t.After(forecasts[len(forecasts)-1].ValidTime) {
+			continue
+		}
+
+		for k < len(forecasts)-1 && forecasts[k+1].ValidTime.Before(t) {
+			k++
+		}
+
+		if forecasts[k].ValidTime.Equal(t) {
+			hour := forecasts[k]
+			hour.Precipitation = nil
+
+			if k > 0 {
+				hour.Precipitation = hourlyPrecipitation(forecasts[k-1], forecasts[k])
+			}
+
+			res = append(res, hour)
+
+			continue
+		}
+
+		prev, next := forecasts[k], forecasts[k+1]
+		w := float64(t.Sub(prev.ValidTime)) / float64(next.ValidTime.Sub(prev.ValidTime))
+
+		hour := domain.ModelForecast{ValidTime: t, Precipitation: hourlyPrecipitation(prev, next)}
+		for _, field := range []string{domain.FieldTemperature, domain.FieldHumidity, domain.FieldPressure,
+			domain.FieldWindSpeed, domain.FieldWindGust} {
+			a, b := prev.Value(field), next.Value(field)
+			if a != nil && b != nil {
+				v := *a + (*b-*a)*w
+				hour.SetValue(field, &v)
+			}
+		}
+
+		if prev.WindDirection != nil && next.WindDirection != nil {
+			// interpolate along the shorter arc
+			delta := math.Mod(*next.WindDirection-*prev.WindDirection+540, 360) - 180
+			direction := math.Mod(*prev.WindDirection+delta*w+360, 360)
+			hour.WindDirection = &direction
+		}
+
+		res = append(res, hour)
+	}
+
+	return res
+}
+
+func hourlyPrecipitation(prev, next domain.ModelForecast) *float64 {
+	if next.Precipitation == nil {
+		return nil
+	}
+
+	amount := *next.Precipitation / next.ValidTime.Sub(prev.ValidTime).Hours()
+
+	return &amount
+}
+
+func correctBias(forecasts []domain.ModelForecast, corrections map[string]BiasCorrection) {
+	for i := range forecasts {
+		for field, correction := range corrections {
+			v := forecasts[i].Value(field)
+			if v == nil {
+				continue
+			}
+
+			corrected := *v + correction.Value
+
+			switch field {
+			case domain.FieldHumidity:
+				corrected = math.Max(0, math.Min(100, corrected))
+			case domain.FieldWindSpeed:
+				corrected = math.Max(0, corrected)
+			}
+
+			forecasts[i].SetValue(field, &corrected)
+		}
+	}
+}
+
+// haversine returns great-circle distance between two points in km
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const radians = math.Pi / 180
+
+	dLat := (lat2 - lat1) * radians
+	dLon := (lon2 - lon1) * radians
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*radians)*math.Cos(lat2*radians)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
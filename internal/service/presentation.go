@@ -0,0 +1,179 @@
+package service
+
+import (
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/units"
+	"regexp"
+	"time"
+)
+
+var localeRegex = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// Presentation converts observations stored in metric units and UTC to user preferred units and time zone
+type Presentation struct {
+	Units     units.System
+	Location  *time.Location
+	converter units.Converter
+}
+
+// NewPresentation creates presentation from user preferences, non-empty unitSystem overrides preferred one
+func NewPresentation(preferences domain.Preferences, unitSystem string) (Presentation, error) {
+	if unitSystem == "" {
+		unitSystem = preferences.Units
+	}
+
+	if unitSystem == "" {
+		unitSystem = units.SystemMetric
+	}
+
+	system, ok := units.SystemByName(unitSystem)
+	if !ok {
+		return Presentation{}, ErrUnknownUnitSystem
+	}
+
+	location, err := time.LoadLocation(preferences.Timezone)
+	if err != nil {
+		return Presentation{}, ErrInvalidTimezone
+	}
+
+	return Presentation{
+		Units:     system,
+		Location:  location,
+		converter: units.NewConverter(units.Metric, system),
+	}, nil
+}
+
+func (p Presentation) Observation(observation *domain.Observation) {
+	observation.Timestamp = observation.Timestamp.In(p.Location)
+	observation.ReceivedAt = observation.ReceivedAt.In(p.Location)
+
+	if p.converter.Identity() {
+		return
+	}
+
+	for _, field := range domain.ObservationFields {
+		if value := observation.Value(field); value != nil {
+			converted := p.convert(field, *value)
+			observation.SetValue(field, &converted)
+		}
+	}
+
+	for name, value := range observation.Computed {
+		observation.Computed[name] = p.convert(name, value)
+	}
+}
+
+func (p Presentation) Observations(observations []domain.Observation) {
+	for i := range observations {
+		p.Observation(&observations[i])
+	}
+}
+
+func (p Presentation) Aggregates(aggregates []domain.ObservationAggregate) {
+	for i := range aggregates {
+		aggregates[i].Start = aggregates[i].Start.In(p.Location)
+
+		if p.converter.Identity() {
+			continue
+		}
+
+		for field, stats := range aggregates[i].Fields {
+			aggregates[i].Fields[field] = domain.FieldStats{
+				Min:  p.convertPtr(field, stats.Min),
+				Max:  p.convertPtr(field, stats.Max),
+				Mean: p.convertPtr(field, stats.Mean),
+				Sum:  p.convertPtr(field, stats.Sum),
+			}
+		}
+	}
+}
+
+// Normals converts normals, standard deviation is converted as a difference
+func (p Presentation) Normals(normals []domain.Normal) {
+	if p.converter.Identity() {
+		return
+	}
+
+	for i := range normals {
+		normals[i].Mean = p.convert(normals[i].Element, normals[i].Mean)
+		normals[i].StdDev = p.convertDifference(normals[i].Element, normals[i].StdDev)
+
+		for j := range normals[i].Percentiles {
+			normals[i].Percentiles[j] = p.convert(normals[i].Element, normals[i].Percentiles[j])
+		}
+	}
+}
+
+func (p Presentation) Anomalies(anomalies []Anomaly) {
+	for i := range anomalies {
+		anomalies[i].Start = anomalies[i].Start.In(p.Location)
+
+		if p.converter.Identity() {
+			continue
+		}
+
+		for element, anomaly := range anomalies[i].Elements {
+			anomalies[i].Elements[element] = ElementAnomaly{
+				Observed:   p.convert(element, anomaly.Observed),
+				Normal:     p.convert(element, anomaly.Normal),
+				Anomaly:    p.convertDifference(element, anomaly.Anomaly),
+				Percentile: anomaly.Percentile,
+			}
+		}
+	}
+}
+
+// convert converts value of observation field or derived quantity, dimensionless ones are returned as is
+func (p Presentation) convert(name string, value float64) float64 {
+	switch name {
+	case domain.FieldTemperature, domain.NormalTemperatureMin, domain.NormalTemperatureMax,
+		DerivedDewPoint, DerivedHeatIndex, DerivedWindChill, DerivedHumidex, DerivedApparentTemperature:
+		return p.converter.Temperature(value)
+	case domain.FieldPressure, domain.FieldStationPressure, DerivedSeaLevelPressure:
+		return p.converter.Pressure(value)
+	case domain.FieldWindSpeed, domain.FieldWindGust:
+		return p.converter.Speed(value)
+	case domain.FieldPrecipitation:
+		return p.converter.Precipitation(value)
+	default:
+		return value
+	}
+}
+
+// convertDifference converts difference of values, other units than temperature ones have no offset
+func (p Presentation) convertDifference(name string, value float64) float64 {
+	switch name {
+	case domain.FieldTemperature, domain.NormalTemperatureMin, domain.NormalTemperatureMax:
+		return p.converter.TemperatureDifference(value)
+	default:
+		return p.convert(name, value)
+	}
+}
+
+func (p Presentation) convertPtr(name string, value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+
+	converted := p.convert(name, *value)
+
+	return &converted
+}
+
+func validatePreferences(preferences domain.Preferences) error {
+	if preferences.Units != "" {
+		if _, ok := units.SystemByName(preferences.Units); !ok {
+			return ErrUnknownUnitSystem
+		}
+	}
+
+	if _, err := time.LoadLocation(preferences.Timezone); err != nil {
+		return ErrInvalidTimezone
+	}
+
+	if preferences.Locale != "" && !localeRegex.MatchString(preferences.Locale) {
+		return ErrInvalidLocale
+	}
+
+	return nil
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/meteo"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// step check compares value with the previous one reported no earlier than stepMaxGap
+	stepMaxGap = time.Hour
+	// persistence check flags values that didn't change during the window having at least persistenceMinCount reports
+	persistenceWindow   = 6 * time.Hour
+	persistenceMinCount = 6
+	// humidity above 100% is tolerated up to this value as sensor error, consistency check flags it anyway
+	maxHumidity = 105
+)
+
+// qualityLimit holds plausible range, maximum change between consecutive reports
+// and whether flat line is suspicious for the field. Zero step disables step check
+type qualityLimit struct {
+	min, max    float64
+	step        float64
+	persistence bool
+}
+
+var qualityLimits = map[string]qualityLimit{
+	domain.FieldTemperature: {min: -90, max: 60, step: 10, persistence: true},
+	domain.FieldHumidity:    {min: 0, max: maxHumidity, step: 50, persistence: true},
+	domain.FieldPressure:    {min: 500, max: 1100, step: 6, persistence: true},
+	// stations are up to 5 km high
+	domain.FieldStationPressure: {min: 500, max: 1100, step: 6, persistence: true},
+	domain.FieldWindSpeed:       {min: 0, max: 75, step: 25, persistence: true},
+	domain.FieldWindGust:        {min: 0, max: 115},
+	domain.FieldWindDirection:   {min: 0, max: 360},
+	domain.FieldPrecipitation:   {min: 0, max: 500},
+}
+
+type QualityControlService struct {
+	repo repository.Observations
+}
+
+func NewQualityControlService(repo repository.Observations) *QualityControlService {
+	return &QualityControlService{repo: repo}
+}
+
+// Check sets quality flags of every reported value. Range failures mark values bad,
+// step, persistence and consistency failures mark them suspect
+func (s *QualityControlService) Check(ctx context.Context, observation *domain.Observation) error {
+	history, err := s.repo.Find(ctx, repository.ObservationsQuery{
+		StationIDs: []string{observation.StationID},
+		From:       observation.Timestamp.Add(-persistenceWindow),
+		To:         observation.Timestamp,
+		Descending: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	checkQuality(observation, history)
+
+	return nil
+}
+
+// CheckBatch checks observations not stored yet, such as imported ones. History of each observation
+// consists of stored observations and earlier observations of the batch, they are checked in time order
+func (s *QualityControlService) CheckBatch(ctx context.Context, observations []domain.Observation) error {
+	byStation := make(map[string][]*domain.Observation)
+	for i := range observations {
+		byStation[observations[i].StationID] = append(byStation[observations[i].StationID], &observations[i])
+	}
+
+	for stationId, batch := range byStation {
+		sort.SliceStable(batch, func(i, j int) bool { return batch[i].Timestamp.Before(batch[j].Timestamp) })
+
+		stored, err := s.repo.Find(ctx, repository.ObservationsQuery{
+			StationIDs: []string{stationId},
+			From:       batch[0].Timestamp.Add(-persistenceWindow),
+			To:         batch[len(batch)-1].Timestamp,
+		})
+		if err != nil {
+			return err
+		}
+
+		known := make([]*domain.Observation, 0, len(stored)+len(batch))
+		for i := range stored {
+			known = append(known, &stored[i])
+		}
+
+		known = append(known, batch...)
+		sort.SliceStable(known, func(i, j int) bool { return known[i].Timestamp.Before(known[j].Timestamp) })
+
+		// known observations are in time order, so history of each is a window sliding along them
+		first, next := 0, 0
+		for _, observation := range batch {
+			for known[first].Timestamp.Before(observation.Timestamp.Add(-persistenceWindow)) {
+				first++
+			}
+
+			for next < len(known) && known[next].Timestamp.Before(observation.Timestamp) {
+				next++
+			}
+
+			history := make([]domain.Observation, 0, next-first)
+			for i := next - 1; i >= first; i-- {
+				history = append(history, *known[i])
+			}
+
+			checkQuality(observation, history)
+		}
+	}
+
+	return nil
+}
+
+// checkQuality sets quality flags of the observation, history holds previous observations of the station
+// within persistence window, the latest first
+func checkQuality(observation *domain.Observation, history []domain.Observation) {
+	failed := make(map[string][]string)
+	fail := func(field, check string) {
+		failed[field] = append(failed[field], check)
+	}
+
+	for field, limit := range qualityLimits {
+		value := observation.Value(field)
+		if value == nil {
+			continue
+		}
+
+		if *value < limit.min || *value > limit.max {
+			fail(field, domain.QualityCheckRange)
+			continue
+		}
+
+		if limit.step > 0 && failsStepCheck(field, *value, observation.Timestamp, limit.step, history) {
+			fail(field, domain.QualityCheckStep)
+		}
+
+		if limit.persistence && failsPersistenceCheck(field, *value, history) {
+			fail(field, domain.QualityCheckPersistence)
+		}
+	}
+
+	for _, field := range inconsistentFields(observation) {
+		fail(field, domain.QualityCheckConsistency)
+	}
+
+	observation.QC = make(map[string]domain.QualityFlag)
+	for _, field := range domain.ObservationFields {
+		if observation.Value(field) == nil {
+			continue
+		}
+
+		flag := domain.QualityFlag{Flag: domain.QualityGood, Checks: failed[field]}
+		for _, check := range flag.Checks {
+			if check == domain.QualityCheckRange {
+				flag.Flag = domain.QualityBad
+				break
+			}
+
+			flag.Flag = domain.QualitySuspect
+		}
+
+		observation.QC[field] = flag
+	}
+}
+
+func (s *QualityControlService) Override(ctx context.Context, inp OverrideQualityInput) error {
+	if !domain.IsObservationField(inp.Field) {
+		return ErrUnknownObservationField
+	}
+
+	if !domain.IsQualityFlag(inp.Flag) || inp.Flag == domain.QualityUnchecked {
+		return ErrUnknownQualityFlag
+	}
+
+	err := s.repo.SetQualityFlag(ctx, inp.ObservationID, inp.Field, domain.QualityFlag{Flag: inp.Flag, Manual: true})
+	if err == repository.ErrObservationNotFound {
+		return ErrObservationNotFound
+	}
+
+	return err
+}
+
+// failsStepCheck compares value with the latest previous good or unchecked one
+func failsStepCheck(field string, value float64, ts time.Time, step float64, history []domain.Observation) bool {
+	for _, previous := range history {
+		prev := previous.Value(field)
+		if prev == nil || previous.Quality(field) == domain.QualityBad || previous.Quality(field) == domain.QualitySuspect {
+			continue
+		}
+
+		if ts.Sub(previous.Timestamp) > stepMaxGap {
+			return false
+		}
+
+		return math.Abs(value-*prev) > step
+	}
+
+	return false
+}
+
+// failsPersistenceCheck reports whether the value hasn't changed during the whole window.
+// Calm wind and saturated air are legitimately steady, so they aren't flagged
+func failsPersistenceCheck(field string, value float64, history []domain.Observation) bool {
+	if (field == domain.FieldWindSpeed && value == 0) || (field == domain.FieldHumidity && value >= 100) {
+		return false
+	}
+
+	count := 0
+	for _, previous := range history {
+		prev := previous.Value(field)
+		if prev == nil {
+			continue
+		}
+
+		if *prev != value {
+			return false
+		}
+
+		count++
+	}
+
+	return count+1 >= persistenceMinCount
+}
+
+// inconsistentFields returns fields contradicting each other: dew point above temperature,
+// gust below mean wind speed and wind direction reported for calm
+func inconsistentFields(observation *domain.Observation) []string {
+	var fields []string
+
+	t, rh := observation.Temperature, observation.Humidity
+	if t != nil && rh != nil && *rh > 0 && meteo.DewPoint(*t, *rh) > *t {
+		fields = append(fields, domain.FieldTemperature, domain.FieldHumidity)
+	}
+
+	speed, gust := observation.WindSpeed, observation.WindGust
+	if speed != nil && gust != nil && *gust < *speed {
+		fields = append(fields, domain.FieldWindSpeed, domain.FieldWindGust)
+	}
+
+	if speed != nil && *speed == 0 && observation.WindDirection != nil && *observation.WindDirection != 0 {
+		fields = append(fields, domain.FieldWindDirection)
+	}
+
+	return fields
+}
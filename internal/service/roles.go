@@ -0,0 +1,62 @@
+package service
+
+import (
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/auth"
+)
+
+// TokenClaims embeds grants into access token claims, global roles go to Roles and station grants to Scopes
+func TokenClaims(subject string, grants []domain.Grant) auth.Claims {
+	claims := auth.Claims{Subject: subject}
+	for _, g := range grants {
+		if len(g.Stations) == 0 {
+			claims.Roles = append(claims.Roles, g.Role)
+			continue
+		}
+
+		if claims.Scopes == nil {
+			claims.Scopes = make(map[string][]string)
+		}
+
+		claims.Scopes[g.Role] = append(claims.Scopes[g.Role], g.Stations...)
+	}
+
+	return claims
+}
+
+// ClaimGrants restores grants from access token claims
+func ClaimGrants(claims auth.Claims) []domain.Grant {
+	grants := make([]domain.Grant, 0, len(claims.Roles)+len(claims.Scopes))
+	for _, role := range claims.Roles {
+		grants = append(grants, domain.Grant{Role: role})
+	}
+
+	for role, stations := range claims.Scopes {
+		grants = append(grants, domain.Grant{Role: role, Stations: stations})
+	}
+
+	return grants
+}
+
+// userGrants returns grants of the user, users registered before roles were introduced are viewers
+func userGrants(user domain.User) []domain.Grant {
+	if len(user.Grants) == 0 {
+		return []domain.Grant{{Role: domain.RoleViewer}}
+	}
+
+	return user.Grants
+}
+
+func validateGrants(grants []domain.Grant) error {
+	for _, g := range grants {
+		if !domain.IsRole(g.Role) {
+			return ErrUnknownRole
+		}
+
+		if g.Role == domain.RoleAdmin && len(g.Stations) > 0 {
+			return ErrInvalidGrant
+		}
+	}
+
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"gitlab.com/peleng-meteo/meteo-go/pkg/hash"
 	"gitlab.com/peleng-meteo/meteo-go/pkg/otp"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
 	"time"
 )
 
@@ -24,9 +25,18 @@ type SignUpInput struct {
 	Password string
 }
 
+// SignInInput holds credentials. UserAgent and IP describe device of the session, admins' sessions ignore them
 type SignInInput struct {
-	Email    string
-	Password string
+	Email     string
+	Password  string
+	UserAgent string
+	IP        string
+}
+
+type RefreshTokensInput struct {
+	Token     string
+	UserAgent string
+	IP        string
 }
 
 type Tokens struct {
@@ -37,14 +47,47 @@ type Tokens struct {
 type Users interface {
 	SignUp(ctx context.Context, input SignUpInput) error
 	SignIn(ctx context.Context, input SignInInput) (Tokens, error)
-	RefreshTokens(ctx context.Context, refreshToken string) (Tokens, error)
+	RefreshTokens(ctx context.Context, inp RefreshTokensInput) (Tokens, error)
+	GetSessions(ctx context.Context, userId, currentId primitive.ObjectID) ([]domain.UserSession, error)
+	DeleteSession(ctx context.Context, userId, id primitive.ObjectID) error
+	DeleteSessions(ctx context.Context, userId primitive.ObjectID) error
 	Verify(ctx context.Context, hash string) error
 	GetById(ctx context.Context, id primitive.ObjectID) (domain.User, error)
+	UpdatePreferences(ctx context.Context, inp UpdatePreferencesInput) (domain.Preferences, error)
+	GetPresentation(ctx context.Context, id primitive.ObjectID, unitSystem string) (Presentation, error)
+	SetGrants(ctx context.Context, id primitive.ObjectID, grants []domain.Grant) error
+}
+
+type UpdatePreferencesInput struct {
+	UserID   primitive.ObjectID
+	Units    *string
+	Timezone *string
+	Locale   *string
+}
+
+type CreateAdminInput struct {
+	Name       string
+	Email      string
+	Password   string
+	SuperAdmin bool
+}
+
+type UpdateAdminInput struct {
+	ID         primitive.ObjectID
+	Name       *string
+	Email      *string
+	Password   *string
+	SuperAdmin *bool
 }
 
 type Admins interface {
 	SignIn(ctx context.Context, input SignInInput) (Tokens, error)
 	RefreshTokens(ctx context.Context, refreshToken string) (Tokens, error)
+	Create(ctx context.Context, inp CreateAdminInput) (primitive.ObjectID, error)
+	GetAll(ctx context.Context) ([]domain.Admin, error)
+	GetById(ctx context.Context, id primitive.ObjectID) (domain.Admin, error)
+	Update(ctx context.Context, inp UpdateAdminInput) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
 }
 
 type SendVerificationEmailInput struct {
@@ -53,18 +96,466 @@ type SendVerificationEmailInput struct {
 	VerificationCode string
 }
 
+type SendAlertEmailInput struct {
+	Email string
+	Name  string
+	Rule  domain.AlertRule
+	Event domain.AlertEvent
+}
+
 type Emails interface {
 	AddToList(name, email string) error
 	SendVerificationEmail(input SendVerificationEmailInput) error
+	SendAlertEmail(input SendAlertEmailInput) error
+}
+
+type CreateStationInput struct {
+	WMO       string
+	ICAO      string
+	Name      string
+	Latitude  float64
+	Longitude float64
+	Elevation float64
+	Type      string
+	Sensors   []domain.StationSensor
+	Status    string
+
+	ReportInterval int64
+}
+
+type UpdateStationInput struct {
+	ID        primitive.ObjectID
+	WMO       *string
+	ICAO      *string
+	Name      *string
+	Latitude  *float64
+	Longitude *float64
+	Elevation *float64
+	Type      *string
+	Sensors   []domain.StationSensor
+	Status    *string
+
+	ReportInterval *int64
+}
+
+// StationsNearInput selects stations nearest to the point. Radius is in km, zero means unlimited.
+// Empty Types and Statuses match any
+type StationsNearInput struct {
+	Latitude  float64
+	Longitude float64
+	Radius    float64
+	Types     []string
+	Statuses  []string
+	Limit     int64
+}
+
+// StationsBoxInput selects stations within the bounding box.
+// MinLongitude greater than MaxLongitude means the box crosses the antimeridian
+type StationsBoxInput struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+	Types        []string
+	Statuses     []string
+	Limit        int64
+}
+
+type Stations interface {
+	Create(ctx context.Context, inp CreateStationInput) (primitive.ObjectID, error)
+	GetAll(ctx context.Context) ([]domain.Station, error)
+	GetById(ctx context.Context, id primitive.ObjectID) (domain.Station, error)
+	GetNear(ctx context.Context, inp StationsNearInput) ([]domain.StationDistance, error)
+	GetWithinBox(ctx context.Context, inp StationsBoxInput) ([]domain.Station, error)
+	Update(ctx context.Context, inp UpdateStationInput) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type ObservationsQueryInput struct {
+	StationIDs []string
+	From       time.Time
+	To         time.Time
+	Fields     []string
+	Computed   []string
+	Quality    []string
+	Cursor     string
+	Descending bool
+	Limit      int64
+}
+
+type ObservationsPage struct {
+	Observations []domain.Observation
+	NextCursor   string
+}
+
+type AggregateObservationsInput struct {
+	StationIDs []string
+	From       time.Time
+	To         time.Time
+	Fields     []string
+	Interval   string
+}
+
+type Observations interface {
+	Find(ctx context.Context, inp ObservationsQueryInput) (ObservationsPage, error)
+	Aggregate(ctx context.Context, inp AggregateObservationsInput) ([]domain.ObservationAggregate, error)
+}
+
+type OverrideQualityInput struct {
+	ObservationID primitive.ObjectID
+	Field         string
+	Flag          string
+}
+
+type QualityControl interface {
+	Check(ctx context.Context, observation *domain.Observation) error
+	CheckBatch(ctx context.Context, observations []domain.Observation) error
+	Override(ctx context.Context, inp OverrideQualityInput) error
+}
+
+type TerminalForecasts interface {
+	Create(ctx context.Context, raw string) (primitive.ObjectID, error)
+	GetInEffect(ctx context.Context, stationId string, at time.Time) (domain.ForecastInEffect, error)
+}
+
+// ImportInput describes import file. Mapping maps observation fields (stationId, timestamp, temperature etc.)
+// to CSV columns or NDJSON keys, StationID is used for rows without station.
+// TimeFormat is either unix, unixms or Go time layout, RFC3339 by default
+type ImportInput struct {
+	Format     string
+	Mapping    map[string]string
+	StationID  string
+	TimeFormat string
+	BatchSize  int
+}
+
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type ImportReport struct {
+	Total      int              `json:"total"`
+	Inserted   int              `json:"inserted"`
+	Duplicates int              `json:"duplicates"`
+	Failed     int              `json:"failed"`
+	Errors     []ImportRowError `json:"errors"`
+}
+
+type Import interface {
+	Import(ctx context.Context, r io.Reader, inp ImportInput) (ImportReport, error)
+}
+
+// ExportInput filters exported values by Quality flags like ObservationsQueryInput,
+// values flagged bad are left out if Quality is empty
+type ExportInput struct {
+	StationID string
+	From      time.Time
+	To        time.Time
+	Fields    []string
+	Quality   []string
+	Format    string
+}
+
+type Export interface {
+	Export(ctx context.Context, w io.Writer, inp ExportInput) error
+}
+
+type CreateAlertRuleInput struct {
+	UserID     primitive.ObjectID
+	Name       string
+	StationID  string
+	Field      string
+	Operator   string
+	Threshold  float64
+	Hysteresis float64
+	ForSeconds int64
+}
+
+type UpdateAlertRuleInput struct {
+	ID         primitive.ObjectID
+	UserID     primitive.ObjectID
+	Name       *string
+	Operator   *string
+	Threshold  *float64
+	Hysteresis *float64
+	ForSeconds *int64
+	Enabled    *bool
+}
+
+type Alerts interface {
+	Create(ctx context.Context, inp CreateAlertRuleInput) (primitive.ObjectID, error)
+	GetByUser(ctx context.Context, userId primitive.ObjectID) ([]domain.AlertRule, error)
+	GetById(ctx context.Context, userId, id primitive.ObjectID) (domain.AlertRule, error)
+	Update(ctx context.Context, inp UpdateAlertRuleInput) error
+	Delete(ctx context.Context, userId, id primitive.ObjectID) error
+	GetEvents(ctx context.Context, userId primitive.ObjectID, limit int64) ([]domain.AlertEvent, error)
+	Evaluate(ctx context.Context, observation domain.Observation) error
+	Run(ctx context.Context)
+}
+
+type CreateWebhookInput struct {
+	UserID     primitive.ObjectID
+	URL        string
+	Events     []string
+	StationIDs []string
+}
+
+type UpdateWebhookInput struct {
+	ID         primitive.ObjectID
+	UserID     primitive.ObjectID
+	URL        *string
+	Events     []string
+	StationIDs []string
+	Enabled    *bool
+}
+
+// WebhookEvent is delivered to webhooks subscribed to its Type and StationID.
+// Events with UserID set are delivered only to webhooks of this user
+type WebhookEvent struct {
+	Type      string
+	StationID string
+	UserID    *primitive.ObjectID
+	Data      interface{}
+}
+
+type Webhooks interface {
+	Create(ctx context.Context, inp CreateWebhookInput) (domain.Webhook, error)
+	GetByUser(ctx context.Context, userId primitive.ObjectID) ([]domain.Webhook, error)
+	GetById(ctx context.Context, userId, id primitive.ObjectID) (domain.Webhook, error)
+	Update(ctx context.Context, inp UpdateWebhookInput) error
+	Delete(ctx context.Context, userId, id primitive.ObjectID) error
+	GetDeliveries(ctx context.Context, userId, id primitive.ObjectID, limit int64) ([]domain.WebhookDelivery, error)
+	Ping(ctx context.Context, userId, id primitive.ObjectID) error
+	Dispatch(ctx context.Context, event WebhookEvent) error
+	Run(ctx context.Context)
+}
+
+type StationUptimeInput struct {
+	From time.Time
+	To   time.Time
+}
+
+// StationUptime holds percentages of the window the station spent in each health state
+type StationUptime struct {
+	StationID  primitive.ObjectID `json:"stationId"`
+	Identifier string             `json:"identifier"`
+	Name       string             `json:"name"`
+	State      string             `json:"state"`
+	Uptime     float64            `json:"uptime"`
+	Online     float64            `json:"online"`
+	Late       float64            `json:"late"`
+	Offline    float64            `json:"offline"`
+	Unknown    float64            `json:"unknown"`
+}
+
+type StationHealth interface {
+	Check(ctx context.Context) error
+	Uptime(ctx context.Context, inp StationUptimeInput) ([]StationUptime, error)
+	Run(ctx context.Context)
+}
+
+type LoadModelInput struct {
+	Model string
+	File  string
+}
+
+// ModelLoadReport counts stored and skipped fields of the file, Errors lists messages that couldn't be decoded
+type ModelLoadReport struct {
+	File    string   `json:"file"`
+	Fields  int      `json:"fields"`
+	Skipped int      `json:"skipped"`
+	Errors  []string `json:"errors"`
+}
+
+type PointModelForecastInput struct {
+	Model     string
+	Latitude  float64
+	Longitude float64
+	From      time.Time
+	To        time.Time
+}
+
+type StationModelForecastInput struct {
+	Model     string
+	StationID string
+	From      time.Time
+	To        time.Time
+}
+
+type PointModelForecast struct {
+	Model     string                 `json:"model"`
+	RunTime   time.Time              `json:"runTime"`
+	LoadedAt  time.Time              `json:"loadedAt"`
+	Latitude  float64                `json:"latitude"`
+	Longitude float64                `json:"longitude"`
+	StationID string                 `json:"stationId,omitempty"`
+	Forecasts []domain.ModelForecast `json:"forecasts"`
+}
+
+type Models interface {
+	Load(ctx context.Context, r io.Reader, inp LoadModelInput) (ModelLoadReport, error)
+	AtPoint(ctx context.Context, inp PointModelForecastInput) (PointModelForecast, error)
+	AtStation(ctx context.Context, inp StationModelForecastInput) (PointModelForecast, error)
+}
+
+type PointForecastInput struct {
+	Latitude  float64
+	Longitude float64
+	Hours     int
+	Model     string
+}
+
+// ForecastSource is the station or the grid point forecast values are taken at, Distance is in km from the requested point
+type ForecastSource struct {
+	Type      string  `json:"type"`
+	StationID string  `json:"stationId,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Distance  float64 `json:"distance"`
+}
+
+type ForecastRun struct {
+	Model    string    `json:"model"`
+	RunTime  time.Time `json:"runTime"`
+	LoadedAt time.Time `json:"loadedAt"`
+	AgeHours float64   `json:"ageHours"`
+}
+
+// ForecastBias holds corrections added to forecast values, Distance is in km from the requested point to the station
+type ForecastBias struct {
+	StationID   string                    `json:"stationId"`
+	Distance    float64                   `json:"distance"`
+	Hours       int                       `json:"hours"`
+	Corrections map[string]BiasCorrection `json:"corrections"`
+}
+
+type BiasCorrection struct {
+	Value   float64 `json:"value"`
+	Samples int     `json:"samples"`
+}
+
+type PointForecast struct {
+	Latitude  float64                `json:"latitude"`
+	Longitude float64                `json:"longitude"`
+	Source    ForecastSource         `json:"source"`
+	Run       ForecastRun            `json:"run"`
+	Bias      *ForecastBias          `json:"bias,omitempty"`
+	Hourly    []domain.ModelForecast `json:"hourly"`
+}
+
+type PointForecasts interface {
+	Get(ctx context.Context, inp PointForecastInput) (PointForecast, error)
+}
+
+// NormalsInput selects normals of the station. Zero Month or Day and empty Elements match any
+type NormalsInput struct {
+	StationID string
+	Period    string
+	Elements  []string
+	Month     int
+	Day       int
+}
+
+// AnomaliesInput selects UTC days or calendar months of [From, To) compared with normals of the Period.
+// Today and the current month by default
+type AnomaliesInput struct {
+	StationID string
+	Period    string
+	Elements  []string
+	From      time.Time
+	To        time.Time
+}
+
+// Anomaly compares values observed in the day or month starting at Start with normals
+type Anomaly struct {
+	Start    time.Time                 `json:"start"`
+	Elements map[string]ElementAnomaly `json:"elements"`
+}
+
+// ElementAnomaly holds difference of the observed value from the normal mean
+// and percentile rank of the observed value among values of the reference period
+type ElementAnomaly struct {
+	Observed   float64 `json:"observed"`
+	Normal     float64 `json:"normal"`
+	Anomaly    float64 `json:"anomaly"`
+	Percentile float64 `json:"percentile"`
+}
+
+// RecomputeNormalsInput selects stations and reference period of recomputed normals.
+// All stations and configured number of years preceding the current one by default
+type RecomputeNormalsInput struct {
+	StationIDs []string
+	FromYear   int
+	ToYear     int
+}
+
+type Climate interface {
+	Run(ctx context.Context)
+	Normals(ctx context.Context, inp NormalsInput) ([]domain.Normal, error)
+	Anomalies(ctx context.Context, inp AnomaliesInput) ([]Anomaly, error)
+	Recompute(ctx context.Context, inp RecomputeNormalsInput) (primitive.ObjectID, error)
+	GetJob(ctx context.Context, id primitive.ObjectID) (domain.NormalsJob, error)
+}
+
+type StreamSubscriptionInput struct {
+	StationIDs []string
+	Fields     []string
+}
+
+type Stream interface {
+	Subscribe(inp StreamSubscriptionInput) (*ObservationsSubscription, error)
+	Publish(observation domain.Observation)
+}
+
+type Ingestion interface {
+	Run(ctx context.Context)
+}
+
+type CreateAPIKeyInput struct {
+	Owner      domain.APIKeyOwner
+	Name       string
+	Scopes     []string
+	AllowedIPs []string
+	ExpiresAt  *time.Time
+}
+
+// APIKeyIdentity is the key request was authenticated with and current grants of its owner
+type APIKeyIdentity struct {
+	Key    domain.APIKey
+	Grants []domain.Grant
+}
+
+type APIKeys interface {
+	Create(ctx context.Context, inp CreateAPIKeyInput) (domain.APIKey, string, error)
+	GetByOwner(ctx context.Context, owner domain.APIKeyOwner) ([]domain.APIKey, error)
+	Delete(ctx context.Context, owner domain.APIKeyOwner, id primitive.ObjectID) error
+	Authenticate(ctx context.Context, secret, ip string) (APIKeyIdentity, error)
 }
 
 type Services struct {
-	Users  Users
-	Admins Admins
+	Users             Users
+	Admins            Admins
+	APIKeys           APIKeys
+	Stations          Stations
+	StationHealth     StationHealth
+	Observations      Observations
+	QualityControl    QualityControl
+	TerminalForecasts TerminalForecasts
+	Models            Models
+	PointForecasts    PointForecasts
+	Climate           Climate
+	Import            Import
+	Export            Export
+	Alerts            Alerts
+	Webhooks          Webhooks
+	Stream            Stream
+	Ingestion         Ingestion
 }
 
 type Deps struct {
 	Repos                  *repository.Repositories
+	Sensors                repository.Sensors
 	Cache                  cache.Cache
 	Hasher                 hash.PasswordHasher
 	TokenManager           auth.TokenManager
@@ -78,14 +569,40 @@ type Deps struct {
 	VerificationCodeLength int
 	FrontendURL            string
 	Environment            string
+	IngestionRetryInterval time.Duration
+	WebhooksConfig         config.WebhooksConfig
+	AlertsQueueSize        int
+	StreamBufferSize       int
+	HealthConfig           config.HealthConfig
+	ForecastConfig         config.ForecastConfig
+	ClimateConfig          config.ClimateConfig
 }
 
 func NewServices(deps Deps) *Services {
 	emailsService := NewEmailsService(deps.EmailProvider, deps.EmailSender, deps.EmailConfig, deps.FrontendURL)
-	usersService := NewUsersService(deps.Repos.Users, deps.Hasher, deps.TokenManager, emailsService, deps.AccessTokenTTL, deps.RefreshTokenTTL, deps.OtpGenerator, deps.VerificationCodeLength)
+	usersService := NewUsersService(deps.Repos.Users, deps.Repos.UserSessions, deps.Hasher, deps.TokenManager, emailsService, deps.AccessTokenTTL, deps.RefreshTokenTTL, deps.OtpGenerator, deps.VerificationCodeLength)
+	webhooksService := NewWebhooksService(deps.Repos.Webhooks, deps.Repos.WebhookDeliveries, deps.WebhooksConfig, deps.Environment)
+	streamService := NewStreamService(deps.StreamBufferSize)
+	qualityControlService := NewQualityControlService(deps.Repos.Observations)
+	alertsService := NewAlertsService(deps.Repos.AlertRules, deps.Repos.AlertEvents, deps.Repos.Users, emailsService, webhooksService, deps.AlertsQueueSize)
 
 	return &Services{
-		Users:  usersService,
-		Admins: NewAdminsService(deps.Hasher, deps.TokenManager, deps.Repos.Admins, deps.AccessTokenTTL, deps.RefreshTokenTTL),
+		Users:             usersService,
+		Admins:            NewAdminsService(deps.Hasher, deps.TokenManager, deps.Repos.Admins, deps.AccessTokenTTL, deps.RefreshTokenTTL),
+		APIKeys:           NewAPIKeysService(deps.Repos.APIKeys, deps.Repos.Users, deps.Repos.Admins),
+		Stations:          NewStationsService(deps.Repos.Stations),
+		StationHealth:     NewStationHealthService(deps.Repos.Stations, deps.Repos.Observations, deps.Repos.StationHealthEvents, webhooksService, deps.HealthConfig),
+		Observations:      NewObservationsService(deps.Repos.Observations, deps.Repos.Stations),
+		QualityControl:    qualityControlService,
+		TerminalForecasts: NewTerminalForecastsService(deps.Repos.TerminalForecasts),
+		Models:            NewModelsService(deps.Repos.ModelRuns, deps.Repos.ModelFields, deps.Repos.Stations),
+		PointForecasts:    NewPointForecastsService(deps.Repos.ModelRuns, deps.Repos.ModelFields, deps.Repos.Stations, deps.Repos.Observations, deps.ForecastConfig),
+		Climate:           NewClimateService(deps.Repos.Normals, deps.Repos.NormalsJobs, deps.Repos.Observations, deps.Repos.Stations, deps.ClimateConfig),
+		Import:            NewImportService(deps.Repos.Observations, qualityControlService),
+		Export:            NewExportService(deps.Repos.Observations, deps.Repos.Stations),
+		Alerts:            alertsService,
+		Webhooks:          webhooksService,
+		Stream:            streamService,
+		Ingestion:         NewIngestionService(deps.Sensors, deps.Repos.Observations, qualityControlService, alertsService, webhooksService, streamService, deps.IngestionRetryInterval),
 	}
 }
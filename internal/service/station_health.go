@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/config"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/logger"
+	"time"
+)
+
+const defaultUptimeWindow = 7 * 24 * time.Hour
+
+var stationHealthWebhookEvents = map[string]string{
+	domain.StationHealthOnline:  domain.WebhookEventStationOnline,
+	domain.StationHealthLate:    domain.WebhookEventStationLate,
+	domain.StationHealthOffline: domain.WebhookEventStationOffline,
+}
+
+type StationHealthService struct {
+	stationsRepo     repository.Stations
+	observationsRepo repository.Observations
+	eventsRepo       repository.StationHealthEvents
+	webhooks         Webhooks
+
+	config config.HealthConfig
+}
+
+func NewStationHealthService(stationsRepo repository.Stations, observationsRepo repository.Observations,
+	eventsRepo repository.StationHealthEvents, webhooks Webhooks, config config.HealthConfig) *StationHealthService {
+	return &StationHealthService{
+		stationsRepo:     stationsRepo,
+		observationsRepo: observationsRepo,
+		eventsRepo:       eventsRepo,
+		webhooks:         webhooks,
+		config:           config,
+	}
+}
+
+// Run checks stations health every check interval until ctx is cancelled
+func (s *StationHealthService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Check(ctx); err != nil && ctx.Err() == nil {
+			logger.Errorf("failed to check stations health: %s", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check updates health of active stations by time of their latest observation.
+// Stations in maintenance or decommissioned aren't expected to report, so they keep the last state
+func (s *StationHealthService) Check(ctx context.Context) error {
+	stations, err := s.stationsRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, station := range stations {
+		if station.Status != domain.StationStatusActive {
+			continue
+		}
+
+		if err := s.checkStation(ctx, station, now); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			logger.Errorf("failed to check health of station %s: %s", station.Identifier(), err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (s *StationHealthService) checkStation(ctx context.Context, station domain.Station, now time.Time) error {
+	observations, err := s.observationsRepo.Find(ctx, repository.ObservationsQuery{
+		StationIDs: station.Identifiers(),
+		Descending: true,
+		Limit:      1,
+	})
+	if err != nil {
+		return err
+	}
+
+	health := station.Health
+	if len(observations) > 0 {
+		health.LastObservationAt = observations[0].Timestamp
+	}
+
+	health.State = s.state(station, health.LastObservationAt, now)
+	if health.State == station.Health.State && health.LastObservationAt.Equal(station.Health.LastObservationAt) {
+		return nil
+	}
+
+	if health.State == station.Health.State {
+		return s.stationsRepo.SetHealth(ctx, station.ID, health)
+	}
+
+	health.ChangedAt = now
+	if err := s.stationsRepo.SetHealth(ctx, station.ID, health); err != nil {
+		return err
+	}
+
+	event := domain.StationHealthEvent{
+		StationID:         station.ID,
+		Identifier:        station.Identifier(),
+		State:             health.State,
+		PreviousState:     station.Health.State,
+		LastObservationAt: health.LastObservationAt,
+		CreatedAt:         now,
+	}
+
+	if err := s.eventsRepo.Create(ctx, event); err != nil {
+		return err
+	}
+
+	// the first check of the station only establishes its state, there is no change to notify about
+	if event.PreviousState == "" || event.PreviousState == domain.StationHealthUnknown {
+		return nil
+	}
+
+	if err := s.webhooks.Dispatch(ctx, WebhookEvent{
+		Type:      stationHealthWebhookEvents[event.State],
+		StationID: event.Identifier,
+		Data:      event,
+	}); err != nil {
+		logger.Errorf("failed to dispatch station %s health webhooks: %s", event.Identifier, err.Error())
+	}
+
+	return nil
+}
+
+func (s *StationHealthService) state(station domain.Station, lastObservationAt, now time.Time) string {
+	if lastObservationAt.IsZero() {
+		return domain.StationHealthUnknown
+	}
+
+	interval := s.config.DefaultReportInterval
+	if station.ReportInterval > 0 {
+		interval = time.Duration(station.ReportInterval) * time.Second
+	}
+
+	silence := now.Sub(lastObservationAt)
+
+	switch {
+	case silence > time.Duration(float64(interval)*s.config.OfflineFactor):
+		return domain.StationHealthOffline
+	case silence > time.Duration(float64(interval)*s.config.LateFactor):
+		return domain.StationHealthLate
+	default:
+		return domain.StationHealthOnline
+	}
+}
+
+// Uptime returns share of the window every station spent in each health state. Time before the first
+// recorded health event is unknown. Uptime counts late stations as up, since they still report
+func (s *StationHealthService) Uptime(ctx context.Context, inp StationUptimeInput) ([]StationUptime, error) {
+	now := time.Now()
+
+	if inp.To.IsZero() || inp.To.After(now) {
+		inp.To = now
+	}
+
+	if inp.From.IsZero() {
+		inp.From = inp.To.Add(-defaultUptimeWindow)
+	}
+
+	if !inp.From.Before(inp.To) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	stations, err := s.stationsRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]StationUptime, 0, len(stations))
+	for _, station := range stations {
+		durations, err := s.stateDurations(ctx, station, inp.From, inp.To)
+		if err != nil {
+			return nil, err
+		}
+
+		window := inp.To.Sub(inp.From)
+		percent := func(states ...string) float64 {
+			var d time.Duration
+			for _, state := range states {
+				d += durations[state]
+			}
+
+			return float64(d) / float64(window) * 100
+		}
+
+		res = append(res, StationUptime{
+			StationID:  station.ID,
+			Identifier: station.Identifier(),
+			Name:       station.Name,
+			State:      station.Health.State,
+			Uptime:     percent(domain.StationHealthOnline, domain.StationHealthLate),
+			Online:     percent(domain.StationHealthOnline),
+			Late:       percent(domain.StationHealthLate),
+			Offline:    percent(domain.StationHealthOffline),
+			Unknown:    percent(domain.StationHealthUnknown),
+		})
+	}
+
+	return res, nil
+}
+
+// stateDurations replays station health events of the window starting with the state in effect at its beginning
+func (s *StationHealthService) stateDurations(ctx context.Context, station domain.Station, from, to time.Time) (map[string]time.Duration, error) {
+	state := domain.StationHealthUnknown
+
+	previous, err := s.eventsRepo.GetLatestBefore(ctx, station.ID, from)
+	switch err {
+	case nil:
+		state = previous.State
+	case repository.ErrStationHealthNotFound:
+	default:
+		return nil, err
+	}
+
+	events, err := s.eventsRepo.GetByStation(ctx, station.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	durations := make(map[string]time.Duration)
+	since := from
+
+	for _, event := range events {
+		durations[state] += event.CreatedAt.Sub(since)
+		state, since = event.State, event.CreatedAt
+	}
+
+	durations[state] += to.Sub(since)
+
+	return durations, nil
+}
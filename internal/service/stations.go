@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const (
+	defaultNearestStations = 10
+	defaultStationsLimit   = 100
+	maxStationsLimit       = 1000
+)
+
+type StationsService struct {
+	repo repository.Stations
+}
+
+func NewStationsService(repo repository.Stations) *StationsService {
+	return &StationsService{repo: repo}
+}
+
+func (s *StationsService) Create(ctx context.Context, inp CreateStationInput) (primitive.ObjectID, error) {
+	if inp.WMO == "" && inp.ICAO == "" {
+		return primitive.ObjectID{}, ErrStationIdentifierEmpty
+	}
+
+	if inp.Status == "" {
+		inp.Status = domain.StationStatusActive
+	}
+
+	if !isValidStationStatus(inp.Status) {
+		return primitive.ObjectID{}, ErrInvalidStationStatus
+	}
+
+	if inp.Type == "" {
+		inp.Type = defaultStationType(inp.WMO)
+	}
+
+	if !isValidStationType(inp.Type) {
+		return primitive.ObjectID{}, ErrInvalidStationType
+	}
+
+	if inp.Sensors == nil {
+		inp.Sensors = make([]domain.StationSensor, 0)
+	}
+
+	id, err := s.repo.Create(ctx, domain.Station{
+		WMO:       inp.WMO,
+		ICAO:      inp.ICAO,
+		Name:      inp.Name,
+		Latitude:  inp.Latitude,
+		Longitude: inp.Longitude,
+		Elevation: inp.Elevation,
+		Location:  domain.NewGeoPoint(inp.Latitude, inp.Longitude),
+		Type:      inp.Type,
+		Sensors:   inp.Sensors,
+		Status:    inp.Status,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+
+		ReportInterval: inp.ReportInterval,
+	})
+	if err != nil {
+		if err == repository.ErrStationAlreadyExists {
+			return primitive.ObjectID{}, ErrStationAlreadyExists
+		}
+
+		return primitive.ObjectID{}, err
+	}
+
+	return id, nil
+}
+
+func (s *StationsService) GetAll(ctx context.Context) ([]domain.Station, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *StationsService) GetById(ctx context.Context, id primitive.ObjectID) (domain.Station, error) {
+	station, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		if err == repository.ErrStationNotFound {
+			return domain.Station{}, ErrStationNotFound
+		}
+
+		return domain.Station{}, err
+	}
+
+	return station, nil
+}
+
+// GetNear returns stations ordered by distance to the point, nearest 10 ones by default
+func (s *StationsService) GetNear(ctx context.Context, inp StationsNearInput) ([]domain.StationDistance, error) {
+	if err := validateStationsFilter(inp.Types, inp.Statuses); err != nil {
+		return nil, err
+	}
+
+	if inp.Limit <= 0 {
+		inp.Limit = defaultNearestStations
+		if inp.Radius > 0 {
+			inp.Limit = defaultStationsLimit
+		}
+	}
+
+	stations, err := s.repo.GetNear(ctx, repository.StationsNearQuery{
+		Latitude:    inp.Latitude,
+		Longitude:   inp.Longitude,
+		MaxDistance: inp.Radius * 1000,
+		Types:       inp.Types,
+		Statuses:    inp.Statuses,
+		Limit:       limitStations(inp.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stations {
+		if stations[i].Distance != nil {
+			km := *stations[i].Distance / 1000
+			stations[i].Distance = &km
+		}
+	}
+
+	return stations, nil
+}
+
+func (s *StationsService) GetWithinBox(ctx context.Context, inp StationsBoxInput) ([]domain.Station, error) {
+	if err := validateStationsFilter(inp.Types, inp.Statuses); err != nil {
+		return nil, err
+	}
+
+	if inp.MinLatitude > inp.MaxLatitude || inp.MinLongitude == inp.MaxLongitude {
+		return nil, ErrInvalidBoundingBox
+	}
+
+	if inp.Limit <= 0 {
+		inp.Limit = defaultStationsLimit
+	}
+
+	return s.repo.GetWithinBox(ctx, repository.StationsBoxQuery{
+		MinLatitude:  inp.MinLatitude,
+		MinLongitude: inp.MinLongitude,
+		MaxLatitude:  inp.MaxLatitude,
+		MaxLongitude: inp.MaxLongitude,
+		Types:        inp.Types,
+		Statuses:     inp.Statuses,
+		Limit:        limitStations(inp.Limit),
+	})
+}
+
+func (s *StationsService) Update(ctx context.Context, inp UpdateStationInput) error {
+	if inp.Status != nil && !isValidStationStatus(*inp.Status) {
+		return ErrInvalidStationStatus
+	}
+
+	if inp.Type != nil && !isValidStationType(*inp.Type) {
+		return ErrInvalidStationType
+	}
+
+	partialLocation := (inp.Latitude == nil) != (inp.Longitude == nil)
+	if partialLocation || inp.WMO != nil || inp.ICAO != nil {
+		station, err := s.repo.GetById(ctx, inp.ID)
+		if err != nil {
+			if err == repository.ErrStationNotFound {
+				return ErrStationNotFound
+			}
+
+			return err
+		}
+
+		// location is stored as a whole, so the other coordinate is taken from the station
+		if partialLocation && inp.Latitude == nil {
+			inp.Latitude = &station.Latitude
+		} else if partialLocation {
+			inp.Longitude = &station.Longitude
+		}
+
+		if inp.WMO != nil {
+			station.WMO = *inp.WMO
+		}
+
+		if inp.ICAO != nil {
+			station.ICAO = *inp.ICAO
+		}
+
+		if station.WMO == "" && station.ICAO == "" {
+			return ErrStationIdentifierEmpty
+		}
+	}
+
+	err := s.repo.Update(ctx, repository.UpdateStationInput{
+		ID:        inp.ID,
+		WMO:       inp.WMO,
+		ICAO:      inp.ICAO,
+		Name:      inp.Name,
+		Latitude:  inp.Latitude,
+		Longitude: inp.Longitude,
+		Elevation: inp.Elevation,
+		Type:      inp.Type,
+		Sensors:   inp.Sensors,
+		Status:    inp.Status,
+
+		ReportInterval: inp.ReportInterval,
+	})
+
+	switch err {
+	case repository.ErrStationNotFound:
+		return ErrStationNotFound
+	case repository.ErrStationAlreadyExists:
+		return ErrStationAlreadyExists
+	default:
+		return err
+	}
+}
+
+func (s *StationsService) Delete(ctx context.Context, id primitive.ObjectID) error {
+	err := s.repo.Delete(ctx, id)
+	if err == repository.ErrStationNotFound {
+		return ErrStationNotFound
+	}
+
+	return err
+}
+
+func isValidStationStatus(status string) bool {
+	switch status {
+	case domain.StationStatusActive, domain.StationStatusMaintenance, domain.StationStatusDecommissioned:
+		return true
+	default:
+		return false
+	}
+}
+
+func isValidStationType(stationType string) bool {
+	switch stationType {
+	case domain.StationTypeSynoptic, domain.StationTypeAviation, domain.StationTypeAutomatic, domain.StationTypeClimate:
+		return true
+	default:
+		return false
+	}
+}
+
+// defaultStationType treats stations without WMO identifier as airfields
+func defaultStationType(wmo string) string {
+	if wmo == "" {
+		return domain.StationTypeAviation
+	}
+
+	return domain.StationTypeSynoptic
+}
+
+func validateStationsFilter(types, statuses []string) error {
+	for _, t := range types {
+		if !isValidStationType(t) {
+			return ErrInvalidStationType
+		}
+	}
+
+	for _, status := range statuses {
+		if !isValidStationStatus(status) {
+			return ErrInvalidStationStatus
+		}
+	}
+
+	return nil
+}
+
+func limitStations(limit int64) int64 {
+	if limit > maxStationsLimit {
+		return maxStationsLimit
+	}
+
+	return limit
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"sync"
+	"sync/atomic"
+)
+
+// StreamService is in-process pub/sub hub fanning out stored observations to live subscribers.
+// Publishing never blocks ingestion: observations for subscribers with full buffer are dropped and counted
+type StreamService struct {
+	mu          sync.RWMutex
+	subscribers map[*ObservationsSubscription]struct{}
+
+	bufferSize int
+}
+
+func NewStreamService(bufferSize int) *StreamService {
+	return &StreamService{
+		subscribers: make(map[*ObservationsSubscription]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+func (s *StreamService) Subscribe(inp StreamSubscriptionInput) (*ObservationsSubscription, error) {
+	filter, err := newStreamFilter(inp)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &ObservationsSubscription{
+		stream: s,
+		ch:     make(chan domain.Observation, s.bufferSize),
+		filter: filter,
+	}
+
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+
+	return sub, nil
+}
+
+func (s *StreamService) Publish(observation domain.Observation) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for sub := range s.subscribers {
+		sub.send(observation)
+	}
+}
+
+func (s *StreamService) unsubscribe(sub *ObservationsSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// channel is closed under the hub lock, so Publish can't send to it afterwards
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+// ObservationsSubscription receives observations of subscribed stations projected to subscribed fields
+type ObservationsSubscription struct {
+	stream  *StreamService
+	ch      chan domain.Observation
+	dropped uint64
+
+	mu     sync.RWMutex
+	filter streamFilter
+}
+
+// C returns channel of observations, it's closed by Close
+func (s *ObservationsSubscription) C() <-chan domain.Observation {
+	return s.ch
+}
+
+// Update replaces subscribed stations and fields
+func (s *ObservationsSubscription) Update(inp StreamSubscriptionInput) error {
+	filter, err := newStreamFilter(inp)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.filter = filter
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Dropped returns number of observations dropped since the previous call because subscriber was too slow
+func (s *ObservationsSubscription) Dropped() uint64 {
+	return atomic.SwapUint64(&s.dropped, 0)
+}
+
+func (s *ObservationsSubscription) Close() {
+	s.stream.unsubscribe(s)
+}
+
+func (s *ObservationsSubscription) send(observation domain.Observation) {
+	s.mu.RLock()
+	filter := s.filter
+	s.mu.RUnlock()
+
+	if !filter.matches(observation) {
+		return
+	}
+
+	select {
+	case s.ch <- filter.project(observation):
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+type streamFilter struct {
+	stations map[string]bool
+	fields   []string
+}
+
+func newStreamFilter(inp StreamSubscriptionInput) (streamFilter, error) {
+	for _, field := range inp.Fields {
+		if !domain.IsObservationField(field) {
+			return streamFilter{}, fmt.Errorf("%w: %s", ErrUnknownObservationField, field)
+		}
+	}
+
+	filter := streamFilter{fields: inp.Fields}
+	if len(inp.StationIDs) > 0 {
+		filter.stations = make(map[string]bool, len(inp.StationIDs))
+		for _, id := range inp.StationIDs {
+			filter.stations[id] = true
+		}
+	}
+
+	return filter, nil
+}
+
+// matches reports whether observation belongs to subscribed station, empty stations list matches every station
+func (f streamFilter) matches(observation domain.Observation) bool {
+	return f.stations == nil || f.stations[observation.StationID]
+}
+
+// project leaves only subscribed fields, all of them if fields weren't specified
+func (f streamFilter) project(observation domain.Observation) domain.Observation {
+	if len(f.fields) == 0 {
+		return observation
+	}
+
+	projected := domain.Observation{
+		ID:         observation.ID,
+		StationID:  observation.StationID,
+		Timestamp:  observation.Timestamp,
+		ReceivedAt: observation.ReceivedAt,
+	}
+
+	for _, field := range f.fields {
+		projected.SetValue(field, observation.Value(field))
+	}
+
+	return projected
+}
@@ -14,6 +14,7 @@ import (
 
 type UsersService struct {
 	repo           repository.Users
+	sessionsRepo   repository.UserSessions
 	hasher         hash.PasswordHasher
 	tokenManager   auth.TokenManager
 	otpGenenerator otp.Generator
@@ -25,9 +26,10 @@ type UsersService struct {
 	verificationCodeLength int
 }
 
-func NewUsersService(repo repository.Users, hasher hash.PasswordHasher, tokenManager auth.TokenManager, emailService Emails, accessTTL, refreshTTL time.Duration, otpGenerator otp.Generator, verificationCodeLength int) *UsersService {
+func NewUsersService(repo repository.Users, sessionsRepo repository.UserSessions, hasher hash.PasswordHasher, tokenManager auth.TokenManager, emailService Emails, accessTTL, refreshTTL time.Duration, otpGenerator otp.Generator, verificationCodeLength int) *UsersService {
 	return &UsersService{
 		repo:                   repo,
+		sessionsRepo:           sessionsRepo,
 		hasher:                 hasher,
 		emailService:           emailService,
 		tokenManager:           tokenManager,
@@ -56,6 +58,7 @@ func (s *UsersService) SignUp(ctx context.Context, input SignUpInput) error {
 		Verification: domain.Verification{
 			Code: verificationCode,
 		},
+		Grants: []domain.Grant{{Role: domain.RoleViewer}},
 	}
 
 	if err := s.repo.Create(ctx, user); err != nil {
@@ -81,28 +84,143 @@ func (s *UsersService) SignUp(ctx context.Context, input SignUpInput) error {
 }
 
 func (s *UsersService) SignIn(ctx context.Context, input SignInInput) (Tokens, error) {
-	passwordHash, err := s.hasher.Hash(input.Password)
+	user, err := s.repo.GetByEmail(ctx, input.Email)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			// hashing takes as long as verification, so response time doesn't tell whether the email is registered
+			_, _ = s.hasher.Hash(input.Password)
+			return Tokens{}, ErrUserNotFound
+		}
+		return Tokens{}, err
+	}
+
+	ok, err := s.hasher.Verify(user.Password, input.Password)
 	if err != nil {
 		return Tokens{}, err
 	}
-	user, err := s.repo.GetByCredentials(ctx, input.Email, passwordHash)
+
+	if !ok {
+		return Tokens{}, ErrUserNotFound
+	}
+
+	// hashes of legacy algorithm or outdated parameters are replaced while the password is known
+	if s.hasher.NeedsRehash(user.Password) {
+		if err := s.rehashPassword(ctx, user.ID, input.Password); err != nil {
+			logger.Errorf("failed to rehash password of user %s: %s", user.ID.Hex(), err.Error())
+		}
+	}
+
+	return s.createSession(ctx, user, input.UserAgent, input.IP)
+}
+
+func (s *UsersService) rehashPassword(ctx context.Context, id primitive.ObjectID, password string) error {
+	passwordHash, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SetPassword(ctx, id, passwordHash)
+}
+
+// RefreshTokens rotates refresh token of the session. Presenting a token that was already rotated
+// means it leaked, so the session is revoked
+func (s *UsersService) RefreshTokens(ctx context.Context, inp RefreshTokensInput) (Tokens, error) {
+	tokenHash := hashToken(inp.Token)
+
+	session, err := s.sessionsRepo.GetByRefreshToken(ctx, tokenHash)
+	if err != nil {
+		if err == repository.ErrSessionNotFound {
+			return Tokens{}, s.revokeReused(ctx, tokenHash)
+		}
+
+		return Tokens{}, err
+	}
+
+	user, err := s.repo.GetById(ctx, session.UserID)
 	if err != nil {
 		if err == repository.ErrUserNotFound {
-			return Tokens{}, ErrUserNotFound
+			return Tokens{}, ErrInvalidRefreshToken
+		}
+
+		return Tokens{}, err
+	}
+
+	res, err := s.newTokens(user, session.ID)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	now := time.Now()
+	if err := s.sessionsRepo.Rotate(ctx, repository.RotateSessionInput{
+		ID:              session.ID,
+		RefreshToken:    tokenHash,
+		NewRefreshToken: hashToken(res.RefreshToken),
+		UserAgent:       inp.UserAgent,
+		IP:              inp.IP,
+		UsedAt:          now,
+		ExpiresAt:       now.Add(s.refreshTokenTTL),
+	}); err != nil {
+		if err == repository.ErrSessionNotFound {
+			// the token was rotated by a concurrent refresh in between
+			return Tokens{}, s.revokeReused(ctx, tokenHash)
 		}
+
 		return Tokens{}, err
 	}
 
-	return s.createSession(ctx, user.ID)
+	return res, nil
 }
 
-func (s *UsersService) RefreshTokens(ctx context.Context, refreshToken string) (Tokens, error) {
-	user, err := s.repo.GetByRefreshToken(ctx, refreshToken)
+// revokeReused revokes session the refresh token was rotated out of
+func (s *UsersService) revokeReused(ctx context.Context, tokenHash string) error {
+	session, err := s.sessionsRepo.GetByRotatedToken(ctx, tokenHash)
 	if err != nil {
-		return Tokens{}, err
+		if err == repository.ErrSessionNotFound {
+			return ErrInvalidRefreshToken
+		}
+
+		return err
 	}
 
-	return s.createSession(ctx, user.ID)
+	logger.Warnf("refresh token of session %s of user %s was reused, revoking the session", session.ID.Hex(), session.UserID.Hex())
+
+	if err := s.sessionsRepo.Delete(ctx, session.UserID, session.ID); err != nil && err != repository.ErrSessionNotFound {
+		return err
+	}
+
+	return ErrRefreshTokenReused
+}
+
+// GetSessions returns active sessions of the user, the one with currentId is marked as current
+func (s *UsersService) GetSessions(ctx context.Context, userId, currentId primitive.ObjectID) ([]domain.UserSession, error) {
+	sessions, err := s.sessionsRepo.GetByUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentId
+	}
+
+	return sessions, nil
+}
+
+// DeleteSession signs the user out of the session, access tokens issued for it are valid until they expire
+func (s *UsersService) DeleteSession(ctx context.Context, userId, id primitive.ObjectID) error {
+	if err := s.sessionsRepo.Delete(ctx, userId, id); err != nil {
+		if err == repository.ErrSessionNotFound {
+			return ErrSessionNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// DeleteSessions signs the user out everywhere
+func (s *UsersService) DeleteSessions(ctx context.Context, userId primitive.ObjectID) error {
+	return s.sessionsRepo.DeleteByUser(ctx, userId)
 }
 
 func (s *UsersService) Verify(ctx context.Context, hash string) error {
@@ -122,27 +240,113 @@ func (s *UsersService) GetById(ctx context.Context, id primitive.ObjectID) (doma
 	return s.repo.GetById(ctx, id)
 }
 
-func (s *UsersService) createSession(ctx context.Context, studentId primitive.ObjectID) (Tokens, error) {
-	var (
-		res Tokens
-		err error
-	)
+func (s *UsersService) UpdatePreferences(ctx context.Context, inp UpdatePreferencesInput) (domain.Preferences, error) {
+	user, err := s.repo.GetById(ctx, inp.UserID)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return domain.Preferences{}, ErrUserNotFound
+		}
+
+		return domain.Preferences{}, err
+	}
+
+	preferences := user.Preferences
+	if inp.Units != nil {
+		preferences.Units = *inp.Units
+	}
+
+	if inp.Timezone != nil {
+		preferences.Timezone = *inp.Timezone
+	}
 
-	res.AccessToken, err = s.tokenManager.NewJWT(studentId.Hex(), s.accessTokenTTL)
+	if inp.Locale != nil {
+		preferences.Locale = *inp.Locale
+	}
+
+	if err := validatePreferences(preferences); err != nil {
+		return domain.Preferences{}, err
+	}
+
+	if err := s.repo.SetPreferences(ctx, inp.UserID, preferences); err != nil {
+		return domain.Preferences{}, err
+	}
+
+	return preferences, nil
+}
+
+// GetPresentation returns presentation of observations for the user, non-empty unitSystem overrides preferred units
+func (s *UsersService) GetPresentation(ctx context.Context, id primitive.ObjectID, unitSystem string) (Presentation, error) {
+	user, err := s.repo.GetById(ctx, id)
 	if err != nil {
-		return res, err
+		if err == repository.ErrUserNotFound {
+			return Presentation{}, ErrUserNotFound
+		}
+
+		return Presentation{}, err
 	}
 
-	res.RefreshToken, err = s.tokenManager.NewRefreshToken()
+	return NewPresentation(user.Preferences, unitSystem)
+}
+
+// SetGrants replaces roles of the user, they take effect once access token is refreshed
+func (s *UsersService) SetGrants(ctx context.Context, id primitive.ObjectID, grants []domain.Grant) error {
+	if err := validateGrants(grants); err != nil {
+		return err
+	}
+
+	if err := s.repo.SetGrants(ctx, id, grants); err != nil {
+		if err == repository.ErrUserNotFound {
+			return ErrUserNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *UsersService) createSession(ctx context.Context, user domain.User, userAgent, ip string) (Tokens, error) {
+	sessionId := primitive.NewObjectID()
+
+	res, err := s.newTokens(user, sessionId)
 	if err != nil {
 		return res, err
 	}
 
-	session := domain.Session{
-		RefreshToken: res.RefreshToken,
-		ExpiresAt:    time.Now().Add(s.refreshTokenTTL),
+	now := time.Now()
+	session := domain.UserSession{
+		ID:           sessionId,
+		UserID:       user.ID,
+		RefreshToken: hashToken(res.RefreshToken),
+		UserAgent:    userAgent,
+		IP:           ip,
+		CreatedAt:    now,
+		LastUsedAt:   now,
+		ExpiresAt:    now.Add(s.refreshTokenTTL),
+	}
+
+	if err := s.sessionsRepo.Create(ctx, session); err != nil {
+		return res, err
+	}
+
+	err = s.repo.SetLastVisit(ctx, user.ID, now)
+	return res, err
+}
+
+func (s *UsersService) newTokens(user domain.User, sessionId primitive.ObjectID) (Tokens, error) {
+	var (
+		res Tokens
+		err error
+	)
+
+	claims := TokenClaims(user.ID.Hex(), userGrants(user))
+	claims.SessionID = sessionId.Hex()
+
+	res.AccessToken, err = s.tokenManager.NewJWT(claims, s.accessTokenTTL)
+	if err != nil {
+		return res, err
 	}
 
-	err = s.repo.SetSession(ctx, studentId, session)
+	res.RefreshToken, err = s.tokenManager.NewRefreshToken()
 	return res, err
 }
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"gitlab.com/peleng-meteo/meteo-go/internal/config"
+	"gitlab.com/peleng-meteo/meteo-go/internal/domain"
+	"gitlab.com/peleng-meteo/meteo-go/internal/repository"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/logger"
+	"gitlab.com/peleng-meteo/meteo-go/pkg/webhook"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const webhookSecretLength = 32
+
+// webhookPayload is the body of every delivery
+type webhookPayload struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+type webhookJob struct {
+	webhook    domain.Webhook
+	deliveryId string
+	event      string
+	payload    []byte
+	attempt    int
+}
+
+type WebhooksService struct {
+	repo           repository.Webhooks
+	deliveriesRepo repository.WebhookDeliveries
+	client         *webhook.Client
+	config         config.WebhooksConfig
+	allowInsecure  bool
+
+	queue chan webhookJob
+}
+
+func NewWebhooksService(repo repository.Webhooks, deliveriesRepo repository.WebhookDeliveries, cfg config.WebhooksConfig, environment string) *WebhooksService {
+	return &WebhooksService{
+		repo:           repo,
+		deliveriesRepo: deliveriesRepo,
+		client:         webhook.NewClient(cfg.Timeout, environment == config.EnvLocal),
+		config:         cfg,
+		allowInsecure:  environment == config.EnvLocal,
+		queue:          make(chan webhookJob, cfg.QueueSize),
+	}
+}
+
+func (s *WebhooksService) Create(ctx context.Context, inp CreateWebhookInput) (domain.Webhook, error) {
+	if err := s.validate(ctx, inp.URL, inp.Events); err != nil {
+		return domain.Webhook{}, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	if inp.StationIDs == nil {
+		inp.StationIDs = make([]string, 0)
+	}
+
+	wh := domain.Webhook{
+		UserID:     inp.UserID,
+		URL:        inp.URL,
+		Secret:     secret,
+		Events:     inp.Events,
+		StationIDs: inp.StationIDs,
+		Enabled:    true,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	wh.ID, err = s.repo.Create(ctx, wh)
+
+	return wh, err
+}
+
+func (s *WebhooksService) GetByUser(ctx context.Context, userId primitive.ObjectID) ([]domain.Webhook, error) {
+	return s.repo.GetByUser(ctx, userId)
+}
+
+func (s *WebhooksService) GetById(ctx context.Context, userId, id primitive.ObjectID) (domain.Webhook, error) {
+	wh, err := s.repo.GetById(ctx, userId, id)
+	if err != nil {
+		if err == repository.ErrWebhookNotFound {
+			return domain.Webhook{}, ErrWebhookNotFound
+		}
+
+		return domain.Webhook{}, err
+	}
+
+	return wh, nil
+}
+
+func (s *WebhooksService) Update(ctx context.Context, inp UpdateWebhookInput) error {
+	if inp.URL != nil {
+		if err := s.validateURL(ctx, *inp.URL); err != nil {
+			return err
+		}
+	}
+
+	if inp.Events != nil {
+		if err := validateWebhookEvents(inp.Events); err != nil {
+			return err
+		}
+	}
+
+	err := s.repo.Update(ctx, repository.UpdateWebhookInput{
+		ID:         inp.ID,
+		UserID:     inp.UserID,
+		URL:        inp.URL,
+		Events:     inp.Events,
+		StationIDs: inp.StationIDs,
+		Enabled:    inp.Enabled,
+	})
+	if err == repository.ErrWebhookNotFound {
+		return ErrWebhookNotFound
+	}
+
+	return err
+}
+
+func (s *WebhooksService) Delete(ctx context.Context, userId, id primitive.ObjectID) error {
+	err := s.repo.Delete(ctx, userId, id)
+	if err == repository.ErrWebhookNotFound {
+		return ErrWebhookNotFound
+	}
+
+	return err
+}
+
+func (s *WebhooksService) GetDeliveries(ctx context.Context, userId, id primitive.ObjectID, limit int64) ([]domain.WebhookDelivery, error) {
+	if _, err := s.GetById(ctx, userId, id); err != nil {
+		return nil, err
+	}
+
+	return s.deliveriesRepo.GetByWebhook(ctx, id, limit)
+}
+
+// Ping sends ping event to the webhook regardless of its subscriptions, so users can check their endpoint
+func (s *WebhooksService) Ping(ctx context.Context, userId, id primitive.ObjectID) error {
+	wh, err := s.GetById(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	return s.enqueueEvent(wh, WebhookEvent{Type: domain.WebhookEventPing, Data: struct{}{}})
+}
+
+// Dispatch queues event delivery to every webhook subscribed to it. Deliveries are made by Run workers
+func (s *WebhooksService) Dispatch(ctx context.Context, event WebhookEvent) error {
+	webhooks, err := s.repo.GetSubscribed(ctx, repository.WebhooksSubscription{
+		Event:     event.Type,
+		StationID: event.StationID,
+		UserID:    event.UserID,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, wh := range webhooks {
+		if err := s.enqueueEvent(wh, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run delivers queued events until ctx is cancelled. Pending retries are dropped on shutdown
+func (s *WebhooksService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < s.config.Workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-s.queue:
+					s.deliver(ctx, job)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (s *WebhooksService) enqueueEvent(wh domain.Webhook, event WebhookEvent) error {
+	deliveryId := primitive.NewObjectID().Hex()
+
+	payload, err := json.Marshal(webhookPayload{
+		ID:        deliveryId,
+		Type:      event.Type,
+		CreatedAt: time.Now(),
+		Data:      event.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.enqueue(webhookJob{
+		webhook:    wh,
+		deliveryId: deliveryId,
+		event:      event.Type,
+		payload:    payload,
+		attempt:    1,
+	})
+
+	return nil
+}
+
+// enqueue never blocks event producers such as ingestion, deliveries exceeding queue size are dropped
+func (s *WebhooksService) enqueue(job webhookJob) {
+	select {
+	case s.queue <- job:
+	default:
+		logger.Errorf("webhook queue is full, delivery %s to %s dropped", job.deliveryId, job.webhook.URL)
+	}
+}
+
+func (s *WebhooksService) deliver(ctx context.Context, job webhookJob) {
+	resp, err := s.client.Send(ctx, webhook.Request{
+		URL:        job.webhook.URL,
+		Secret:     job.webhook.Secret,
+		Event:      job.event,
+		DeliveryID: job.deliveryId,
+		Body:       job.payload,
+	})
+
+	delivery := domain.WebhookDelivery{
+		WebhookID:   job.webhook.ID,
+		UserID:      job.webhook.UserID,
+		DeliveryID:  job.deliveryId,
+		Event:       job.event,
+		URL:         job.webhook.URL,
+		Payload:     string(job.payload),
+		Attempt:     job.attempt,
+		Success:     err == nil,
+		StatusCode:  resp.StatusCode,
+		Response:    resp.Body,
+		DurationMs:  resp.Duration.Milliseconds(),
+		AttemptedAt: time.Now(),
+	}
+
+	if err != nil {
+		delivery.Error = err.Error()
+
+		if job.attempt < s.config.MaxAttempts && ctx.Err() == nil {
+			backoff := s.backoff(job.attempt)
+			nextRetryAt := time.Now().Add(backoff)
+			delivery.NextRetryAt = &nextRetryAt
+
+			job.attempt++
+			time.AfterFunc(backoff, func() {
+				if ctx.Err() == nil {
+					s.enqueue(job)
+				}
+			})
+		}
+	}
+
+	if err := s.deliveriesRepo.Create(ctx, delivery); err != nil {
+		logger.Errorf("failed to save webhook delivery %s: %s", job.deliveryId, err.Error())
+	}
+}
+
+// backoff doubles retry delay after every failed attempt up to MaxBackoff
+func (s *WebhooksService) backoff(attempt int) time.Duration {
+	backoff := s.config.InitialBackoff
+	for i := 1; i < attempt && backoff < s.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > s.config.MaxBackoff {
+		backoff = s.config.MaxBackoff
+	}
+
+	return backoff
+}
+
+func (s *WebhooksService) validate(ctx context.Context, rawURL string, events []string) error {
+	if err := s.validateURL(ctx, rawURL); err != nil {
+		return err
+	}
+
+	return validateWebhookEvents(events)
+}
+
+// validateURL accepts only HTTPS endpoints resolving to public addresses. Plain HTTP and internal hosts
+// are allowed in local environment for development. Client checks the address again on every delivery
+func (s *WebhooksService) validateURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return ErrInvalidWebhookURL
+	}
+
+	if s.allowInsecure {
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return ErrInvalidWebhookURL
+		}
+
+		return nil
+	}
+
+	if u.Scheme != "https" {
+		return ErrInvalidWebhookURL
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return ErrWebhookURLNotPublic
+	}
+
+	for _, addr := range addrs {
+		if !webhook.IsPublicIP(addr.IP) {
+			return ErrWebhookURLNotPublic
+		}
+	}
+
+	return nil
+}
+
+func validateWebhookEvents(events []string) error {
+	if len(events) == 0 {
+		return ErrInvalidWebhookEvent
+	}
+
+	for _, event := range events {
+		if !domain.IsWebhookEvent(event) {
+			return ErrInvalidWebhookEvent
+		}
+	}
+
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
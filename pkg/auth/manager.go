@@ -1,20 +1,36 @@
 package auth
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"github.com/dgrijalva/jwt-go"
-	"math/rand"
 	"time"
 )
 
 // TokenManager provides logic for JWT & Refresh tokens generation and parsing
 type TokenManager interface {
-	NewJWT(userId string, ttl time.Duration) (string, error)
-	Parse(accessToken string) (string, error)
+	NewJWT(claims Claims, ttl time.Duration) (string, error)
+	Parse(accessToken string) (Claims, error)
 	NewRefreshToken() (string, error)
 }
 
+// Claims of access token. Roles are granted globally, Scopes maps roles to resources they are granted for only,
+// so requests can be authorized without looking the subject up. SessionID is optional id of the sign in
+type Claims struct {
+	Subject   string
+	SessionID string
+	Roles     []string
+	Scopes    map[string][]string
+}
+
+type tokenClaims struct {
+	jwt.StandardClaims
+	SessionID string              `json:"sid,omitempty"`
+	Roles     []string            `json:"roles,omitempty"`
+	Scopes    map[string][]string `json:"scopes,omitempty"`
+}
+
 type Manager struct {
 	signingKey string
 }
@@ -27,41 +43,49 @@ func NewManager(signingKey string) (*Manager, error) {
 	return &Manager{signingKey: signingKey}, nil
 }
 
-func (m *Manager) NewJWT(userId string, ttl time.Duration) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(ttl).Unix(),
-		Subject:   userId,
+func (m *Manager) NewJWT(claims Claims, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, tokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(ttl).Unix(),
+			Subject:   claims.Subject,
+		},
+		SessionID: claims.SessionID,
+		Roles:     claims.Roles,
+		Scopes:    claims.Scopes,
 	})
 
 	return token.SignedString([]byte(m.signingKey))
 }
 
-func (m *Manager) Parse(accessToken string) (string, error) {
-	token, err := jwt.Parse(accessToken, func(token *jwt.Token) (i interface{}, err error) {
+func (m *Manager) Parse(accessToken string) (Claims, error) {
+	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (i interface{}, err error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(m.signingKey), nil
 	})
 	if err != nil {
-		return "", err
+		return Claims{}, err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return "", fmt.Errorf("error get user claims from token")
+	claims, ok := token.Claims.(*tokenClaims)
+	if !ok || claims.Subject == "" {
+		return Claims{}, fmt.Errorf("error get user claims from token")
 	}
 
-	return claims["sub"].(string), nil
+	return Claims{
+		Subject:   claims.Subject,
+		SessionID: claims.SessionID,
+		Roles:     claims.Roles,
+		Scopes:    claims.Scopes,
+	}, nil
 }
 
 func (m *Manager) NewRefreshToken() (string, error) {
+	// refresh tokens identify sessions, they must be unique and unpredictable
 	b := make([]byte, 32)
 
-	s := rand.NewSource(time.Now().Unix())
-	r := rand.New(s)
-
-	_, err := r.Read(b)
+	_, err := rand.Read(b)
 	if err != nil {
 		return "", err
 	}
@@ -8,7 +8,12 @@ import (
 	"time"
 )
 
-const timeout = 10 * time.Second
+const (
+	timeout = 10 * time.Second
+
+	DuplicateKeyCode      = 11000
+	NamespaceNotFoundCode = 26
+)
 
 // NewClient established connection to a mongoDb instance using provided URI and auth credentials
 func NewClient(uri, username, password string) (*mongo.Client, error) {
@@ -43,7 +48,7 @@ func IsDuplicate(err error) bool {
 	var e mongo.WriteException
 	if errors.As(err, &e) {
 		for _, we := range e.WriteErrors {
-			if we.Code == 11000 {
+			if we.Code == DuplicateKeyCode {
 				return true
 			}
 		}
@@ -51,3 +56,9 @@ func IsDuplicate(err error) bool {
 
 	return false
 }
+
+// IsNamespaceNotFound reports whether command failed because the collection doesn't exist
+func IsNamespaceNotFound(err error) bool {
+	var e mongo.CommandError
+	return errors.As(err, &e) && e.Code == NamespaceNotFoundCode
+}
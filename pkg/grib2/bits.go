@@ -0,0 +1,76 @@
+package grib2
+
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func newBitReader(data []byte) *bitReader {
+	return &bitReader{data: data}
+}
+
+// read reads n bits most significant first, n must not exceed 64
+func (r *bitReader) read(n int) (uint64, error) {
+	if r.pos+n > len(r.data)*8 {
+		return 0, &FormatError{Section: 7, Msg: "data is truncated"}
+	}
+
+	var v uint64
+	for n > 0 {
+		offset := r.pos % 8
+		available := 8 - offset
+
+		take := available
+		if n < take {
+			take = n
+		}
+
+		b := uint64(r.data[r.pos/8]>>uint(available-take)) & (1<<uint(take) - 1)
+		v = v<<uint(take) | b
+
+		r.pos += take
+		n -= take
+	}
+
+	return v, nil
+}
+
+// readSigned reads n bits sign and magnitude integer
+func (r *bitReader) readSigned(n int) (int64, error) {
+	v, err := r.read(n)
+	if err != nil {
+		return 0, err
+	}
+
+	sign := uint64(1) << uint(n-1)
+	if v&sign != 0 {
+		return -int64(v &^ sign), nil
+	}
+
+	return int64(v), nil
+}
+
+// readGroupValues reads count values of n bits and returns reference+value*increment,
+// group descriptors are padded to the octet boundary
+func (r *bitReader) readGroupValues(count, n, reference, increment int) ([]int64, error) {
+	values := make([]int64, count)
+
+	for i := range values {
+		v, err := r.read(n)
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = int64(reference) + int64(v)*int64(increment)
+	}
+
+	r.align()
+
+	return values, nil
+}
+
+func (r *bitReader) align() {
+	if rem := r.pos % 8; rem != 0 {
+		r.pos += 8 - rem
+	}
+}
@@ -0,0 +1,111 @@
+// Package grib2 reads WMO GRIB edition 2 messages. Supported are grid definition template 3.0
+// (regular latitude/longitude grid), product definition templates 4.0, 4.1, 4.8 and 4.11 and
+// data representation templates 5.0 (simple packing), 5.2 and 5.3 (complex packing and spatial differencing).
+package grib2
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	SurfaceGround            = 1
+	SurfaceIsobaric          = 100
+	SurfaceMeanSeaLevel      = 101
+	SurfaceHeightAboveGround = 103
+	SurfaceMissing           = 255
+)
+
+var (
+	// ErrNotGRIB is returned when input doesn't contain GRIB message
+	ErrNotGRIB = errors.New("grib2: not a GRIB message")
+	// ErrOutsideGrid is returned when interpolated point isn't covered by the grid
+	ErrOutsideGrid = errors.New("grib2: point is outside of the grid")
+)
+
+// Field is a single decoded data field. Message can contain several fields sharing some sections.
+// Values are stored in the grid scanning order, missing values are NaN
+type Field struct {
+	Discipline    int
+	Centre        int
+	ReferenceTime time.Time
+	Grid          Grid
+	Product       Product
+	Values        []float64
+}
+
+// Product describes forecast parameter, its level and forecast time
+type Product struct {
+	Template     int
+	Category     int
+	Number       int
+	ForecastTime time.Duration
+	Surface      Surface
+	// Statistical is set for values processed over time interval, e.g. accumulated precipitation
+	Statistical *Statistical
+}
+
+// Surface is the first fixed surface of the product, e.g. 2 m above ground (SurfaceHeightAboveGround, 2)
+type Surface struct {
+	Type  int
+	Value float64
+}
+
+// Statistical describes processing of values over [Start, End) interval, Process is WMO code table 4.10 value
+type Statistical struct {
+	Process int
+	Start   time.Time
+	End     time.Time
+}
+
+// ValidTime returns time the field values are valid at, end of interval for statistically processed ones
+func (f *Field) ValidTime() time.Time {
+	if f.Product.Statistical != nil {
+		return f.Product.Statistical.End
+	}
+
+	return f.ReferenceTime.Add(f.Product.ForecastTime)
+}
+
+// Parameter returns parameter of the field from WMO code table 4.2
+func (f *Field) Parameter() Parameter {
+	return LookupParameter(f.Discipline, f.Product.Category, f.Product.Number)
+}
+
+// Interpolate returns value at lat, lon bilinearly interpolated from 4 surrounding grid points.
+// Missing points are skipped and weights of the others are normalized, NaN is returned if all of them are missing
+func (f *Field) Interpolate(lat, lon float64) (float64, error) {
+	neighbours, err := f.Grid.Neighbours(lat, lon)
+	if err != nil {
+		return 0, err
+	}
+
+	return Weigh(neighbours, func(i, j int) float64 {
+		return f.Values[f.Grid.Index(i, j)]
+	}), nil
+}
+
+// FormatError describes malformed section of the message
+type FormatError struct {
+	Section int
+	Msg     string
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("grib2: section %d: %s", e.Section, e.Msg)
+}
+
+// UnsupportedError is returned for templates and editions the package can't decode
+type UnsupportedError struct {
+	Section  int
+	Template int
+}
+
+func (e *UnsupportedError) Error() string {
+	if e.Section == 0 {
+		return fmt.Sprintf("grib2: GRIB edition %d is not supported", e.Template)
+	}
+
+	return fmt.Sprintf("grib2: template %d.%d is not supported", e.Section, e.Template)
+}
@@ -0,0 +1,187 @@
+package grib2
+
+import "math"
+
+// Scanning mode flags, WMO flag table 3.4
+const (
+	ScanNegativeI     = 0x80
+	ScanPositiveJ     = 0x40
+	ScanConsecutiveJ  = 0x20
+	ScanBoustrophedon = 0x10
+)
+
+const coordinateEpsilon = 1e-9
+
+// Grid is regular latitude/longitude grid of template 3.0. Point (i, j) is i-th along the parallel
+// and j-th along the meridian counting from the first grid point (La1, Lo1) in scanning direction.
+// Coordinates and increments are in degrees, increments are positive
+type Grid struct {
+	Template int
+	Points   int
+	Ni       int
+	Nj       int
+	La1      float64
+	Lo1      float64
+	La2      float64
+	Lo2      float64
+	Di       float64
+	Dj       float64
+	ScanMode byte
+}
+
+// Neighbour is grid point with its weight in interpolated value
+type Neighbour struct {
+	I      int
+	J      int
+	Weight float64
+}
+
+// Increments returns signed increments of longitude and latitude between adjacent points
+func (g Grid) Increments() (di, dj float64) {
+	di, dj = g.Di, -g.Dj
+
+	if g.ScanMode&ScanNegativeI != 0 {
+		di = -di
+	}
+
+	if g.ScanMode&ScanPositiveJ != 0 {
+		dj = -dj
+	}
+
+	return di, dj
+}
+
+// Index returns position of point (i, j) in field values
+func (g Grid) Index(i, j int) int {
+	if g.ScanMode&ScanBoustrophedon != 0 && j%2 == 1 {
+		i = g.Ni - 1 - i
+	}
+
+	return j*g.Ni + i
+}
+
+// Point returns coordinates of point (i, j), longitude is normalized to [-180, 180)
+func (g Grid) Point(i, j int) (lat, lon float64) {
+	di, dj := g.Increments()
+
+	return g.La1 + float64(j)*dj, normalizeLongitude(g.Lo1 + float64(i)*di)
+}
+
+// Global reports whether the grid wraps around the globe along parallels
+func (g Grid) Global() bool {
+	return g.Di > 0 && math.Abs(float64(g.Ni)*g.Di-360) < g.Di/2
+}
+
+// Neighbours returns up to 4 grid points surrounding lat, lon with bilinear interpolation weights
+func (g Grid) Neighbours(lat, lon float64) ([]Neighbour, error) {
+	di, dj := g.Increments()
+
+	x := math.Mod(lon-g.Lo1, 360)
+	if di < 0 {
+		x = math.Mod(g.Lo1-lon, 360)
+	}
+
+	if x < 0 {
+		x += 360
+	}
+
+	if x > 360-coordinateEpsilon {
+		x = 0
+	}
+
+	fi, fj := 0.0, 0.0
+	if g.Di > 0 {
+		fi = x / g.Di
+	}
+
+	if dj != 0 {
+		fj = (lat - g.La1) / dj
+	}
+
+	maxI := float64(g.Ni - 1)
+	if g.Global() {
+		maxI = float64(g.Ni)
+	}
+
+	if fi > maxI+coordinateEpsilon || fj < -coordinateEpsilon || fj > float64(g.Nj-1)+coordinateEpsilon {
+		return nil, ErrOutsideGrid
+	}
+
+	i0, t := split(fi, g.Ni, g.Global())
+	j0, u := split(fj, g.Nj, false)
+
+	i1, j1 := i0+1, j0+1
+	if i1 == g.Ni {
+		i1 = 0
+	}
+
+	neighbours := []Neighbour{
+		{I: i0, J: j0, Weight: (1 - t) * (1 - u)},
+		{I: i1, J: j0, Weight: t * (1 - u)},
+		{I: i0, J: j1, Weight: (1 - t) * u},
+		{I: i1, J: j1, Weight: t * u},
+	}
+
+	// points with zero weight may lie outside of the grid, e.g. on its last row
+	res := neighbours[:0]
+	for _, n := range neighbours {
+		if n.Weight > 0 {
+			res = append(res, n)
+		}
+	}
+
+	if len(res) == 0 {
+		res = append(res, Neighbour{I: i0, J: j0, Weight: 1})
+	}
+
+	return res, nil
+}
+
+// Weigh sums values of neighbours with their weights. Missing (NaN) values are skipped
+// and the remaining weights are normalized, NaN is returned if all values are missing
+func Weigh(neighbours []Neighbour, value func(i, j int) float64) float64 {
+	var sum, weights float64
+
+	for _, n := range neighbours {
+		v := value(n.I, n.J)
+		if math.IsNaN(v) {
+			continue
+		}
+
+		sum += v * n.Weight
+		weights += n.Weight
+	}
+
+	if weights == 0 {
+		return math.NaN()
+	}
+
+	return sum / weights
+}
+
+// split returns index of the cell containing fractional position f and offset within the cell.
+// Positions slightly outside of the grid due to rounding are clamped to its edge
+func split(f float64, n int, wrap bool) (int, float64) {
+	last := float64(n - 1)
+	if wrap {
+		last = float64(n)
+	}
+
+	f = math.Max(0, math.Min(f, last))
+
+	i := int(math.Floor(f))
+	if i > n-1 {
+		i = n - 1
+	}
+
+	return i, f - float64(i)
+}
+
+func normalizeLongitude(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+
+	return lon - 180
+}
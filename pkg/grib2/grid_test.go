@@ -0,0 +1,151 @@
+package grib2
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNeighbours(t *testing.T) {
+	// 4x3 points from 60N 30E to 58N 33E scanned west to east, north to south
+	regional := Grid{Points: 12, Ni: 4, Nj: 3, La1: 60, Lo1: 30, La2: 58, Lo2: 33, Di: 1, Dj: 1}
+
+	// 4x3 points from 90S 0E to 90N 270E scanned south to north, wrapping around the globe
+	global := Grid{Points: 12, Ni: 4, Nj: 3, La1: -90, Lo1: 0, La2: 90, Lo2: 270, Di: 90, Dj: 90, ScanMode: ScanPositiveJ}
+
+	// the same regional grid scanned east to west
+	westward := Grid{Points: 12, Ni: 4, Nj: 3, La1: 60, Lo1: 33, La2: 58, Lo2: 30, Di: 1, Dj: 1, ScanMode: ScanNegativeI}
+
+	tests := []struct {
+		name     string
+		grid     Grid
+		lat, lon float64
+		want     []Neighbour
+	}{
+		{
+			name: "inside the cell",
+			grid: regional, lat: 59.5, lon: 31.25,
+			want: []Neighbour{{1, 0, 0.375}, {2, 0, 0.125}, {1, 1, 0.375}, {2, 1, 0.125}},
+		},
+		{
+			name: "grid point",
+			grid: regional, lat: 59, lon: 32,
+			want: []Neighbour{{2, 1, 1}},
+		},
+		{
+			name: "first grid point",
+			grid: regional, lat: 60, lon: 30,
+			want: []Neighbour{{0, 0, 1}},
+		},
+		{
+			name: "last grid point",
+			grid: regional, lat: 58, lon: 33,
+			want: []Neighbour{{3, 2, 1}},
+		},
+		{
+			name: "last column",
+			grid: regional, lat: 58.5, lon: 33,
+			want: []Neighbour{{3, 1, 0.5}, {3, 2, 0.5}},
+		},
+		{
+			name: "last row",
+			grid: regional, lat: 58, lon: 32.5,
+			want: []Neighbour{{2, 2, 0.5}, {3, 2, 0.5}},
+		},
+		{
+			name: "last row and column within rounding error",
+			grid: regional, lat: 58 - 1e-12, lon: 33 + 1e-12,
+			want: []Neighbour{{3, 2, 1}},
+		},
+		{
+			name: "longitude in another range",
+			grid: regional, lat: 59, lon: -329,
+			want: []Neighbour{{1, 1, 1}},
+		},
+		{
+			name: "wrap between the last and the first column",
+			grid: global, lat: 45, lon: 315,
+			want: []Neighbour{{3, 1, 0.25}, {0, 1, 0.25}, {3, 2, 0.25}, {0, 2, 0.25}},
+		},
+		{
+			name: "wrap with negative longitude",
+			grid: global, lat: 0, lon: -45,
+			want: []Neighbour{{3, 1, 0.5}, {0, 1, 0.5}},
+		},
+		{
+			name: "full turn is the first column",
+			grid: global, lat: 90, lon: 360,
+			want: []Neighbour{{0, 2, 1}},
+		},
+		{
+			name: "south pole",
+			grid: global, lat: -90, lon: 90,
+			want: []Neighbour{{1, 0, 1}},
+		},
+		{
+			name: "east to west scanning",
+			grid: westward, lat: 60, lon: 30.5,
+			want: []Neighbour{{2, 0, 0.5}, {3, 0, 0.5}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.grid.Neighbours(tt.lat, tt.lon)
+			if err != nil {
+				t.Fatalf("Neighbours() error = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Neighbours() = %+v, want %+v", got, tt.want)
+			}
+
+			for i, n := range got {
+				if n.I != tt.want[i].I || n.J != tt.want[i].J || math.Abs(n.Weight-tt.want[i].Weight) > 1e-9 {
+					t.Errorf("Neighbours() = %+v, want %+v", got, tt.want)
+					break
+				}
+			}
+
+			for _, n := range got {
+				if n.I < 0 || n.I >= tt.grid.Ni || n.J < 0 || n.J >= tt.grid.Nj {
+					t.Errorf("neighbour %+v is outside of the grid", n)
+				}
+			}
+		})
+	}
+}
+
+func TestNeighboursOutsideGrid(t *testing.T) {
+	regional := Grid{Points: 12, Ni: 4, Nj: 3, La1: 60, Lo1: 30, La2: 58, Lo2: 33, Di: 1, Dj: 1}
+
+	tests := []struct {
+		name     string
+		lat, lon float64
+	}{
+		{name: "north", lat: 60.5, lon: 31},
+		{name: "south", lat: 57.5, lon: 31},
+		{name: "east", lat: 59, lon: 33.5},
+		{name: "west", lat: 59, lon: 29.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := regional.Neighbours(tt.lat, tt.lon); err != ErrOutsideGrid {
+				t.Errorf("Neighbours() error = %v, want ErrOutsideGrid", err)
+			}
+		})
+	}
+}
+
+func TestIndex(t *testing.T) {
+	g := Grid{Points: 6, Ni: 3, Nj: 2, ScanMode: ScanBoustrophedon}
+
+	if got := g.Index(0, 1); got != 5 {
+		t.Errorf("boustrophedon Index(0, 1) = %d, want 5", got)
+	}
+
+	g.ScanMode = 0
+	if got := g.Index(0, 1); got != 3 {
+		t.Errorf("Index(0, 1) = %d, want 3", got)
+	}
+}
@@ -0,0 +1,262 @@
+package grib2
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+const (
+	packingSimple              = 0
+	packingComplex             = 2
+	packingSpatialDifferencing = 3
+
+	missingNone      = 0
+	missingPrimary   = 1
+	missingSecondary = 2
+)
+
+// dataRepresentation holds data representation templates 5.0, 5.2 and 5.3.
+// Unpacked value is (reference + X * 2^binaryScale) / 10^decimalScale
+type dataRepresentation struct {
+	template     int
+	values       int
+	reference    float64
+	binaryScale  int
+	decimalScale int
+	bits         int
+
+	missingManagement    int
+	groups               int
+	groupWidthReference  int
+	groupWidthBits       int
+	groupLengthReference int
+	groupLengthIncrement int
+	lastGroupLength      int
+	groupLengthBits      int
+
+	spatialOrder     int
+	descriptorOctets int
+}
+
+func decodeDataRepresentation(sec []byte) (*dataRepresentation, error) {
+	if len(sec) < 11 {
+		return nil, &FormatError{Section: 5, Msg: "section is too short"}
+	}
+
+	drs := &dataRepresentation{
+		values:   int(binary.BigEndian.Uint32(sec[5:])),
+		template: int(binary.BigEndian.Uint16(sec[9:])),
+	}
+
+	minLength := map[int]int{packingSimple: 21, packingComplex: 47, packingSpatialDifferencing: 49}[drs.template]
+	if minLength == 0 {
+		return nil, &UnsupportedError{Section: 5, Template: drs.template}
+	}
+
+	if len(sec) < minLength {
+		return nil, &FormatError{Section: 5, Msg: "template is too short"}
+	}
+
+	drs.reference = float64(math.Float32frombits(binary.BigEndian.Uint32(sec[11:])))
+	drs.binaryScale = int(int16SignMagnitude(sec[15:]))
+	drs.decimalScale = int(int16SignMagnitude(sec[17:]))
+	drs.bits = int(sec[19])
+
+	if drs.template == packingSimple {
+		return drs, nil
+	}
+
+	drs.missingManagement = int(sec[22])
+	drs.groups = int(binary.BigEndian.Uint32(sec[31:]))
+	drs.groupWidthReference = int(sec[35])
+	drs.groupWidthBits = int(sec[36])
+	drs.groupLengthReference = int(binary.BigEndian.Uint32(sec[37:]))
+	drs.groupLengthIncrement = int(sec[41])
+	drs.lastGroupLength = int(binary.BigEndian.Uint32(sec[42:]))
+	drs.groupLengthBits = int(sec[46])
+
+	if drs.missingManagement > missingSecondary {
+		return nil, &FormatError{Section: 5, Msg: "invalid missing value management"}
+	}
+
+	if drs.template == packingSpatialDifferencing {
+		drs.spatialOrder = int(sec[47])
+		drs.descriptorOctets = int(sec[48])
+
+		if drs.spatialOrder != 1 && drs.spatialOrder != 2 {
+			return nil, &FormatError{Section: 5, Msg: "order of spatial differencing must be 1 or 2"}
+		}
+	}
+
+	return drs, nil
+}
+
+func (drs *dataRepresentation) unpack(data []byte) ([]float64, error) {
+	if drs.template == packingSimple {
+		return drs.unpackSimple(data)
+	}
+
+	return drs.unpackComplex(data)
+}
+
+func (drs *dataRepresentation) scale(x float64) float64 {
+	return (drs.reference + x*math.Pow(2, float64(drs.binaryScale))) / math.Pow(10, float64(drs.decimalScale))
+}
+
+func (drs *dataRepresentation) unpackSimple(data []byte) ([]float64, error) {
+	values := make([]float64, drs.values)
+
+	if drs.bits == 0 {
+		for i := range values {
+			values[i] = drs.scale(0)
+		}
+
+		return values, nil
+	}
+
+	r := newBitReader(data)
+	for i := range values {
+		x, err := r.read(drs.bits)
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = drs.scale(float64(x))
+	}
+
+	return values, nil
+}
+
+// unpackComplex decodes data template 7.3: values are split into groups having own reference and bit width,
+// for template 5.3 original values are restored from differences of the first or second order
+func (drs *dataRepresentation) unpackComplex(data []byte) ([]float64, error) {
+	r := newBitReader(data)
+
+	var first, second, minimum int64
+	if drs.spatialOrder > 0 && drs.descriptorOctets > 0 {
+		bits := drs.descriptorOctets * 8
+
+		var err error
+		if first, err = r.readSigned(bits); err != nil {
+			return nil, err
+		}
+
+		if drs.spatialOrder == 2 {
+			if second, err = r.readSigned(bits); err != nil {
+				return nil, err
+			}
+		}
+
+		if minimum, err = r.readSigned(bits); err != nil {
+			return nil, err
+		}
+	}
+
+	references, err := r.readGroupValues(drs.groups, drs.bits, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	widths, err := r.readGroupValues(drs.groups, drs.groupWidthBits, drs.groupWidthReference, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	lengths, err := r.readGroupValues(drs.groups, drs.groupLengthBits, drs.groupLengthReference, drs.groupLengthIncrement)
+	if err != nil {
+		return nil, err
+	}
+
+	if drs.groups > 0 {
+		lengths[drs.groups-1] = int64(drs.lastGroupLength)
+	}
+
+	total := 0
+	for _, length := range lengths {
+		total += int(length)
+	}
+
+	if total != drs.values {
+		return nil, &FormatError{Section: 7, Msg: "group lengths don't match number of values"}
+	}
+
+	xs := make([]int64, 0, drs.values)
+	missing := make([]bool, 0, drs.values)
+
+	for g := 0; g < drs.groups; g++ {
+		width := int(widths[g])
+
+		for k := int64(0); k < lengths[g]; k++ {
+			if width == 0 {
+				xs = append(xs, references[g])
+				missing = append(missing, drs.isMissing(uint64(references[g]), drs.bits))
+
+				continue
+			}
+
+			x, err := r.read(width)
+			if err != nil {
+				return nil, err
+			}
+
+			xs = append(xs, references[g]+int64(x))
+			missing = append(missing, drs.isMissing(x, width))
+		}
+	}
+
+	if drs.spatialOrder > 0 {
+		undoSpatialDifferencing(xs, missing, drs.spatialOrder, first, second, minimum)
+	}
+
+	values := make([]float64, len(xs))
+	for i, x := range xs {
+		if missing[i] {
+			values[i] = math.NaN()
+			continue
+		}
+
+		values[i] = drs.scale(float64(x))
+	}
+
+	return values, nil
+}
+
+// isMissing reports whether x of the width is a missing value indicator, i.e. all ones or all ones minus one
+func (drs *dataRepresentation) isMissing(x uint64, width int) bool {
+	if drs.missingManagement == missingNone || width == 0 {
+		return false
+	}
+
+	all := uint64(1)<<uint(width) - 1
+	if x == all {
+		return true
+	}
+
+	return drs.missingManagement == missingSecondary && x == all-1
+}
+
+// undoSpatialDifferencing restores original values of non-missing points in place
+func undoSpatialDifferencing(xs []int64, missing []bool, order int, first, second, minimum int64) {
+	n := 0
+	var prev1, prev2 int64
+
+	for i := range xs {
+		if missing[i] {
+			continue
+		}
+
+		switch {
+		case n == 0:
+			xs[i] = first
+		case n == 1 && order == 2:
+			xs[i] = second
+		case order == 1:
+			xs[i] = xs[i] + minimum + prev1
+		default:
+			xs[i] = xs[i] + minimum + 2*prev1 - prev2
+		}
+
+		prev2, prev1 = prev1, xs[i]
+		n++
+	}
+}
@@ -0,0 +1,303 @@
+package grib2
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestUnpack(t *testing.T) {
+	nan := math.NaN()
+
+	tests := []struct {
+		name string
+		drs  dataRepresentation
+		data func(w *bitWriter)
+		want []float64
+	}{
+		{
+			name: "simple packing with binary and decimal scale",
+			drs:  dataRepresentation{template: packingSimple, values: 4, reference: 10, binaryScale: 1, decimalScale: 1, bits: 4},
+			data: func(w *bitWriter) {
+				w.write(0, 4)
+				w.write(1, 4)
+				w.write(5, 4)
+				w.write(15, 4)
+			},
+			want: []float64{1, 1.2, 2, 4},
+		},
+		{
+			name: "simple packing of constant field",
+			drs:  dataRepresentation{template: packingSimple, values: 3, reference: 2.5},
+			data: func(w *bitWriter) {},
+			want: []float64{2.5, 2.5, 2.5},
+		},
+		{
+			name: "complex packing",
+			drs: dataRepresentation{template: packingComplex, values: 5, reference: 100, bits: 4, groups: 2,
+				groupWidthBits: 2, groupLengthReference: 3, groupLengthIncrement: 1, lastGroupLength: 2, groupLengthBits: 1},
+			data: func(w *bitWriter) {
+				writeGroups(w, 4, 3, 10)
+				writeGroups(w, 2, 2, 0)
+				writeGroups(w, 1, 0, 0)
+				w.write(0, 2)
+				w.write(1, 2)
+				w.write(3, 2)
+			},
+			want: []float64{103, 104, 106, 110, 110},
+		},
+		{
+			name: "complex packing with primary missing values",
+			drs: dataRepresentation{template: packingComplex, values: 5, bits: 4, missingManagement: missingPrimary, groups: 2,
+				groupWidthBits: 2, groupLengthReference: 3, groupLengthIncrement: 1, lastGroupLength: 2, groupLengthBits: 1},
+			data: func(w *bitWriter) {
+				writeGroups(w, 4, 3, 15)
+				writeGroups(w, 2, 2, 0)
+				writeGroups(w, 1, 0, 0)
+				w.write(1, 2)
+				w.write(3, 2)
+				w.write(0, 2)
+			},
+			want: []float64{4, nan, 3, nan, nan},
+		},
+		{
+			name: "complex packing with secondary missing values",
+			drs: dataRepresentation{template: packingComplex, values: 5, bits: 4, missingManagement: missingSecondary, groups: 2,
+				groupWidthBits: 2, groupLengthReference: 3, groupLengthIncrement: 1, lastGroupLength: 2, groupLengthBits: 1},
+			data: func(w *bitWriter) {
+				writeGroups(w, 4, 3, 14)
+				writeGroups(w, 2, 2, 0)
+				writeGroups(w, 1, 0, 0)
+				w.write(0, 2)
+				w.write(2, 2)
+				w.write(3, 2)
+			},
+			want: []float64{3, nan, nan, nan, nan},
+		},
+		{
+			name: "first-order spatial differencing",
+			drs: dataRepresentation{template: packingSpatialDifferencing, values: 5, reference: 100, bits: 3, groups: 1,
+				groupWidthReference: 3, lastGroupLength: 5, spatialOrder: 1, descriptorOctets: 2},
+			data: func(w *bitWriter) {
+				// 10 12 15 15 11: first value 10, differences 2 3 0 -4 stored as 6 7 4 0 with minimum -4
+				writeDescriptors(w, 2, 10, -4)
+				writeGroups(w, 3, 0)
+				for _, x := range []uint64{0, 6, 7, 4, 0} {
+					w.write(x, 3)
+				}
+			},
+			want: []float64{110, 112, 115, 115, 111},
+		},
+		{
+			name: "second-order spatial differencing",
+			drs: dataRepresentation{template: packingSpatialDifferencing, values: 5, decimalScale: 1, bits: 2, groups: 1,
+				groupWidthReference: 2, lastGroupLength: 5, spatialOrder: 2, descriptorOctets: 1},
+			data: func(w *bitWriter) {
+				// 10 12 15 19 22: second-order differences 1 1 -1 stored as 2 2 0 with minimum -1
+				writeDescriptors(w, 1, 10, 12, -1)
+				writeGroups(w, 2, 0)
+				for _, x := range []uint64{0, 0, 2, 2, 0} {
+					w.write(x, 2)
+				}
+			},
+			want: []float64{1, 1.2, 1.5, 1.9, 2.2},
+		},
+		{
+			name: "spatial differencing skips missing values",
+			drs: dataRepresentation{template: packingSpatialDifferencing, values: 4, bits: 3, missingManagement: missingPrimary,
+				groups: 1, groupWidthReference: 3, lastGroupLength: 4, spatialOrder: 1, descriptorOctets: 2},
+			data: func(w *bitWriter) {
+				// 10 - 12 9: differences 2 -3 stored as 5 0 with minimum -3
+				writeDescriptors(w, 2, 10, -3)
+				writeGroups(w, 3, 0)
+				for _, x := range []uint64{0, 7, 5, 0} {
+					w.write(x, 3)
+				}
+			},
+			want: []float64{10, nan, 12, 9},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drs, err := decodeDataRepresentation(encodeDataRepresentation(tt.drs))
+			if err != nil {
+				t.Fatalf("decodeDataRepresentation() error = %v", err)
+			}
+
+			var w bitWriter
+			tt.data(&w)
+
+			values, err := drs.unpack(w.data)
+			if err != nil {
+				t.Fatalf("unpack() error = %v", err)
+			}
+
+			expectValues(t, values, tt.want)
+		})
+	}
+}
+
+func TestUnpackErrors(t *testing.T) {
+	complexDRS := dataRepresentation{template: packingComplex, values: 4, bits: 4, groups: 1,
+		groupWidthReference: 4, lastGroupLength: 3}
+
+	var lengths bitWriter
+	writeGroups(&lengths, 4, 0)
+	lengths.write(0, 12)
+
+	var truncated bitWriter
+	writeGroups(&truncated, 4, 0)
+	truncated.write(0, 8)
+
+	tests := []struct {
+		name string
+		drs  dataRepresentation
+		data []byte
+	}{
+		{name: "group lengths don't match number of values", drs: complexDRS, data: lengths.data},
+		{name: "truncated simple packing", drs: dataRepresentation{template: packingSimple, values: 3, bits: 8}, data: []byte{1, 2}},
+		{name: "truncated complex packing", drs: dataRepresentation{template: packingComplex, values: 3, bits: 4, groups: 1,
+			groupWidthReference: 4, lastGroupLength: 3}, data: truncated.data},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drs, err := decodeDataRepresentation(encodeDataRepresentation(tt.drs))
+			if err != nil {
+				t.Fatalf("decodeDataRepresentation() error = %v", err)
+			}
+
+			var formatErr *FormatError
+			if _, err := drs.unpack(tt.data); !errors.As(err, &formatErr) || formatErr.Section != 7 {
+				t.Errorf("unpack() error = %v, want section 7 format error", err)
+			}
+		})
+	}
+}
+
+func TestDecodeDataRepresentationErrors(t *testing.T) {
+	unsupported := encodeDataRepresentation(dataRepresentation{template: packingSimple})
+	binary.BigEndian.PutUint16(unsupported[9:], 40)
+
+	var unsupportedErr *UnsupportedError
+	if _, err := decodeDataRepresentation(unsupported); !errors.As(err, &unsupportedErr) || unsupportedErr.Template != 40 {
+		t.Errorf("template 5.40 error = %v, want unsupported template", err)
+	}
+
+	order := encodeDataRepresentation(dataRepresentation{template: packingSpatialDifferencing, spatialOrder: 3})
+
+	var formatErr *FormatError
+	if _, err := decodeDataRepresentation(order); !errors.As(err, &formatErr) {
+		t.Errorf("third-order spatial differencing error = %v, want format error", err)
+	}
+
+	short := encodeDataRepresentation(dataRepresentation{template: packingComplex})
+	if _, err := decodeDataRepresentation(short[:30]); !errors.As(err, &formatErr) {
+		t.Errorf("short template 5.2 error = %v, want format error", err)
+	}
+}
+
+// bitWriter packs values most significant bit first, the way bitReader reads them
+type bitWriter struct {
+	data []byte
+	pos  int
+}
+
+func (w *bitWriter) write(v uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if w.pos%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+
+		if v>>uint(i)&1 != 0 {
+			w.data[w.pos/8] |= 0x80 >> uint(w.pos%8)
+		}
+
+		w.pos++
+	}
+}
+
+func (w *bitWriter) align() {
+	w.pos = len(w.data) * 8
+}
+
+// writeGroups writes group references, widths or lengths of n bits padded to the octet boundary
+func writeGroups(w *bitWriter, n int, values ...uint64) {
+	for _, v := range values {
+		w.write(v, n)
+	}
+
+	w.align()
+}
+
+// writeDescriptors writes extra descriptors of template 7.3 as sign and magnitude integers
+func writeDescriptors(w *bitWriter, octets int, values ...int64) {
+	bits := octets * 8
+	for _, v := range values {
+		if v < 0 {
+			w.write(uint64(-v)|1<<uint(bits-1), bits)
+		} else {
+			w.write(uint64(v), bits)
+		}
+	}
+}
+
+// encodeDataRepresentation builds section 5 of the template with fields of drs
+func encodeDataRepresentation(drs dataRepresentation) []byte {
+	length := map[int]int{packingSimple: 21, packingComplex: 47, packingSpatialDifferencing: 49}[drs.template]
+
+	sec := make([]byte, length)
+	binary.BigEndian.PutUint32(sec, uint32(length))
+	sec[4] = 5
+	binary.BigEndian.PutUint32(sec[5:], uint32(drs.values))
+	binary.BigEndian.PutUint16(sec[9:], uint16(drs.template))
+	binary.BigEndian.PutUint32(sec[11:], math.Float32bits(float32(drs.reference)))
+	binary.BigEndian.PutUint16(sec[15:], signMagnitude16(drs.binaryScale))
+	binary.BigEndian.PutUint16(sec[17:], signMagnitude16(drs.decimalScale))
+	sec[19] = byte(drs.bits)
+
+	if drs.template == packingSimple {
+		return sec
+	}
+
+	sec[22] = byte(drs.missingManagement)
+	binary.BigEndian.PutUint32(sec[31:], uint32(drs.groups))
+	sec[35] = byte(drs.groupWidthReference)
+	sec[36] = byte(drs.groupWidthBits)
+	binary.BigEndian.PutUint32(sec[37:], uint32(drs.groupLengthReference))
+	sec[41] = byte(drs.groupLengthIncrement)
+	binary.BigEndian.PutUint32(sec[42:], uint32(drs.lastGroupLength))
+	sec[46] = byte(drs.groupLengthBits)
+
+	if drs.template == packingSpatialDifferencing {
+		sec[47] = byte(drs.spatialOrder)
+		sec[48] = byte(drs.descriptorOctets)
+	}
+
+	return sec
+}
+
+func signMagnitude16(v int) uint16 {
+	if v < 0 {
+		return uint16(-v) | 0x8000
+	}
+
+	return uint16(v)
+}
+
+func expectValues(t *testing.T, got, want []float64) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("values = %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if math.IsNaN(want[i]) != math.IsNaN(got[i]) || math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Errorf("values = %v, want %v", got, want)
+			return
+		}
+	}
+}
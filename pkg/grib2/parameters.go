@@ -0,0 +1,43 @@
+package grib2
+
+import "fmt"
+
+// Parameter of WMO code table 4.2 identified by discipline, category and number
+type Parameter struct {
+	Name        string
+	Description string
+	Unit        string
+}
+
+type parameterKey struct {
+	discipline, category, number int
+}
+
+// parameters lists meteorological parameters of discipline 0 commonly found in NWP output
+var parameters = map[parameterKey]Parameter{
+	{0, 0, 0}:  {Name: "TMP", Description: "Temperature", Unit: "K"},
+	{0, 0, 6}:  {Name: "DPT", Description: "Dew point temperature", Unit: "K"},
+	{0, 1, 0}:  {Name: "SPFH", Description: "Specific humidity", Unit: "kg/kg"},
+	{0, 1, 1}:  {Name: "RH", Description: "Relative humidity", Unit: "%"},
+	{0, 1, 8}:  {Name: "APCP", Description: "Total precipitation", Unit: "kg/m^2"},
+	{0, 1, 52}: {Name: "PRATE", Description: "Total precipitation rate", Unit: "kg/m^2/s"},
+	{0, 2, 0}:  {Name: "WDIR", Description: "Wind direction (from which blowing)", Unit: "deg"},
+	{0, 2, 1}:  {Name: "WIND", Description: "Wind speed", Unit: "m/s"},
+	{0, 2, 2}:  {Name: "UGRD", Description: "u-component of wind", Unit: "m/s"},
+	{0, 2, 3}:  {Name: "VGRD", Description: "v-component of wind", Unit: "m/s"},
+	{0, 2, 22}: {Name: "GUST", Description: "Wind speed (gust)", Unit: "m/s"},
+	{0, 3, 0}:  {Name: "PRES", Description: "Pressure", Unit: "Pa"},
+	{0, 3, 1}:  {Name: "PRMSL", Description: "Pressure reduced to MSL", Unit: "Pa"},
+	{0, 3, 5}:  {Name: "HGT", Description: "Geopotential height", Unit: "gpm"},
+	{0, 6, 1}:  {Name: "TCDC", Description: "Total cloud cover", Unit: "%"},
+	{0, 19, 0}: {Name: "VIS", Description: "Visibility", Unit: "m"},
+}
+
+// LookupParameter returns the parameter, unknown parameters are named like VAR0-1-2 after their codes
+func LookupParameter(discipline, category, number int) Parameter {
+	if p, ok := parameters[parameterKey{discipline, category, number}]; ok {
+		return p
+	}
+
+	return Parameter{Name: fmt.Sprintf("VAR%d-%d-%d", discipline, category, number)}
+}
@@ -0,0 +1,101 @@
+package grib2
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+const (
+	indicatorLength = 16
+	maxMessageSize  = 1 << 30
+)
+
+var (
+	magic      = []byte("GRIB")
+	endSection = []byte("7777")
+)
+
+// Reader reads GRIB2 messages from the stream. Bytes between messages, e.g. WMO bulletin headers, are skipped
+type Reader struct {
+	r *bufio.Reader
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next decodes the next message and returns its fields. io.EOF is returned when there are no more messages
+func (r *Reader) Next() ([]Field, error) {
+	if err := r.skipToMagic(); err != nil {
+		return nil, err
+	}
+
+	indicator := make([]byte, indicatorLength)
+	if _, err := io.ReadFull(r.r, indicator); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	if edition := int(indicator[7]); edition != 2 {
+		return nil, &UnsupportedError{Section: 0, Template: edition}
+	}
+
+	length := binary.BigEndian.Uint64(indicator[8:16])
+	if length < uint64(indicatorLength+len(endSection)) || length > maxMessageSize {
+		return nil, &FormatError{Section: 0, Msg: "invalid message length"}
+	}
+
+	msg := make([]byte, length)
+	copy(msg, indicator)
+
+	if _, err := io.ReadFull(r.r, msg[indicatorLength:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	return decodeMessage(msg)
+}
+
+// ReadAll decodes fields of all messages from r
+func ReadAll(r io.Reader) ([]Field, error) {
+	reader := NewReader(r)
+
+	var fields []Field
+	for {
+		msgFields, err := reader.Next()
+		if err == io.EOF {
+			return fields, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, msgFields...)
+	}
+}
+
+func (r *Reader) skipToMagic() error {
+	for {
+		head, err := r.r.Peek(len(magic))
+		if err != nil {
+			return err
+		}
+
+		if bytes.Equal(head, magic) {
+			return nil
+		}
+
+		if _, err := r.r.Discard(1); err != nil {
+			return err
+		}
+	}
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
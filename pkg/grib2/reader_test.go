@@ -0,0 +1,189 @@
+package grib2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReadAll(t *testing.T) {
+	nan := math.NaN()
+	grid := Grid{Points: 6, Ni: 3, Nj: 2, La1: 60, Lo1: 30, La2: 59, Lo2: 32, Di: 1, Dj: 1}
+
+	simple := func(values ...uint64) []byte {
+		var w bitWriter
+		for _, v := range values {
+			w.write(v, 8)
+		}
+
+		return w.data
+	}
+
+	tests := []struct {
+		name   string
+		fields [][]byte
+		want   [][]float64
+	}{
+		{
+			name:   "without bitmap",
+			fields: [][]byte{fieldSections(grid, 6, bitmapSection(bitmapNone), simple(1, 2, 3, 4, 5, 6))},
+			want:   [][]float64{{1, 2, 3, 4, 5, 6}},
+		},
+		{
+			name:   "bitmap",
+			fields: [][]byte{fieldSections(grid, 4, bitmapSection(bitmapFollows, 0xB4), simple(1, 2, 3, 4))},
+			want:   [][]float64{{1, nan, 2, 3, nan, 4}},
+		},
+		{
+			name: "previous bitmap is reused",
+			fields: [][]byte{
+				fieldSections(grid, 4, bitmapSection(bitmapFollows, 0xB4), simple(1, 2, 3, 4)),
+				fieldSections(grid, 4, bitmapSection(bitmapPrevious), simple(5, 6, 7, 8)),
+			},
+			want: [][]float64{{1, nan, 2, 3, nan, 4}, {5, nan, 6, 7, nan, 8}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// messages may be wrapped in WMO bulletins, the heading is skipped
+			input := append([]byte("YTXA98 UUWW 181200\r\r\n"), encodeMessage(tt.fields...)...)
+
+			fields, err := ReadAll(bytes.NewReader(input))
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+
+			if len(fields) != len(tt.want) {
+				t.Fatalf("ReadAll() returned %d fields, want %d", len(fields), len(tt.want))
+			}
+
+			for i, field := range fields {
+				expectValues(t, field.Values, tt.want[i])
+			}
+
+			field := fields[0]
+			if field.Centre != 74 || field.Grid != grid {
+				t.Errorf("centre = %d, grid = %+v, want 74 and %+v", field.Centre, field.Grid, grid)
+			}
+
+			validTime := time.Date(2026, 10, 18, 18, 0, 0, 0, time.UTC)
+			if field.ValidTime() != validTime || field.Product.Surface != (Surface{Type: SurfaceHeightAboveGround, Value: 2}) {
+				t.Errorf("valid time = %v, surface = %+v, want %v at 2 m above ground", field.ValidTime(), field.Product.Surface, validTime)
+			}
+
+			if p := field.Parameter(); p.Name != "TMP" {
+				t.Errorf("parameter = %+v, want TMP", p)
+			}
+		})
+	}
+}
+
+func TestReadAllErrors(t *testing.T) {
+	grid := Grid{Points: 6, Ni: 3, Nj: 2, La1: 60, Lo1: 30, La2: 59, Lo2: 32, Di: 1, Dj: 1}
+	values := []byte{1, 2, 3, 4}
+
+	edition := encodeMessage(fieldSections(grid, 4, bitmapSection(bitmapNone), values))
+	edition[7] = 1
+
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{name: "GRIB edition 1", input: edition},
+		{name: "values don't match grid size", input: encodeMessage(fieldSections(grid, 4, bitmapSection(bitmapNone), values))},
+		{name: "values don't match bitmap", input: encodeMessage(fieldSections(grid, 4, bitmapSection(bitmapFollows, 0xFC), values))},
+		{name: "no previous bitmap", input: encodeMessage(fieldSections(grid, 4, bitmapSection(bitmapPrevious), values))},
+		{name: "truncated message", input: encodeMessage(fieldSections(grid, 4, bitmapSection(bitmapNone), values))[:100]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadAll(bytes.NewReader(tt.input)); err == nil {
+				t.Error("ReadAll() error = nil, want error")
+			}
+		})
+	}
+
+	if fields, err := ReadAll(bytes.NewReader([]byte("no messages here"))); err != nil || len(fields) != 0 {
+		t.Errorf("ReadAll() = %v, %v, want no fields", fields, err)
+	}
+
+	if _, err := NewReader(bytes.NewReader(nil)).Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next() error = %v, want io.EOF", err)
+	}
+}
+
+// encodeMessage builds GRIB2 message of ECMWF centre issued at 2026-10-18 12:00 UTC with the fields sections
+func encodeMessage(fields ...[]byte) []byte {
+	identification := make([]byte, 21)
+	binary.BigEndian.PutUint32(identification, 21)
+	identification[4] = 1
+	binary.BigEndian.PutUint16(identification[5:], 74)
+	binary.BigEndian.PutUint16(identification[12:], 2026)
+	identification[14], identification[15], identification[16] = 10, 18, 12
+
+	body := identification
+	for _, field := range fields {
+		body = append(body, field...)
+	}
+
+	msg := append([]byte("GRIB\x00\x00\x00\x02"), make([]byte, 8)...)
+	msg = append(msg, body...)
+	msg = append(msg, endSection...)
+	binary.BigEndian.PutUint64(msg[8:], uint64(len(msg)))
+
+	return msg
+}
+
+// fieldSections builds sections 3 to 7 of 6 hour forecast of 2 m temperature packed in octets
+func fieldSections(g Grid, values int, bitmap []byte, data []byte) []byte {
+	grid := make([]byte, 72)
+	binary.BigEndian.PutUint32(grid, 72)
+	grid[4] = 3
+	binary.BigEndian.PutUint32(grid[6:], uint32(g.Points))
+	binary.BigEndian.PutUint32(grid[30:], uint32(g.Ni))
+	binary.BigEndian.PutUint32(grid[34:], uint32(g.Nj))
+	binary.BigEndian.PutUint32(grid[46:], uint32(g.La1*1e6))
+	binary.BigEndian.PutUint32(grid[50:], uint32(g.Lo1*1e6))
+	grid[54] = 0x30
+	binary.BigEndian.PutUint32(grid[55:], uint32(g.La2*1e6))
+	binary.BigEndian.PutUint32(grid[59:], uint32(g.Lo2*1e6))
+	binary.BigEndian.PutUint32(grid[63:], uint32(g.Di*1e6))
+	binary.BigEndian.PutUint32(grid[67:], uint32(g.Dj*1e6))
+	grid[71] = g.ScanMode
+
+	product := make([]byte, 34)
+	binary.BigEndian.PutUint32(product, 34)
+	product[4] = 4
+	product[17] = 1
+	binary.BigEndian.PutUint32(product[18:], 6)
+	product[22] = SurfaceHeightAboveGround
+	binary.BigEndian.PutUint32(product[24:], 2)
+
+	drs := encodeDataRepresentation(dataRepresentation{template: packingSimple, values: values, bits: 8})
+
+	res := append(grid, product...)
+	res = append(res, drs...)
+	res = append(res, bitmap...)
+
+	length := make([]byte, 5)
+	binary.BigEndian.PutUint32(length, uint32(len(data)+5))
+	length[4] = 7
+
+	res = append(res, length...)
+
+	return append(res, data...)
+}
+
+func bitmapSection(indicator byte, bits ...byte) []byte {
+	sec := make([]byte, 6, 6+len(bits))
+	binary.BigEndian.PutUint32(sec, uint32(6+len(bits)))
+	sec[4], sec[5] = 6, indicator
+
+	return append(sec, bits...)
+}
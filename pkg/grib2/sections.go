@@ -0,0 +1,341 @@
+package grib2
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+const (
+	bitmapFollows  = 0
+	bitmapPrevious = 254
+	bitmapNone     = 255
+
+	missingUint32 = 0xFFFFFFFF
+)
+
+// message holds sections decoded so far, later fields of the message reuse previous sections
+type message struct {
+	discipline    int
+	centre        int
+	referenceTime time.Time
+	grid          *Grid
+	product       *Product
+	data          *dataRepresentation
+	bitmap        []bool
+	hasBitmap     bool
+}
+
+func decodeMessage(msg []byte) ([]Field, error) {
+	m := message{discipline: int(msg[6])}
+
+	var fields []Field
+	for pos := indicatorLength; ; {
+		if pos+len(endSection) > len(msg) {
+			return nil, &FormatError{Section: 8, Msg: "end section is missing"}
+		}
+
+		if string(msg[pos:pos+len(endSection)]) == string(endSection) {
+			return fields, nil
+		}
+
+		if pos+5 > len(msg) {
+			return nil, &FormatError{Section: 0, Msg: "message is truncated"}
+		}
+
+		length := int(binary.BigEndian.Uint32(msg[pos:]))
+		number := int(msg[pos+4])
+		if length < 5 || pos+length > len(msg) {
+			return nil, &FormatError{Section: number, Msg: "invalid section length"}
+		}
+
+		sec := msg[pos : pos+length]
+		pos += length
+
+		var err error
+		switch number {
+		case 1:
+			err = m.decodeIdentification(sec)
+		case 2:
+			// local use section
+		case 3:
+			m.grid, err = decodeGrid(sec)
+		case 4:
+			m.product, err = m.decodeProduct(sec)
+		case 5:
+			m.data, err = decodeDataRepresentation(sec)
+		case 6:
+			err = m.decodeBitmap(sec)
+		case 7:
+			var field Field
+			if field, err = m.decodeData(sec); err == nil {
+				fields = append(fields, field)
+			}
+		default:
+			err = &FormatError{Section: number, Msg: "unknown section"}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (m *message) decodeIdentification(sec []byte) error {
+	if len(sec) < 21 {
+		return &FormatError{Section: 1, Msg: "section is too short"}
+	}
+
+	m.centre = int(binary.BigEndian.Uint16(sec[5:]))
+	m.referenceTime = time.Date(int(binary.BigEndian.Uint16(sec[12:])), time.Month(sec[14]), int(sec[15]),
+		int(sec[16]), int(sec[17]), int(sec[18]), 0, time.UTC)
+
+	return nil
+}
+
+func decodeGrid(sec []byte) (*Grid, error) {
+	if len(sec) < 14 {
+		return nil, &FormatError{Section: 3, Msg: "section is too short"}
+	}
+
+	template := int(binary.BigEndian.Uint16(sec[12:]))
+	if sec[5] != 0 || template != 0 {
+		return nil, &UnsupportedError{Section: 3, Template: template}
+	}
+
+	if len(sec) < 72 {
+		return nil, &FormatError{Section: 3, Msg: "template 3.0 is too short"}
+	}
+
+	grid := &Grid{
+		Template: template,
+		Points:   int(binary.BigEndian.Uint32(sec[6:])),
+		Ni:       int(binary.BigEndian.Uint32(sec[30:])),
+		Nj:       int(binary.BigEndian.Uint32(sec[34:])),
+		ScanMode: sec[71],
+	}
+
+	if grid.Ni <= 0 || grid.Nj <= 0 || grid.Ni*grid.Nj != grid.Points {
+		return nil, &FormatError{Section: 3, Msg: "number of points doesn't match grid size"}
+	}
+
+	if grid.ScanMode&ScanConsecutiveJ != 0 {
+		return nil, &FormatError{Section: 3, Msg: "scanning with consecutive points along meridian isn't supported"}
+	}
+
+	// angles are in millionths of degree unless the basic angle is given
+	unit := 1e-6
+	basic, subdivisions := binary.BigEndian.Uint32(sec[38:]), binary.BigEndian.Uint32(sec[42:])
+	if basic != 0 && basic != missingUint32 && subdivisions != 0 && subdivisions != missingUint32 {
+		unit = float64(basic) / float64(subdivisions)
+	}
+
+	grid.La1 = float64(int32SignMagnitude(sec[46:])) * unit
+	grid.Lo1 = float64(int32SignMagnitude(sec[50:])) * unit
+	grid.La2 = float64(int32SignMagnitude(sec[55:])) * unit
+	grid.Lo2 = float64(int32SignMagnitude(sec[59:])) * unit
+
+	flags := sec[54]
+	di, dj := binary.BigEndian.Uint32(sec[63:]), binary.BigEndian.Uint32(sec[67:])
+
+	if flags&0x20 != 0 && di != missingUint32 {
+		grid.Di = float64(di) * unit
+	} else if grid.Ni > 1 {
+		span := math.Mod(grid.Lo2-grid.Lo1+360, 360)
+		if grid.ScanMode&ScanNegativeI != 0 {
+			span = math.Mod(grid.Lo1-grid.Lo2+360, 360)
+		}
+
+		grid.Di = span / float64(grid.Ni-1)
+	}
+
+	if flags&0x10 != 0 && dj != missingUint32 {
+		grid.Dj = float64(dj) * unit
+	} else if grid.Nj > 1 {
+		grid.Dj = math.Abs(grid.La2-grid.La1) / float64(grid.Nj-1)
+	}
+
+	return grid, nil
+}
+
+func (m *message) decodeProduct(sec []byte) (*Product, error) {
+	if len(sec) < 9 {
+		return nil, &FormatError{Section: 4, Msg: "section is too short"}
+	}
+
+	template := int(binary.BigEndian.Uint16(sec[7:]))
+
+	// templates differ by data following the common part ending with the second fixed surface
+	var intervalEnd int
+	switch template {
+	case 0, 1:
+	case 8:
+		intervalEnd = 34
+	case 11:
+		intervalEnd = 37
+	default:
+		return nil, &UnsupportedError{Section: 4, Template: template}
+	}
+
+	if len(sec) < 34 || (intervalEnd > 0 && len(sec) < intervalEnd+13) {
+		return nil, &FormatError{Section: 4, Msg: "template is too short"}
+	}
+
+	unit, ok := timeUnit(sec[17])
+	if !ok {
+		return nil, &FormatError{Section: 4, Msg: "unsupported unit of time range"}
+	}
+
+	product := &Product{
+		Template:     template,
+		Category:     int(sec[9]),
+		Number:       int(sec[10]),
+		ForecastTime: time.Duration(int32SignMagnitude(sec[18:])) * unit,
+		Surface:      Surface{Type: int(sec[22])},
+	}
+
+	if product.Surface.Type != SurfaceMissing && sec[23] != 0xFF {
+		scale := int(int8SignMagnitude(sec[23]))
+		product.Surface.Value = float64(int32SignMagnitude(sec[24:])) / math.Pow(10, float64(scale))
+	}
+
+	if intervalEnd > 0 {
+		end := sec[intervalEnd:]
+		product.Statistical = &Statistical{
+			Process: int(sec[intervalEnd+12]),
+			Start:   m.referenceTime.Add(product.ForecastTime),
+			End: time.Date(int(binary.BigEndian.Uint16(end)), time.Month(end[2]), int(end[3]),
+				int(end[4]), int(end[5]), int(end[6]), 0, time.UTC),
+		}
+	}
+
+	return product, nil
+}
+
+func (m *message) decodeBitmap(sec []byte) error {
+	if len(sec) < 6 {
+		return &FormatError{Section: 6, Msg: "section is too short"}
+	}
+
+	switch sec[5] {
+	case bitmapNone:
+		m.bitmap, m.hasBitmap = nil, false
+	case bitmapPrevious:
+		if m.bitmap == nil {
+			return &FormatError{Section: 6, Msg: "there is no previous bitmap"}
+		}
+
+		m.hasBitmap = true
+	case bitmapFollows:
+		if m.grid == nil {
+			return &FormatError{Section: 6, Msg: "bitmap precedes grid definition"}
+		}
+
+		bits := sec[6:]
+		if len(bits)*8 < m.grid.Points {
+			return &FormatError{Section: 6, Msg: "bitmap is too short"}
+		}
+
+		m.bitmap = make([]bool, m.grid.Points)
+		for i := range m.bitmap {
+			m.bitmap[i] = bits[i/8]&(0x80>>uint(i%8)) != 0
+		}
+
+		m.hasBitmap = true
+	default:
+		return &FormatError{Section: 6, Msg: "predefined bitmaps aren't supported"}
+	}
+
+	return nil
+}
+
+func (m *message) decodeData(sec []byte) (Field, error) {
+	if m.grid == nil || m.product == nil || m.data == nil {
+		return Field{}, &FormatError{Section: 7, Msg: "data precedes grid, product or data representation definition"}
+	}
+
+	packed, err := m.data.unpack(sec[5:])
+	if err != nil {
+		return Field{}, err
+	}
+
+	values := packed
+	if m.hasBitmap {
+		values = make([]float64, m.grid.Points)
+
+		n := 0
+		for i, present := range m.bitmap {
+			if !present {
+				values[i] = math.NaN()
+				continue
+			}
+
+			if n == len(packed) {
+				return Field{}, &FormatError{Section: 7, Msg: "number of values doesn't match bitmap"}
+			}
+
+			values[i] = packed[n]
+			n++
+		}
+	} else if len(values) != m.grid.Points {
+		return Field{}, &FormatError{Section: 7, Msg: "number of values doesn't match grid size"}
+	}
+
+	return Field{
+		Discipline:    m.discipline,
+		Centre:        m.centre,
+		ReferenceTime: m.referenceTime,
+		Grid:          *m.grid,
+		Product:       *m.product,
+		Values:        values,
+	}, nil
+}
+
+// timeUnit maps WMO code table 4.4 to duration
+func timeUnit(code byte) (time.Duration, bool) {
+	switch code {
+	case 0:
+		return time.Minute, true
+	case 1:
+		return time.Hour, true
+	case 2:
+		return 24 * time.Hour, true
+	case 10:
+		return 3 * time.Hour, true
+	case 11:
+		return 6 * time.Hour, true
+	case 12:
+		return 12 * time.Hour, true
+	case 13:
+		return time.Second, true
+	default:
+		return 0, false
+	}
+}
+
+// GRIB2 signed integers are stored as sign bit followed by magnitude
+func int32SignMagnitude(b []byte) int32 {
+	v := binary.BigEndian.Uint32(b)
+	if v&0x80000000 != 0 {
+		return -int32(v & 0x7FFFFFFF)
+	}
+
+	return int32(v)
+}
+
+func int16SignMagnitude(b []byte) int16 {
+	v := binary.BigEndian.Uint16(b)
+	if v&0x8000 != 0 {
+		return -int16(v & 0x7FFF)
+	}
+
+	return int16(v)
+}
+
+func int8SignMagnitude(b byte) int8 {
+	if b&0x80 != 0 {
+		return -int8(b & 0x7F)
+	}
+
+	return int8(b)
+}
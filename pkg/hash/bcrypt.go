@@ -0,0 +1,43 @@
+package hash
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BCryptHasher creates hashes in modular crypt format, e.g. $2a$12$<salt and key>, cost is read from the hash
+// on verification. Note that bcrypt uses only the first 72 bytes of the password
+type BCryptHasher struct {
+	cost int
+}
+
+func NewBCryptHasher(cost int) *BCryptHasher {
+	return &BCryptHasher{cost: cost}
+}
+
+func (h *BCryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+func (h *BCryptHasher) Verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+
+	return true, nil
+}
+
+func (h *BCryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+
+	return err != nil || cost != h.cost
+}
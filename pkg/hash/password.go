@@ -2,13 +2,48 @@ package hash
 
 import (
 	"crypto/sha1"
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"strings"
 )
 
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBCrypt   = "bcrypt"
+	AlgorithmSHA1     = "sha1"
+)
+
+var (
+	ErrInvalidHash          = errors.New("invalid password hash")
+	ErrUnsupportedAlgorithm = errors.New("unsupported password hash algorithm")
+)
+
+// PasswordHasher hashes passwords and verifies them against stored hashes.
+// NeedsRehash reports whether the hash was created by other algorithm or with other parameters
 type PasswordHasher interface {
 	Hash(password string) (string, error)
+	Verify(hash, password string) (bool, error)
+	NeedsRehash(hash string) bool
 }
 
+// Algorithm returns algorithm the hash was created with. Argon2id hashes are in PHC string format, bcrypt ones
+// are in modular crypt format, hashes without algorithm identifier are legacy SHA-1 ones
+func Algorithm(hash string) string {
+	switch {
+	case strings.HasPrefix(hash, "$"+AlgorithmArgon2id+"$"):
+		return AlgorithmArgon2id
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return AlgorithmBCrypt
+	case strings.HasPrefix(hash, "$"):
+		return ""
+	default:
+		return AlgorithmSHA1
+	}
+}
+
+// SHA1Hasher is kept to verify legacy hashes only, SHA-1 is unfit for passwords.
+// Note that it prepends hex encoded salt to the digest instead of hashing it, stored hashes depend on that
 type SHA1Hasher struct {
 	salt string
 }
@@ -27,3 +62,61 @@ func (h *SHA1Hasher) Hash(password string) (string, error) {
 
 	return fmt.Sprintf("%x", hash.Sum([]byte(h.salt))), nil
 }
+
+func (h *SHA1Hasher) Verify(hash, password string) (bool, error) {
+	if Algorithm(hash) != AlgorithmSHA1 {
+		return false, ErrUnsupportedAlgorithm
+	}
+
+	expected, err := h.Hash(password)
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(expected)) == 1, nil
+}
+
+// NeedsRehash is always true, so legacy hashes are replaced on successful verification
+func (h *SHA1Hasher) NeedsRehash(hash string) bool {
+	return true
+}
+
+// MultiHasher hashes passwords with the preferred hasher and verifies hashes of any known algorithm,
+// so stored hashes can be upgraded to the preferred one on login
+type MultiHasher struct {
+	preferred  PasswordHasher
+	algorithm  string
+	algorithms map[string]PasswordHasher
+}
+
+// NewMultiHasher creates hasher preferring the algorithm. Legacy SHA-1 hashes are verified with the salt
+func NewMultiHasher(algorithm string, argon2id *Argon2idHasher, bcrypt *BCryptHasher, salt string) (*MultiHasher, error) {
+	algorithms := map[string]PasswordHasher{
+		AlgorithmArgon2id: argon2id,
+		AlgorithmBCrypt:   bcrypt,
+		AlgorithmSHA1:     NewSHA1Hasher(salt),
+	}
+
+	if algorithm == AlgorithmSHA1 || algorithms[algorithm] == nil {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	return &MultiHasher{preferred: algorithms[algorithm], algorithm: algorithm, algorithms: algorithms}, nil
+}
+
+func (h *MultiHasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+func (h *MultiHasher) Verify(hash, password string) (bool, error) {
+	hasher, ok := h.algorithms[Algorithm(hash)]
+	if !ok {
+		return false, ErrUnsupportedAlgorithm
+	}
+
+	return hasher.Verify(hash, password)
+}
+
+func (h *MultiHasher) NeedsRehash(hash string) bool {
+	return Algorithm(hash) != h.algorithm || h.preferred.NeedsRehash(hash)
+}
@@ -0,0 +1,394 @@
+package metar
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	stationRegex      = regexp.MustCompile(`^[A-Z][A-Z0-9]{3}$`)
+	timeRegex         = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	windRegex         = regexp.MustCompile(`^(\d{3}|VRB|///)(\d{2,3}|//)(?:G(\d{2,3}))?(KT|MPS|KMH)$`)
+	windVariableRegex = regexp.MustCompile(`^(\d{3})V(\d{3})$`)
+	visibilityRegex   = regexp.MustCompile(`^(\d{4})(NDV)?$`)
+	minVisRegex       = regexp.MustCompile(`^(\d{4})(N|NE|E|SE|S|SW|W|NW)$`)
+	visibilitySMRegex = regexp.MustCompile(`^([MP])?(?:(\d+)|(\d+)/(\d+))SM$`)
+	wholeMilesRegex   = regexp.MustCompile(`^\d$`)
+	fractionSMRegex   = regexp.MustCompile(`^\d/\d+SM$`)
+	rvrRegex          = regexp.MustCompile(`^R(\d{2}[LCR]?)/([PM])?(\d{4})(?:V([PM])?(\d{4}))?(FT)?/?([UDN])?$`)
+	weatherRegex      = regexp.MustCompile(`^(-|\+|VC)?(MI|PR|BC|DR|BL|SH|TS|FZ)?((?:DZ|RA|SN|SG|IC|PL|GR|GS|UP|BR|FG|FU|VA|DU|SA|HZ|PY|PO|SQ|FC|SS|DS)*)$`)
+	recentWxRegex     = regexp.MustCompile(`^RE([A-Z]+)$`)
+	cloudRegex        = regexp.MustCompile(`^(FEW|SCT|BKN|OVC)(\d{3}|///)(CB|TCU|///)?$`)
+	vertVisRegex      = regexp.MustCompile(`^VV(\d{3}|///)$`)
+	temperatureRegex  = regexp.MustCompile(`^(M?\d{2}|//)/(M?\d{2}|//)?$`)
+	qnhRegex          = regexp.MustCompile(`^Q(\d{4})$`)
+	altimeterRegex    = regexp.MustCompile(`^A(\d{4})$`)
+	missingRegex      = regexp.MustCompile(`^/+$`)
+)
+
+// Decode parses METAR or SPECI report. Report may be prefixed with report type and terminated with "="
+func Decode(report string) (Report, error) {
+	d := decoder{groups: strings.Fields(strings.TrimSuffix(strings.TrimSpace(report), "="))}
+	return d.decode()
+}
+
+// DecodeGroups decodes weather groups (wind, visibility, RVR, weather, clouds, temperature, pressure)
+// without report header. It's used to decode conditions of forecast change groups
+func DecodeGroups(groups []string) (Report, error) {
+	d := decoder{groups: groups}
+	for d.pos < len(d.groups) {
+		if err := d.decodeGroup(d.next()); err != nil {
+			return Report{}, err
+		}
+	}
+
+	return d.res, nil
+}
+
+type decoder struct {
+	groups []string
+	pos    int
+	res    Report
+}
+
+func (d *decoder) peek() string {
+	if d.pos < len(d.groups) {
+		return d.groups[d.pos]
+	}
+
+	return ""
+}
+
+func (d *decoder) next() string {
+	group := d.peek()
+	d.pos++
+
+	return group
+}
+
+func (d *decoder) decode() (Report, error) {
+	d.res.Type = TypeMETAR
+	if group := d.peek(); group == TypeMETAR || group == TypeSPECI {
+		d.res.Type = d.next()
+	}
+
+	if d.peek() == "COR" {
+		d.res.Corrected = true
+		d.next()
+	}
+
+	station := d.next()
+	if !stationRegex.MatchString(station) {
+		return Report{}, &ParseError{Group: station, Msg: "expected ICAO station identifier"}
+	}
+
+	d.res.Station = station
+
+	if err := d.decodeTime(d.next()); err != nil {
+		return Report{}, err
+	}
+
+	for d.pos < len(d.groups) {
+		group := d.next()
+
+		switch {
+		case group == "NIL":
+			d.res.Nil = true
+			return d.res, nil
+		case group == "AUTO":
+			d.res.Auto = true
+		case group == "COR":
+			d.res.Corrected = true
+		case group == "RMK":
+			d.res.Remarks = strings.Join(d.groups[d.pos:], " ")
+			return d.res, nil
+		case group == "NOSIG" || group == "BECMG" || group == "TEMPO":
+			d.decodeTrend(group)
+		default:
+			if err := d.decodeGroup(group); err != nil {
+				return Report{}, err
+			}
+		}
+	}
+
+	return d.res, nil
+}
+
+func (d *decoder) decodeTime(group string) error {
+	m := timeRegex.FindStringSubmatch(group)
+	if m == nil {
+		return &ParseError{Group: group, Msg: "expected observation time DDHHMMZ"}
+	}
+
+	d.res.Day, _ = strconv.Atoi(m[1])
+	d.res.Hour, _ = strconv.Atoi(m[2])
+	d.res.Minute, _ = strconv.Atoi(m[3])
+
+	if d.res.Day < 1 || d.res.Day > 31 || d.res.Hour > 23 || d.res.Minute > 59 {
+		return &ParseError{Group: group, Msg: "observation time is out of range"}
+	}
+
+	return nil
+}
+
+// decodeTrend consumes trend groups up to remarks
+func (d *decoder) decodeTrend(first string) {
+	trend := []string{first}
+	for d.pos < len(d.groups) && d.peek() != "RMK" {
+		trend = append(trend, d.next())
+	}
+
+	d.res.Trend = strings.Join(trend, " ")
+}
+
+func (d *decoder) decodeGroup(group string) error {
+	if m := windRegex.FindStringSubmatch(group); m != nil {
+		return d.decodeWind(group, m)
+	}
+
+	if m := windVariableRegex.FindStringSubmatch(group); m != nil {
+		if d.res.Wind == nil {
+			return &ParseError{Group: group, Msg: "variable wind direction without wind group"}
+		}
+
+		d.res.Wind.VariableFrom, _ = strconv.Atoi(m[1])
+		d.res.Wind.VariableTo, _ = strconv.Atoi(m[2])
+
+		return nil
+	}
+
+	if group == "CAVOK" {
+		d.res.CAVOK = true
+		return nil
+	}
+
+	if d.decodeVisibility(group) {
+		return nil
+	}
+
+	if m := rvrRegex.FindStringSubmatch(group); m != nil {
+		d.res.RunwayVisualRanges = append(d.res.RunwayVisualRanges, decodeRVR(m))
+		return nil
+	}
+
+	if m := cloudRegex.FindStringSubmatch(group); m != nil {
+		layer := CloudLayer{Cover: m[1], Height: parseHeight(m[2])}
+		if m[3] != "///" {
+			layer.Type = m[3]
+		}
+
+		d.res.Clouds = append(d.res.Clouds, layer)
+
+		return nil
+	}
+
+	if m := vertVisRegex.FindStringSubmatch(group); m != nil {
+		d.res.VerticalVisibility = parseHeight(m[1])
+		return nil
+	}
+
+	switch group {
+	case "SKC", "CLR", "NSC", "NCD":
+		d.res.Clouds = append(d.res.Clouds, CloudLayer{Cover: group})
+		return nil
+	case "NSW":
+		return nil
+	}
+
+	if m := temperatureRegex.FindStringSubmatch(group); m != nil {
+		d.res.Temperature = parseTemperature(m[1])
+		d.res.DewPoint = parseTemperature(m[2])
+
+		return nil
+	}
+
+	if m := qnhRegex.FindStringSubmatch(group); m != nil {
+		value, _ := strconv.Atoi(m[1])
+		d.res.Pressure = &Pressure{Value: float64(value), Unit: UnitHectopascals}
+
+		return nil
+	}
+
+	if m := altimeterRegex.FindStringSubmatch(group); m != nil {
+		value, _ := strconv.Atoi(m[1])
+		d.res.Pressure = &Pressure{Value: float64(value) / 100, Unit: UnitInchesMercury}
+
+		return nil
+	}
+
+	if m := recentWxRegex.FindStringSubmatch(group); m != nil {
+		if wx, ok := decodeWeather(m[1]); ok {
+			d.res.RecentWeather = append(d.res.RecentWeather, wx)
+			return nil
+		}
+	}
+
+	if wx, ok := decodeWeather(group); ok {
+		d.res.Weather = append(d.res.Weather, wx)
+		return nil
+	}
+
+	if group == "WS" {
+		d.skipWindShear()
+		return nil
+	}
+
+	if !missingRegex.MatchString(group) {
+		d.res.Unparsed = append(d.res.Unparsed, group)
+	}
+
+	return nil
+}
+
+func (d *decoder) decodeWind(group string, m []string) error {
+	wind := &Wind{Unit: m[4]}
+
+	switch m[1] {
+	case "VRB":
+		wind.Variable = true
+	case "///":
+		wind.DirectionUnknown = true
+	default:
+		wind.Direction, _ = strconv.Atoi(m[1])
+		if wind.Direction > 360 {
+			return &ParseError{Group: group, Msg: "wind direction is out of range"}
+		}
+	}
+
+	if m[2] == "//" {
+		if m[1] == "///" {
+			return nil
+		}
+
+		return &ParseError{Group: group, Msg: "wind speed is missing"}
+	}
+
+	wind.Speed, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		wind.Gust, _ = strconv.Atoi(m[3])
+	}
+
+	d.res.Wind = wind
+
+	return nil
+}
+
+func (d *decoder) decodeVisibility(group string) bool {
+	if m := visibilityRegex.FindStringSubmatch(group); m != nil && d.res.Visibility == nil {
+		distance, _ := strconv.Atoi(m[1])
+		d.res.Visibility = &Visibility{Distance: float64(distance), Unit: UnitMeters, MoreThan: distance == 9999}
+
+		return true
+	}
+
+	if m := minVisRegex.FindStringSubmatch(group); m != nil {
+		distance, _ := strconv.Atoi(m[1])
+		d.res.MinVisibility = &Visibility{Distance: float64(distance), Unit: UnitMeters, Direction: m[2]}
+
+		return true
+	}
+
+	// whole and fractional statute miles are separate groups, e.g. "1 1/2SM"
+	if wholeMilesRegex.MatchString(group) && fractionSMRegex.MatchString(d.peek()) {
+		whole, _ := strconv.Atoi(group)
+		m := visibilitySMRegex.FindStringSubmatch(d.next())
+		d.res.Visibility = &Visibility{Distance: float64(whole) + fraction(m[3], m[4]), Unit: UnitStatuteMiles}
+
+		return true
+	}
+
+	if m := visibilitySMRegex.FindStringSubmatch(group); m != nil {
+		visibility := &Visibility{Unit: UnitStatuteMiles, LessThan: m[1] == "M", MoreThan: m[1] == "P"}
+		if m[2] != "" {
+			miles, _ := strconv.Atoi(m[2])
+			visibility.Distance = float64(miles)
+		} else {
+			visibility.Distance = fraction(m[3], m[4])
+		}
+
+		d.res.Visibility = visibility
+
+		return true
+	}
+
+	return false
+}
+
+// skipWindShear consumes "WS R24", "WS RWY24" and "WS ALL RWY" groups
+func (d *decoder) skipWindShear() {
+	if d.peek() == "ALL" {
+		d.next()
+	}
+
+	if strings.HasPrefix(d.peek(), "R") {
+		d.next()
+	}
+}
+
+func decodeRVR(m []string) RunwayVisualRange {
+	rvr := RunwayVisualRange{Runway: m[1], Unit: UnitMeters, Tendency: m[7]}
+	if m[6] != "" {
+		rvr.Unit = UnitFeet
+	}
+
+	rvr.Min, _ = strconv.Atoi(m[3])
+	rvr.LessThan = m[2] == "M"
+	rvr.MoreThan = m[2] == "P"
+
+	if m[5] != "" {
+		rvr.Max, _ = strconv.Atoi(m[5])
+		rvr.MoreThan = rvr.MoreThan || m[4] == "P"
+	}
+
+	return rvr
+}
+
+func decodeWeather(group string) (Weather, bool) {
+	m := weatherRegex.FindStringSubmatch(group)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		return Weather{}, false
+	}
+
+	wx := Weather{Intensity: m[1], Descriptor: m[2]}
+	for i := 0; i+2 <= len(m[3]); i += 2 {
+		wx.Phenomena = append(wx.Phenomena, m[3][i:i+2])
+	}
+
+	return wx, true
+}
+
+func parseHeight(value string) *int {
+	if value == "///" {
+		return nil
+	}
+
+	hundreds, _ := strconv.Atoi(value)
+	feet := hundreds * 100
+
+	return &feet
+}
+
+func parseTemperature(value string) *int {
+	if value == "" || value == "//" {
+		return nil
+	}
+
+	negative := strings.HasPrefix(value, "M")
+	t, _ := strconv.Atoi(strings.TrimPrefix(value, "M"))
+	if negative {
+		t = -t
+	}
+
+	return &t
+}
+
+func fraction(numerator, denominator string) float64 {
+	n, _ := strconv.Atoi(numerator)
+	d, _ := strconv.Atoi(denominator)
+	if d == 0 {
+		return 0
+	}
+
+	return float64(n) / float64(d)
+}
@@ -0,0 +1,234 @@
+package metar
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		report  string
+		encoded string
+		check   func(t *testing.T, r Report)
+	}{
+		{
+			name:    "gust, showers and cumulonimbus",
+			report:  "METAR UUEE 181230Z 24008G15MPS 9999 -SHRA BKN020CB 12/08 Q1012 NOSIG=",
+			encoded: "METAR UUEE 181230Z 24008G15MPS 9999 -SHRA BKN020CB 12/08 Q1012 NOSIG=",
+			check: func(t *testing.T, r Report) {
+				expectWind(t, r, Wind{Direction: 240, Speed: 8, Gust: 15, Unit: UnitMetersPerSecond})
+				if r.Visibility == nil || r.Visibility.Distance != 9999 || !r.Visibility.MoreThan {
+					t.Errorf("visibility = %+v, want more than 9999 m", r.Visibility)
+				}
+				expectWeather(t, r.Weather, "-SHRA")
+				if len(r.Clouds) != 1 || r.Clouds[0].Cover != "BKN" || *r.Clouds[0].Height != 2000 || r.Clouds[0].Type != "CB" {
+					t.Errorf("clouds = %+v, want BKN at 2000 ft CB", r.Clouds)
+				}
+				expectInt(t, "temperature", r.Temperature, 12)
+				expectInt(t, "dew point", r.DewPoint, 8)
+				if r.Pressure == nil || r.Pressure.HPa() != 1012 {
+					t.Errorf("pressure = %+v, want 1012 hPa", r.Pressure)
+				}
+				if r.Trend != "NOSIG" {
+					t.Errorf("trend = %q, want NOSIG", r.Trend)
+				}
+			},
+		},
+		{
+			name:    "variable wind and CAVOK",
+			report:  "METAR EGLL 181220Z VRB03KT CAVOK 18/09 Q1021 NOSIG",
+			encoded: "METAR EGLL 181220Z VRB03KT CAVOK 18/09 Q1021 NOSIG=",
+			check: func(t *testing.T, r Report) {
+				expectWind(t, r, Wind{Variable: true, Speed: 3, Unit: UnitKnots})
+				if !r.CAVOK || r.Visibility != nil || len(r.Clouds) != 0 {
+					t.Errorf("CAVOK = %v, visibility = %+v, clouds = %+v, want CAVOK only", r.CAVOK, r.Visibility, r.Clouds)
+				}
+			},
+		},
+		{
+			name:    "SPECI with statute miles, feet RVR and remarks",
+			report:  "SPECI KJFK 181251Z 31015G25KT 1 1/2SM R04R/2200V4000FT -RA BR BKN008 OVC015 14/13 A2992 RMK AO2 PK WND 30030/1230 P0002",
+			encoded: "SPECI KJFK 181251Z 31015G25KT 1 1/2SM R04R/2200V4000FT -RA BR BKN008 OVC015 14/13 A2992 RMK AO2 PK WND 30030/1230 P0002=",
+			check: func(t *testing.T, r Report) {
+				if r.Type != TypeSPECI {
+					t.Errorf("type = %q, want SPECI", r.Type)
+				}
+				expectWind(t, r, Wind{Direction: 310, Speed: 15, Gust: 25, Unit: UnitKnots})
+				if r.Visibility == nil || r.Visibility.Distance != 1.5 || r.Visibility.Unit != UnitStatuteMiles {
+					t.Errorf("visibility = %+v, want 1.5 SM", r.Visibility)
+				}
+				expectRVR(t, r, RunwayVisualRange{Runway: "04R", Min: 2200, Max: 4000, Unit: UnitFeet})
+				expectWeather(t, r.Weather, "-RA", "BR")
+				if len(r.Clouds) != 2 || *r.Clouds[1].Height != 1500 {
+					t.Errorf("clouds = %+v, want BKN008 OVC015", r.Clouds)
+				}
+				if r.Pressure == nil || r.Pressure.Value != 29.92 || r.Pressure.Unit != UnitInchesMercury {
+					t.Errorf("pressure = %+v, want 29.92 inHg", r.Pressure)
+				}
+				if r.Remarks != "AO2 PK WND 30030/1230 P0002" {
+					t.Errorf("remarks = %q", r.Remarks)
+				}
+			},
+		},
+		{
+			name:   "automatic station with missing groups",
+			report: "METAR EDDF 181220Z AUTO ///04KT 9999 // BKN034/// 15/// Q1015",
+			check: func(t *testing.T, r Report) {
+				if !r.Auto {
+					t.Error("auto isn't set")
+				}
+				expectWind(t, r, Wind{DirectionUnknown: true, Speed: 4, Unit: UnitKnots})
+				if len(r.Clouds) != 1 || *r.Clouds[0].Height != 3400 || r.Clouds[0].Type != "" {
+					t.Errorf("clouds = %+v, want BKN at 3400 ft of unknown type", r.Clouds)
+				}
+				expectInt(t, "temperature", r.Temperature, 15)
+				if r.DewPoint != nil {
+					t.Errorf("dew point = %d, want missing", *r.DewPoint)
+				}
+				if len(r.Unparsed) != 0 {
+					t.Errorf("unparsed = %v, want none", r.Unparsed)
+				}
+			},
+		},
+		{
+			name:    "fog with RVR tendency and vertical visibility",
+			report:  "METAR UWWW 180300Z 00000MPS 0300 R15/0450U FG VV002 M02/M02 Q1030",
+			encoded: "METAR UWWW 180300Z 00000MPS 0300 R15/0450U FG VV002 M02/M02 Q1030=",
+			check: func(t *testing.T, r Report) {
+				expectWind(t, r, Wind{Unit: UnitMetersPerSecond})
+				if r.Visibility == nil || r.Visibility.Meters() != 300 {
+					t.Errorf("visibility = %+v, want 300 m", r.Visibility)
+				}
+				expectRVR(t, r, RunwayVisualRange{Runway: "15", Min: 450, Unit: UnitMeters, Tendency: "U"})
+				expectWeather(t, r.Weather, "FG")
+				expectInt(t, "vertical visibility", r.VerticalVisibility, 200)
+				expectInt(t, "temperature", r.Temperature, -2)
+				expectInt(t, "dew point", r.DewPoint, -2)
+			},
+		},
+		{
+			name:    "correction with variable direction, minimum visibility and recent weather",
+			report:  "METAR COR ULLI 181200Z 18005MPS 150V220 4000 1200NE +TSRA SCT015CB 20/18 Q1005 RETS",
+			encoded: "METAR COR ULLI 181200Z 18005MPS 150V220 4000 1200NE +TSRA SCT015CB 20/18 Q1005 RETS=",
+			check: func(t *testing.T, r Report) {
+				if !r.Corrected {
+					t.Error("corrected isn't set")
+				}
+				expectWind(t, r, Wind{Direction: 180, Speed: 5, Unit: UnitMetersPerSecond, VariableFrom: 150, VariableTo: 220})
+				if r.MinVisibility == nil || r.MinVisibility.Distance != 1200 || r.MinVisibility.Direction != "NE" {
+					t.Errorf("minimum visibility = %+v, want 1200 m to NE", r.MinVisibility)
+				}
+				expectWeather(t, r.Weather, "+TSRA")
+				expectWeather(t, r.RecentWeather, "TS")
+			},
+		},
+		{
+			name:    "US report with remarks",
+			report:  "METAR KORD 181251Z 27010KT 10SM FEW050 SCT250 22/11 A3001 RMK AO2 SLP163 T02170111",
+			encoded: "METAR KORD 181251Z 27010KT 10SM FEW050 SCT250 22/11 A3001 RMK AO2 SLP163 T02170111=",
+			check: func(t *testing.T, r Report) {
+				if r.Visibility == nil || r.Visibility.Distance != 10 || r.Visibility.Unit != UnitStatuteMiles {
+					t.Errorf("visibility = %+v, want 10 SM", r.Visibility)
+				}
+				if r.Remarks != "AO2 SLP163 T02170111" {
+					t.Errorf("remarks = %q", r.Remarks)
+				}
+			},
+		},
+		{
+			name:    "missing report",
+			report:  "METAR UUDD 181230Z NIL=",
+			encoded: "METAR UUDD 181230Z NIL=",
+			check: func(t *testing.T, r Report) {
+				if !r.Nil || r.Wind != nil {
+					t.Errorf("nil = %v, wind = %+v, want empty NIL report", r.Nil, r.Wind)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Decode(tt.report)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			tt.check(t, r)
+
+			encoded := Encode(r)
+			if tt.encoded != "" && encoded != tt.encoded {
+				t.Errorf("Encode() = %q, want %q", encoded, tt.encoded)
+			}
+
+			decoded, err := Decode(encoded)
+			if err != nil {
+				t.Fatalf("Decode(Encode()) error = %v", err)
+			}
+
+			if !reflect.DeepEqual(decoded, r) {
+				t.Errorf("Decode(Encode()) = %+v, want %+v", decoded, r)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		report string
+	}{
+		{name: "invalid station", report: "METAR XX 181230Z 24008MPS 9999"},
+		{name: "invalid time", report: "METAR UUEE 1812Z 24008MPS 9999"},
+		{name: "time out of range", report: "METAR UUEE 182460Z 24008MPS 9999"},
+		{name: "wind direction out of range", report: "METAR UUEE 181230Z 40010MPS 9999"},
+		{name: "missing wind speed", report: "METAR UUEE 181230Z 240//MPS 9999"},
+		{name: "variable direction without wind", report: "METAR UUEE 181230Z 150V220 9999"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.report); err == nil {
+				t.Errorf("Decode(%q) error = nil, want parse error", tt.report)
+			}
+		})
+	}
+}
+
+func expectWind(t *testing.T, r Report, want Wind) {
+	t.Helper()
+
+	if r.Wind == nil || *r.Wind != want {
+		t.Errorf("wind = %+v, want %+v", r.Wind, want)
+	}
+}
+
+func expectRVR(t *testing.T, r Report, want RunwayVisualRange) {
+	t.Helper()
+
+	if len(r.RunwayVisualRanges) != 1 || r.RunwayVisualRanges[0] != want {
+		t.Errorf("runway visual ranges = %+v, want %+v", r.RunwayVisualRanges, want)
+	}
+}
+
+func expectWeather(t *testing.T, weather []Weather, want ...string) {
+	t.Helper()
+
+	got := make([]string, len(weather))
+	for i, wx := range weather {
+		got[i] = wx.String()
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("weather = %v, want %v", got, want)
+	}
+}
+
+func expectInt(t *testing.T, name string, got *int, want int) {
+	t.Helper()
+
+	if got == nil || *got != want {
+		t.Errorf("%s = %v, want %d", name, got, want)
+	}
+}
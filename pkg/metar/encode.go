@@ -0,0 +1,203 @@
+package metar
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Encode formats report as METAR/SPECI text terminated with "="
+func Encode(r Report) string {
+	reportType := r.Type
+	if reportType == "" {
+		reportType = TypeMETAR
+	}
+
+	groups := []string{reportType}
+	if r.Corrected {
+		groups = append(groups, "COR")
+	}
+
+	groups = append(groups, r.Station, fmt.Sprintf("%02d%02d%02dZ", r.Day, r.Hour, r.Minute))
+
+	if r.Nil {
+		return strings.Join(append(groups, "NIL"), " ") + "="
+	}
+
+	if r.Auto {
+		groups = append(groups, "AUTO")
+	}
+
+	if r.Wind != nil {
+		groups = append(groups, encodeWind(*r.Wind)...)
+	}
+
+	if r.CAVOK {
+		groups = append(groups, "CAVOK")
+	} else {
+		if r.Visibility != nil {
+			groups = append(groups, encodeVisibility(*r.Visibility))
+		}
+
+		if r.MinVisibility != nil {
+			groups = append(groups, encodeVisibility(*r.MinVisibility))
+		}
+
+		for _, rvr := range r.RunwayVisualRanges {
+			groups = append(groups, encodeRVR(rvr))
+		}
+
+		for _, wx := range r.Weather {
+			groups = append(groups, wx.String())
+		}
+
+		for _, layer := range r.Clouds {
+			groups = append(groups, encodeCloudLayer(layer))
+		}
+
+		if r.VerticalVisibility != nil {
+			groups = append(groups, "VV"+encodeHeight(r.VerticalVisibility))
+		}
+	}
+
+	if r.Temperature != nil {
+		groups = append(groups, encodeTemperature(r.Temperature)+"/"+encodeTemperature(r.DewPoint))
+	}
+
+	if r.Pressure != nil {
+		groups = append(groups, encodePressure(*r.Pressure))
+	}
+
+	for _, wx := range r.RecentWeather {
+		groups = append(groups, "RE"+wx.String())
+	}
+
+	if r.Trend != "" {
+		groups = append(groups, r.Trend)
+	}
+
+	if r.Remarks != "" {
+		groups = append(groups, "RMK", r.Remarks)
+	}
+
+	return strings.Join(groups, " ") + "="
+}
+
+func encodeWind(w Wind) []string {
+	direction := fmt.Sprintf("%03d", w.Direction)
+	if w.Variable {
+		direction = "VRB"
+	} else if w.DirectionUnknown {
+		direction = "///"
+	}
+
+	unit := w.Unit
+	if unit == "" {
+		unit = UnitKnots
+	}
+
+	group := fmt.Sprintf("%s%02d", direction, w.Speed)
+	if w.Gust > 0 {
+		group += fmt.Sprintf("G%02d", w.Gust)
+	}
+
+	groups := []string{group + unit}
+	if w.VariableFrom != 0 || w.VariableTo != 0 {
+		groups = append(groups, fmt.Sprintf("%03dV%03d", w.VariableFrom, w.VariableTo))
+	}
+
+	return groups
+}
+
+func encodeVisibility(v Visibility) string {
+	if v.Unit != UnitStatuteMiles {
+		meters := int(v.Distance)
+		if meters > 9999 || v.MoreThan {
+			meters = 9999
+		}
+
+		return fmt.Sprintf("%04d%s", meters, v.Direction)
+	}
+
+	prefix := ""
+	if v.LessThan {
+		prefix = "M"
+	} else if v.MoreThan {
+		prefix = "P"
+	}
+
+	quarters := int(math.Round(v.Distance * 4))
+	whole, frac := quarters/4, quarters%4
+	fractions := map[int]string{1: "1/4", 2: "1/2", 3: "3/4"}
+
+	switch {
+	case frac == 0:
+		return fmt.Sprintf("%s%dSM", prefix, whole)
+	case whole == 0:
+		return prefix + fractions[frac] + "SM"
+	default:
+		return fmt.Sprintf("%d %sSM", whole, fractions[frac])
+	}
+}
+
+func encodeRVR(rvr RunwayVisualRange) string {
+	prefix := ""
+	if rvr.LessThan {
+		prefix = "M"
+	} else if rvr.MoreThan && rvr.Max == 0 {
+		prefix = "P"
+	}
+
+	group := fmt.Sprintf("R%s/%s%04d", rvr.Runway, prefix, rvr.Min)
+	if rvr.Max > 0 {
+		maxPrefix := ""
+		if rvr.MoreThan {
+			maxPrefix = "P"
+		}
+
+		group += fmt.Sprintf("V%s%04d", maxPrefix, rvr.Max)
+	}
+
+	if rvr.Unit == UnitFeet {
+		group += UnitFeet
+	}
+
+	return group + rvr.Tendency
+}
+
+func encodeCloudLayer(layer CloudLayer) string {
+	switch layer.Cover {
+	case "SKC", "CLR", "NSC", "NCD":
+		return layer.Cover
+	}
+
+	return layer.Cover + encodeHeight(layer.Height) + layer.Type
+}
+
+func encodeHeight(feet *int) string {
+	if feet == nil {
+		return "///"
+	}
+
+	return fmt.Sprintf("%03d", *feet/100)
+}
+
+func encodeTemperature(t *int) string {
+	if t == nil {
+		return "//"
+	}
+
+	if *t < 0 {
+		return fmt.Sprintf("M%02d", -*t)
+	}
+
+	return fmt.Sprintf("%02d", *t)
+}
+
+func encodePressure(p Pressure) string {
+	if p.Unit == UnitInchesMercury {
+		return fmt.Sprintf("A%04d", int(math.Round(p.Value*100)))
+	}
+
+	return fmt.Sprintf("Q%04d", int(math.Floor(p.Value)))
+}
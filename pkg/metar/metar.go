@@ -0,0 +1,174 @@
+// Package metar decodes and encodes METAR and SPECI aviation routine weather reports
+// (WMO FM 15 / FM 16, ICAO Annex 3) including common US/FAA variations.
+package metar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	TypeMETAR = "METAR"
+	TypeSPECI = "SPECI"
+
+	UnitKnots           = "KT"
+	UnitMetersPerSecond = "MPS"
+	UnitKilometersPerH  = "KMH"
+
+	UnitMeters        = "M"
+	UnitStatuteMiles  = "SM"
+	UnitFeet          = "FT"
+	UnitHectopascals  = "hPa"
+	UnitInchesMercury = "inHg"
+
+	metersInStatuteMile = 1609.344
+	hPaInInchMercury    = 33.8639
+	feetInMeter         = 3.28084
+)
+
+// Report is a decoded METAR or SPECI report.
+// Optional groups that weren't reported are nil or zero values
+type Report struct {
+	Type      string
+	Station   string
+	Day       int
+	Hour      int
+	Minute    int
+	Corrected bool
+	Auto      bool
+	Nil       bool
+
+	Wind               *Wind
+	CAVOK              bool
+	Visibility         *Visibility
+	MinVisibility      *Visibility
+	RunwayVisualRanges []RunwayVisualRange
+	Weather            []Weather
+	Clouds             []CloudLayer
+	VerticalVisibility *int
+	Temperature        *int
+	DewPoint           *int
+	Pressure           *Pressure
+	RecentWeather      []Weather
+	Trend              string
+	Remarks            string
+
+	// Unparsed holds groups of the report body that weren't recognized
+	Unparsed []string
+}
+
+// Wind group. Direction is in degrees true, speed and gust are in Unit.
+// DirectionUnknown is set when direction is reported missing as ///
+type Wind struct {
+	Direction        int
+	DirectionUnknown bool
+	Variable         bool
+	Speed            int
+	Gust             int
+	Unit             string
+	VariableFrom     int
+	VariableTo       int
+}
+
+// Visibility in Unit. LessThan/MoreThan are set for M/P prefixed and 9999 values
+type Visibility struct {
+	Distance  float64
+	Unit      string
+	Direction string
+	LessThan  bool
+	MoreThan  bool
+}
+
+// RunwayVisualRange in Unit. Max is set for variable RVR only, Tendency is U, D or N
+type RunwayVisualRange struct {
+	Runway   string
+	Min      int
+	Max      int
+	Unit     string
+	LessThan bool
+	MoreThan bool
+	Tendency string
+}
+
+// Weather is a present or recent weather group, e.g. -SHRA, +TSGR, VCFG
+type Weather struct {
+	Intensity  string
+	Descriptor string
+	Phenomena  []string
+}
+
+// CloudLayer with height in feet. Height is nil if it wasn't reported ("///")
+// or for no-cloud codes (SKC, CLR, NSC, NCD) which are stored in Cover
+type CloudLayer struct {
+	Cover  string
+	Height *int
+	Type   string
+}
+
+// Pressure is QNH in Unit
+type Pressure struct {
+	Value float64
+	Unit  string
+}
+
+// ParseError describes a malformed group of the report
+type ParseError struct {
+	Group string
+	Msg   string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("metar: invalid group %q: %s", e.Group, e.Msg)
+}
+
+// Time resolves report day/hour/minute to the latest matching time not after ref (+1h to tolerate clock skew)
+func (r Report) Time(ref time.Time) time.Time {
+	ref = ref.UTC()
+	limit := ref.Add(time.Hour)
+
+	for months := 0; months < 3; months++ {
+		year, month, _ := time.Date(ref.Year(), ref.Month()-time.Month(months), 1, 0, 0, 0, 0, time.UTC).Date()
+		t := time.Date(year, month, r.Day, r.Hour, r.Minute, 0, 0, time.UTC)
+
+		if t.Day() == r.Day && !t.After(limit) {
+			return t
+		}
+	}
+
+	return time.Date(ref.Year(), ref.Month(), r.Day, r.Hour, r.Minute, 0, 0, time.UTC)
+}
+
+// MetersPerSecond converts wind speed to m/s
+func (w Wind) MetersPerSecond(speed int) float64 {
+	switch w.Unit {
+	case UnitKnots:
+		return float64(speed) * 0.514444
+	case UnitKilometersPerH:
+		return float64(speed) / 3.6
+	default:
+		return float64(speed)
+	}
+}
+
+// Meters converts visibility distance to meters
+func (v Visibility) Meters() float64 {
+	if v.Unit == UnitStatuteMiles {
+		return v.Distance * metersInStatuteMile
+	}
+
+	return v.Distance
+}
+
+// HPa converts pressure to hectopascals
+func (p Pressure) HPa() float64 {
+	if p.Unit == UnitInchesMercury {
+		return p.Value * hPaInInchMercury
+	}
+
+	return p.Value
+}
+
+func (w Weather) String() string {
+	return w.Intensity + w.Descriptor + strings.Join(w.Phenomena, "")
+}
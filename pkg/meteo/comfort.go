@@ -0,0 +1,66 @@
+package meteo
+
+import "math"
+
+// HeatIndex returns NWS heat index: Rothfusz regression with adjustments, or Steadman's
+// simple formula when heat index is below 80 °F. Mild heat index never goes below air temperature
+func HeatIndex(temperature, humidity float64) float64 {
+	t := celsiusToFahrenheit(temperature)
+
+	simple := 0.5 * (t + 61 + (t-68)*1.2 + humidity*0.094)
+	if (simple+t)/2 < 80 {
+		if simple < t {
+			return temperature
+		}
+
+		return fahrenheitToCelsius(simple)
+	}
+
+	hi := -42.379 + 2.04901523*t + 10.14333127*humidity -
+		0.22475541*t*humidity - 0.00683783*t*t -
+		0.05481717*humidity*humidity + 0.00122874*t*t*humidity +
+		0.00085282*t*humidity*humidity - 0.00000199*t*t*humidity*humidity
+
+	switch {
+	case humidity < 13 && t >= 80 && t <= 112:
+		hi -= (13 - humidity) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+	case humidity > 85 && t >= 80 && t <= 87:
+		hi += (humidity - 85) / 10 * (87 - t) / 5
+	}
+
+	return fahrenheitToCelsius(hi)
+}
+
+// WindChill returns wind chill temperature (JAG/TI 2001 formula used by NWS and MSC).
+// It's defined for temperature at most 10 °C and wind above 4.8 km/h, air temperature is returned otherwise
+func WindChill(temperature, windSpeed float64) float64 {
+	v := windSpeed * 3.6
+	if temperature > 10 || v <= 4.8 {
+		return temperature
+	}
+
+	p := math.Pow(v, 0.16)
+
+	return 13.12 + 0.6215*temperature - 11.37*p + 0.3965*temperature*p
+}
+
+// Humidex returns Canadian humidex from temperature and dew point
+func Humidex(temperature, dewPoint float64) float64 {
+	e := 6.11 * math.Exp(5417.7530*(1/273.16-1/(dewPoint+zeroCelsius)))
+	return temperature + 0.5555*(e-10)
+}
+
+// ApparentTemperature returns Steadman's apparent temperature without radiation
+// as used by Australian Bureau of Meteorology
+func ApparentTemperature(temperature, humidity, windSpeed float64) float64 {
+	e := humidity / 100 * 6.105 * math.Exp(17.27*temperature/(237.7+temperature))
+	return temperature + 0.33*e - 0.70*windSpeed - 4.00
+}
+
+func celsiusToFahrenheit(t float64) float64 {
+	return t*9/5 + 32
+}
+
+func fahrenheitToCelsius(t float64) float64 {
+	return (t - 32) * 5 / 9
+}
@@ -0,0 +1,53 @@
+// Package meteo computes derived meteorological quantities.
+// Units: temperature °C, relative humidity %, pressure hPa, wind speed m/s, elevation m
+package meteo
+
+import "math"
+
+// Magnus formula coefficients over water (Sonntag, 1990), valid for -45..60 °C
+const (
+	magnusA = 6.112
+	magnusB = 17.62
+	magnusC = 243.12
+)
+
+const (
+	// specific gas constant of water vapour, J/(kg·K)
+	waterVapourGasConstant = 461.5
+	// ratio of molar masses of water vapour and dry air
+	molarMassRatio = 0.622
+	zeroCelsius    = 273.15
+)
+
+// SaturationVaporPressure returns saturation vapour pressure over water in hPa
+func SaturationVaporPressure(temperature float64) float64 {
+	return magnusA * math.Exp(magnusB*temperature/(magnusC+temperature))
+}
+
+// VaporPressure returns actual vapour pressure in hPa
+func VaporPressure(temperature, humidity float64) float64 {
+	return humidity / 100 * SaturationVaporPressure(temperature)
+}
+
+// DewPoint returns dew point temperature by Magnus formula. Humidity must be positive
+func DewPoint(temperature, humidity float64) float64 {
+	gamma := math.Log(humidity/100) + magnusB*temperature/(magnusC+temperature)
+	return magnusC * gamma / (magnusB - gamma)
+}
+
+// RelativeHumidity returns relative humidity from temperature and dew point
+func RelativeHumidity(temperature, dewPoint float64) float64 {
+	return 100 * math.Exp(magnusB*dewPoint/(magnusC+dewPoint)-magnusB*temperature/(magnusC+temperature))
+}
+
+// AbsoluteHumidity returns mass of water vapour per volume of air in g/m³
+func AbsoluteHumidity(temperature, humidity float64) float64 {
+	// hPa to Pa and kg to g cancel out
+	return VaporPressure(temperature, humidity) * 1e5 / (waterVapourGasConstant * (temperature + zeroCelsius))
+}
+
+// MixingRatio returns mass of water vapour per mass of dry air in g/kg at given air pressure
+func MixingRatio(temperature, humidity, pressure float64) float64 {
+	e := VaporPressure(temperature, humidity)
+	return 1000 * molarMassRatio * e / (pressure - e)
+}
@@ -0,0 +1,21 @@
+package meteo
+
+import "math"
+
+const (
+	// standard atmosphere temperature lapse rate, K/m
+	lapseRate = 0.0065
+	// g·M/(R·L) of standard atmosphere
+	barometricExponent = 5.257
+)
+
+// SeaLevelPressure reduces station pressure to mean sea level with hypsometric equation,
+// assuming standard lapse rate below the station. Temperature is the station air temperature
+func SeaLevelPressure(stationPressure, elevation, temperature float64) float64 {
+	return stationPressure * math.Pow(1-lapseRate*elevation/(temperature+lapseRate*elevation+zeroCelsius), -barometricExponent)
+}
+
+// StationPressure is inverse of SeaLevelPressure
+func StationPressure(seaLevelPressure, elevation, temperature float64) float64 {
+	return seaLevelPressure * math.Pow(1-lapseRate*elevation/(temperature+lapseRate*elevation+zeroCelsius), barometricExponent)
+}
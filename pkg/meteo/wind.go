@@ -0,0 +1,19 @@
+package meteo
+
+import "math"
+
+// Wind returns wind speed and meteorological direction (degrees the wind blows from, 0 for calm)
+// of wind vector with eastward u and northward v components
+func Wind(u, v float64) (speed, direction float64) {
+	speed = math.Hypot(u, v)
+	if speed == 0 {
+		return 0, 0
+	}
+
+	direction = math.Mod(270-math.Atan2(v, u)*180/math.Pi, 360)
+	if direction <= 0 {
+		direction += 360
+	}
+
+	return speed, direction
+}
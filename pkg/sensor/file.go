@@ -0,0 +1,124 @@
+package sensor
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+var errSourceClosed = errors.New("source is closed")
+
+// FileSource follows a file that is appended to by another process (like tail -f)
+// and returns every new non-empty delimited chunk as a frame
+type FileSource struct {
+	path         string
+	delimiter    byte
+	pollInterval time.Duration
+
+	file   *os.File
+	reader *bufio.Reader
+	offset int64
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewFileSource opens file located at path and seeks to its end,
+// so only frames written after opening are returned
+func NewFileSource(path string, delimiter byte, pollInterval time.Duration) (*FileSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &FileSource{
+		path:         path,
+		delimiter:    delimiter,
+		pollInterval: pollInterval,
+		file:         f,
+		reader:       bufio.NewReader(f),
+		offset:       offset,
+		done:         make(chan struct{}),
+	}, nil
+}
+
+func (s *FileSource) ReadFrame() ([]byte, error) {
+	var line []byte
+
+	for {
+		chunk, err := s.reader.ReadBytes(s.delimiter)
+		s.offset += int64(len(chunk))
+		line = append(line, chunk...)
+
+		if err == nil {
+			frame := bytes.TrimSpace(line[:len(line)-1])
+			if len(frame) == 0 {
+				line = line[:0]
+				continue
+			}
+
+			return frame, nil
+		}
+
+		if err != io.EOF {
+			return nil, err
+		}
+
+		if len(line) > maxFrameSize {
+			return nil, bufio.ErrTooLong
+		}
+
+		select {
+		case <-s.done:
+			return nil, errSourceClosed
+		case <-time.After(s.pollInterval):
+		}
+
+		if err := s.checkTruncated(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// checkTruncated rewinds the file if it was truncated or rotated by the writer
+func (s *FileSource) checkTruncated() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+
+	if info.Size() >= s.offset {
+		return nil
+	}
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	s.reader.Reset(s.file)
+	s.offset = 0
+
+	return nil
+}
+
+func (s *FileSource) Write(data []byte) error {
+	return ErrReadOnly
+}
+
+func (s *FileSource) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+
+	return s.file.Close()
+}
@@ -0,0 +1,63 @@
+package sensor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	TypeSerial = "serial"
+	TypeTCP    = "tcp"
+	TypeFile   = "file"
+
+	defaultDialTimeout  = 10 * time.Second
+	defaultPollInterval = time.Second
+	defaultDelimiter    = '\n'
+	maxFrameSize        = 64 * 1024
+)
+
+var ErrReadOnly = errors.New("source is read-only")
+
+// Source provides raw frames produced by a sensor or a data logger
+type Source interface {
+	ReadFrame() ([]byte, error)
+	Write(data []byte) error
+	Close() error
+}
+
+// Config of the source. Frames are separated with Delimiter, newline by default
+type Config struct {
+	Type         string
+	Address      string
+	Delimiter    byte
+	DialTimeout  time.Duration
+	PollInterval time.Duration
+}
+
+// Open creates Source of the given type. Address is a device path for serial sources,
+// host:port for tcp sources and a file path for file sources
+func Open(cfg Config) (Source, error) {
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = defaultDialTimeout
+	}
+
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+
+	if cfg.Delimiter == 0 {
+		cfg.Delimiter = defaultDelimiter
+	}
+
+	switch cfg.Type {
+	case TypeSerial:
+		return NewSerialSource(cfg.Address, cfg.Delimiter)
+	case TypeTCP:
+		return NewTCPSource(cfg.Address, cfg.Delimiter, cfg.DialTimeout)
+	case TypeFile:
+		return NewFileSource(cfg.Address, cfg.Delimiter, cfg.PollInterval)
+	default:
+		return nil, fmt.Errorf("unknown source type: %q", cfg.Type)
+	}
+}
@@ -0,0 +1,95 @@
+package sensor
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// StreamSource reads delimited frames from a bidirectional stream
+// such as a serial device or a TCP connection
+type StreamSource struct {
+	conn    io.ReadWriteCloser
+	scanner *bufio.Scanner
+}
+
+func newStreamSource(conn io.ReadWriteCloser, delimiter byte) *StreamSource {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxFrameSize)
+	scanner.Split(splitDelimited(delimiter))
+
+	return &StreamSource{conn: conn, scanner: scanner}
+}
+
+// NewSerialSource opens serial device located at path.
+// Line settings (baud rate, parity) are expected to be configured by the OS, e.g. with stty
+func NewSerialSource(path string, delimiter byte) (*StreamSource, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return newStreamSource(f, delimiter), nil
+}
+
+// NewTCPSource connects to a data logger listening on addr
+func NewTCPSource(addr string, delimiter byte, timeout time.Duration) (*StreamSource, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return newStreamSource(conn, delimiter), nil
+}
+
+func (s *StreamSource) ReadFrame() ([]byte, error) {
+	for s.scanner.Scan() {
+		frame := bytes.TrimSpace(s.scanner.Bytes())
+		if len(frame) == 0 {
+			continue
+		}
+
+		res := make([]byte, len(frame))
+		copy(res, frame)
+
+		return res, nil
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+func (s *StreamSource) Write(data []byte) error {
+	_, err := s.conn.Write(data)
+	return err
+}
+
+func (s *StreamSource) Close() error {
+	return s.conn.Close()
+}
+
+// splitDelimited is a bufio.SplitFunc returning data up to delimiter.
+// The last frame is returned without delimiter at EOF
+func splitDelimited(delimiter byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+
+		if i := bytes.IndexByte(data, delimiter); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+
+		if atEOF {
+			return len(data), data, nil
+		}
+
+		return 0, nil, nil
+	}
+}
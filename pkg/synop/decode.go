@@ -0,0 +1,379 @@
+package synop
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sectionHeader = 0
+	sectionMain   = 1
+	sectionClimat = 3
+
+	missing = "/"
+)
+
+var (
+	groupRegex = regexp.MustCompile(`^[0-9/]{5}$`)
+
+	// precipitationPeriods maps tR code to accumulation period
+	precipitationPeriods = map[byte]time.Duration{
+		'1': 6 * time.Hour,
+		'2': 12 * time.Hour,
+		'3': 18 * time.Hour,
+		'4': 24 * time.Hour,
+		'5': time.Hour,
+		'6': 2 * time.Hour,
+		'7': 3 * time.Hour,
+		'8': 9 * time.Hour,
+		'9': 15 * time.Hour,
+	}
+)
+
+// Decode parses single SYNOP report starting with "AAXX YYGGiw IIiii" and optionally terminated with "="
+func Decode(report string) (Report, error) {
+	groups := strings.Fields(strings.TrimSuffix(strings.TrimSpace(report), "="))
+	if len(groups) < 3 {
+		return Report{}, &ParseError{Section: sectionHeader, Group: report, Msg: "report is too short"}
+	}
+
+	if groups[0] != "AAXX" {
+		return Report{}, &ParseError{Section: sectionHeader, Group: groups[0], Msg: "expected AAXX"}
+	}
+
+	var res Report
+	if err := res.decodeTime(groups[1]); err != nil {
+		return Report{}, err
+	}
+
+	if len(groups[2]) != 5 || !isDigits(groups[2]) {
+		return Report{}, &ParseError{Section: sectionHeader, Group: groups[2], Msg: "expected station index IIiii"}
+	}
+
+	res.Station = groups[2]
+	if len(groups) > 3 && groups[3] == "NIL" {
+		return Report{}, ErrNil
+	}
+
+	section1, section3 := splitSections(groups[3:])
+	if err := res.decodeMain(section1); err != nil {
+		return Report{}, err
+	}
+
+	if err := res.decodeClimat(section3); err != nil {
+		return Report{}, err
+	}
+
+	return res, nil
+}
+
+// splitSections returns groups of section 1 and section 3, other sections are skipped.
+// Section 2 of ships and coastal stations starts with 222DsVs group, the others with bare 333, 444 or 555
+func splitSections(groups []string) ([]string, []string) {
+	var section1, section3 []string
+
+	current := &section1
+	for _, group := range groups {
+		switch {
+		case group == "444", group == "555", len(group) == 5 && strings.HasPrefix(group, "222"):
+			current = nil
+			continue
+		case group == "333":
+			current = &section3
+			continue
+		}
+
+		if current != nil {
+			*current = append(*current, group)
+		}
+	}
+
+	return section1, section3
+}
+
+func (r *Report) decodeTime(group string) error {
+	if len(group) != 5 || !isDigits(group) {
+		return &ParseError{Section: sectionHeader, Group: group, Msg: "expected YYGGiw"}
+	}
+
+	r.Day, r.Hour = atoi(group[0:2]), atoi(group[2:4])
+
+	iw := group[4]
+	switch iw {
+	case '0', '1':
+		r.WindUnit = WindUnitMetersPerSecond
+	case '3', '4':
+		r.WindUnit = WindUnitKnots
+	default:
+		return &ParseError{Section: sectionHeader, Group: group, Msg: "invalid wind speed indicator"}
+	}
+
+	r.WindEstimated = iw == '0' || iw == '3'
+
+	if r.Day < 1 || r.Day > 31 || r.Hour > 23 {
+		return &ParseError{Section: sectionHeader, Group: group, Msg: "observation time is out of range"}
+	}
+
+	return nil
+}
+
+func (r *Report) decodeMain(groups []string) error {
+	if len(groups) < 2 {
+		return &ParseError{Section: sectionMain, Group: strings.Join(groups, " "), Msg: "expected iRixhVV and Nddff groups"}
+	}
+
+	for _, group := range groups {
+		if !groupRegex.MatchString(group) {
+			return &ParseError{Section: sectionMain, Group: group, Msg: "group must have 5 digits"}
+		}
+	}
+
+	r.decodeIndicators(groups[0])
+	rest, err := r.decodeWind(groups[1:])
+	if err != nil {
+		return err
+	}
+
+	last := byte('0')
+	for _, group := range rest {
+		if group[0] <= last {
+			return &ParseError{Section: sectionMain, Group: group, Msg: "group is out of order"}
+		}
+
+		last = group[0]
+
+		var err error
+		switch group[0] {
+		case '1':
+			r.Temperature, err = parseTemperature(sectionMain, group)
+		case '2':
+			if group[1] == '9' {
+				r.Humidity = parseFloat(group[2:], 1)
+			} else {
+				r.DewPoint, err = parseTemperature(sectionMain, group)
+			}
+		case '3':
+			r.StationPressure = parsePressure(group[1:])
+		case '4':
+			r.decodeSeaLevelPressure(group)
+		case '5':
+			r.decodePressureTendency(group)
+		case '6':
+			r.Precipitation, err = parsePrecipitation(sectionMain, group)
+		case '7':
+			r.PresentWeather = parseInt(group[1:3])
+			r.PastWeather1 = parseInt(group[3:4])
+			r.PastWeather2 = parseInt(group[4:5])
+		case '8':
+			r.Clouds = &Clouds{
+				Amount: parseInt(group[1:2]),
+				Low:    parseInt(group[2:3]),
+				Middle: parseInt(group[3:4]),
+				High:   parseInt(group[4:5]),
+			}
+		case '9':
+			// 9GGgg exact observation time
+		default:
+			err = &ParseError{Section: sectionMain, Group: group, Msg: "unknown group"}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// iR = 3 means no precipitation
+	if r.Precipitation == nil && r.PrecipitationIndicator == 3 {
+		r.Precipitation = &Precipitation{}
+	}
+
+	return nil
+}
+
+func (r *Report) decodeIndicators(group string) {
+	if ir := parseInt(group[0:1]); ir != nil {
+		r.PrecipitationIndicator = *ir
+	}
+
+	if ix := parseInt(group[1:2]); ix != nil {
+		r.StationType = *ix
+	}
+
+	r.CloudBase = parseInt(group[2:3])
+	r.Visibility = parseInt(group[3:5])
+}
+
+func (r *Report) decodeWind(groups []string) ([]string, error) {
+	group := groups[0]
+
+	r.CloudCover = parseInt(group[0:1])
+	direction := parseInt(group[1:3])
+	r.WindSpeed = parseInt(group[3:5])
+
+	if direction != nil {
+		switch {
+		case *direction == 99:
+			r.WindVariable = true
+		case *direction > 36:
+			return nil, &ParseError{Section: sectionMain, Group: group, Msg: "wind direction is out of range"}
+		case *direction > 0:
+			degrees := *direction * 10
+			r.WindDirection = &degrees
+		}
+	}
+
+	// speeds of 99 units and more are reported in a separate 00fff group
+	if r.WindSpeed != nil && *r.WindSpeed == 99 {
+		if len(groups) < 2 || !strings.HasPrefix(groups[1], "00") {
+			return nil, &ParseError{Section: sectionMain, Group: group, Msg: "expected 00fff group"}
+		}
+
+		r.WindSpeed = parseInt(groups[1][2:])
+
+		return groups[2:], nil
+	}
+
+	return groups[1:], nil
+}
+
+func (r *Report) decodeSeaLevelPressure(group string) {
+	// 4a3hhh is reported by high-level stations instead of sea-level pressure
+	switch group[1] {
+	case '1', '2', '5', '7', '8':
+		levels := map[byte]int{'1': 1000, '2': 925, '5': 500, '7': 700, '8': 850}
+		level := levels[group[1]]
+		r.StandardLevel = &level
+		r.GeopotentialHeight = parseInt(group[2:])
+	default:
+		r.SeaLevelPressure = parsePressure(group[1:])
+	}
+}
+
+// decodePressureTendency parses 5appp group, characteristics 5-8 mean pressure decrease
+func (r *Report) decodePressureTendency(group string) {
+	r.PressureTendency = parseInt(group[1:2])
+	r.PressureChange = parseFloat(group[2:], 10)
+
+	if r.PressureChange != nil && r.PressureTendency != nil && *r.PressureTendency >= 5 {
+		*r.PressureChange = -*r.PressureChange
+	}
+}
+
+func (r *Report) decodeClimat(groups []string) error {
+	for _, group := range groups {
+		if !groupRegex.MatchString(group) {
+			return &ParseError{Section: sectionClimat, Group: group, Msg: "group must have 5 digits"}
+		}
+
+		var err error
+		switch group[0] {
+		case '1':
+			r.MaxTemperature, err = parseTemperature(sectionClimat, group)
+		case '2':
+			r.MinTemperature, err = parseTemperature(sectionClimat, group)
+		case '6':
+			if r.Precipitation == nil {
+				r.Precipitation, err = parsePrecipitation(sectionClimat, group)
+			}
+		case '7':
+			r.Precipitation24h = parseFloat(group[1:], 10)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseTemperature parses snTTT groups, temperature is in tenths of °C
+func parseTemperature(section int, group string) (*float64, error) {
+	if strings.Contains(group[2:], missing) {
+		return nil, nil
+	}
+
+	t := parseFloat(group[2:], 10)
+
+	switch group[1] {
+	case '0':
+		return t, nil
+	case '1':
+		*t = -*t
+		return t, nil
+	default:
+		return nil, &ParseError{Section: section, Group: group, Msg: "invalid temperature sign"}
+	}
+}
+
+// parsePressure parses PPPP in tenths of hPa with thousands omitted
+func parsePressure(value string) *float64 {
+	p := parseFloat(value, 10)
+	if p != nil && *p < 100 {
+		*p += 1000
+	}
+
+	return p
+}
+
+// parsePrecipitation parses 6RRRtR group
+func parsePrecipitation(section int, group string) (*Precipitation, error) {
+	amount := parseInt(group[1:4])
+	if amount == nil {
+		return nil, nil
+	}
+
+	period, ok := precipitationPeriods[group[4]]
+	if !ok {
+		return nil, &ParseError{Section: section, Group: group, Msg: "invalid precipitation period"}
+	}
+
+	res := &Precipitation{Amount: float64(*amount), Period: period}
+	switch {
+	case *amount == 990:
+		// trace
+		res.Amount = 0
+	case *amount > 990:
+		res.Amount = float64(*amount-990) / 10
+	}
+
+	return res, nil
+}
+
+func parseInt(value string) *int {
+	if strings.Contains(value, missing) {
+		return nil
+	}
+
+	res := atoi(value)
+
+	return &res
+}
+
+func parseFloat(value string, divisor float64) *float64 {
+	i := parseInt(value)
+	if i == nil {
+		return nil
+	}
+
+	res := float64(*i) / divisor
+
+	return &res
+}
+
+func atoi(value string) int {
+	res, _ := strconv.Atoi(value)
+	return res
+}
+
+func isDigits(value string) bool {
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,175 @@
+package synop
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		report string
+		check  func(t *testing.T, r Report)
+	}{
+		{
+			name:   "land station with climatological section",
+			report: "AAXX 18064 27612 12797 20502 10086 20051 30046 40183 53012 60001 70222 85830 333 10112 20032=",
+			check: func(t *testing.T, r Report) {
+				if r.Station != "27612" || r.Day != 18 || r.Hour != 6 || r.WindUnit != WindUnitKnots || r.WindEstimated {
+					t.Errorf("header = %s %d %d %s %v, want 27612 at 18th 06 UTC with measured wind in knots",
+						r.Station, r.Day, r.Hour, r.WindUnit, r.WindEstimated)
+				}
+				expectInt(t, "cloud base", r.CloudBase, 7)
+				expectInt(t, "visibility", r.Visibility, 97)
+				expectInt(t, "cloud cover", r.CloudCover, 2)
+				expectInt(t, "wind direction", r.WindDirection, 50)
+				expectInt(t, "wind speed", r.WindSpeed, 2)
+				expectFloat(t, "temperature", r.Temperature, 8.6)
+				expectFloat(t, "dew point", r.DewPoint, 5.1)
+				expectFloat(t, "station pressure", r.StationPressure, 1004.6)
+				expectFloat(t, "sea-level pressure", r.SeaLevelPressure, 1018.3)
+				expectInt(t, "pressure tendency", r.PressureTendency, 3)
+				expectFloat(t, "pressure change", r.PressureChange, 1.2)
+				if r.Precipitation == nil || *r.Precipitation != (Precipitation{Amount: 0, Period: 6 * time.Hour}) {
+					t.Errorf("precipitation = %+v, want none in 6 hours", r.Precipitation)
+				}
+				expectInt(t, "present weather", r.PresentWeather, 2)
+				if r.Clouds == nil || *r.Clouds.Amount != 5 || *r.Clouds.Low != 8 || *r.Clouds.Middle != 3 || *r.Clouds.High != 0 {
+					t.Errorf("clouds = %+v, want 5 oktas of CL8 CM3 CH0", r.Clouds)
+				}
+				expectFloat(t, "maximum temperature", r.MaxTemperature, 11.2)
+				expectFloat(t, "minimum temperature", r.MinTemperature, 3.2)
+			},
+		},
+		{
+			name:   "coastal station with section 2",
+			report: "AAXX 18121 26063 11598 72310 11021 21040 39998 40105 57010 69902 222// 04010 20301 333 21045 70015 555 10100=",
+			check: func(t *testing.T, r Report) {
+				if r.WindUnit != WindUnitMetersPerSecond {
+					t.Errorf("wind unit = %s, want m/s", r.WindUnit)
+				}
+				expectInt(t, "wind direction", r.WindDirection, 230)
+				expectInt(t, "wind speed", r.WindSpeed, 10)
+				expectFloat(t, "temperature", r.Temperature, -2.1)
+				expectFloat(t, "dew point", r.DewPoint, -4)
+				expectFloat(t, "station pressure", r.StationPressure, 999.8)
+				expectFloat(t, "sea-level pressure", r.SeaLevelPressure, 1010.5)
+				expectFloat(t, "pressure change", r.PressureChange, -1)
+				if r.Precipitation == nil || *r.Precipitation != (Precipitation{Amount: 0, Period: 12 * time.Hour}) {
+					t.Errorf("precipitation = %+v, want trace in 12 hours", r.Precipitation)
+				}
+				if r.MaxTemperature != nil {
+					t.Errorf("maximum temperature = %v, want missing", *r.MaxTemperature)
+				}
+				expectFloat(t, "minimum temperature", r.MinTemperature, -4.5)
+				expectFloat(t, "24 hour precipitation", r.Precipitation24h, 1.5)
+			},
+		},
+		{
+			name:   "mountain station with geopotential height and strong wind",
+			report: "AAXX 18004 36104 41/60 /9999 00105 10152 29045 38105 48862",
+			check: func(t *testing.T, r Report) {
+				if r.CloudBase != nil || r.CloudCover != nil || r.WindDirection != nil || !r.WindVariable {
+					t.Errorf("cloud base = %v, cloud cover = %v, wind direction = %v, variable = %v, want missing and variable wind",
+						r.CloudBase, r.CloudCover, r.WindDirection, r.WindVariable)
+				}
+				expectInt(t, "wind speed", r.WindSpeed, 105)
+				if speed := r.WindSpeedMetersPerSecond(); speed == nil || math.Abs(*speed-54.02) > 0.01 {
+					t.Errorf("wind speed = %v m/s, want 54.02", speed)
+				}
+				expectFloat(t, "humidity", r.Humidity, 45)
+				expectFloat(t, "station pressure", r.StationPressure, 810.5)
+				if r.SeaLevelPressure != nil {
+					t.Errorf("sea-level pressure = %v, want missing", *r.SeaLevelPressure)
+				}
+				expectInt(t, "standard level", r.StandardLevel, 850)
+				expectInt(t, "geopotential height", r.GeopotentialHeight, 862)
+				if r.Precipitation != nil {
+					t.Errorf("precipitation = %+v, want not observed", r.Precipitation)
+				}
+			},
+		},
+		{
+			name:   "missing groups",
+			report: "AAXX 18031 27612 32/// ///// 1//// 2//// 3//// 4//// 7////",
+			check: func(t *testing.T, r Report) {
+				if r.Temperature != nil || r.DewPoint != nil || r.StationPressure != nil || r.SeaLevelPressure != nil ||
+					r.WindSpeed != nil || r.PresentWeather != nil {
+					t.Errorf("report = %+v, want missing values", r)
+				}
+				if r.Precipitation == nil || r.Precipitation.Amount != 0 {
+					t.Errorf("precipitation = %+v, want none", r.Precipitation)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Decode(tt.report)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			tt.check(t, r)
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		report string
+	}{
+		{name: "not a land station report", report: "BBXX 18061 27612 32797 20502"},
+		{name: "invalid station index", report: "AAXX 18061 2761 32797 20502"},
+		{name: "hour out of range", report: "AAXX 18251 27612 32797 20502"},
+		{name: "invalid wind speed indicator", report: "AAXX 18062 27612 32797 20502"},
+		{name: "missing wind group", report: "AAXX 18061 27612 32797"},
+		{name: "wind direction out of range", report: "AAXX 18061 27612 32797 24005"},
+		{name: "missing 00fff group", report: "AAXX 18061 27612 32797 22599 10086"},
+		{name: "groups out of order", report: "AAXX 18061 27612 32797 20502 20051 10086"},
+		{name: "invalid temperature sign", report: "AAXX 18061 27612 32797 20502 15086"},
+		{name: "invalid precipitation period", report: "AAXX 18061 27612 12797 20502 60010"},
+		{name: "letters in section 1", report: "AAXX 18061 27612 32797 20502 1008A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.report); err == nil {
+				t.Errorf("Decode(%q) error = nil, want parse error", tt.report)
+			}
+		})
+	}
+
+	if _, err := Decode("AAXX 18061 27612 NIL="); err != ErrNil {
+		t.Errorf("Decode() of NIL report error = %v, want ErrNil", err)
+	}
+}
+
+func TestReportTime(t *testing.T) {
+	r := Report{Day: 31, Hour: 21}
+
+	// the report of the last day of October received on November 1st
+	want := time.Date(2026, 10, 31, 21, 0, 0, 0, time.UTC)
+	if got := r.Time(time.Date(2026, 11, 1, 0, 30, 0, 0, time.UTC)); !got.Equal(want) {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+}
+
+func expectInt(t *testing.T, name string, got *int, want int) {
+	t.Helper()
+
+	if got == nil || *got != want {
+		t.Errorf("%s = %v, want %d", name, got, want)
+	}
+}
+
+func expectFloat(t *testing.T, name string, got *float64, want float64) {
+	t.Helper()
+
+	if got == nil || math.Abs(*got-want) > 1e-9 {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
@@ -0,0 +1,112 @@
+// Package synop decodes WMO FM 12 SYNOP reports from fixed land stations.
+// Sections 0, 1 and 3 are decoded, sections 2, 4 and 5 are skipped.
+package synop
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	WindUnitMetersPerSecond = "MPS"
+	WindUnitKnots           = "KT"
+)
+
+// ErrNil is returned for NIL reports of stations that didn't report
+var ErrNil = errors.New("synop: NIL report")
+
+// Report is a decoded SYNOP report. Values that weren't reported are nil.
+// Temperatures are in °C, pressure in hPa, precipitation in mm, wind speed in WindUnit
+type Report struct {
+	Station       string
+	Day           int
+	Hour          int
+	WindUnit      string
+	WindEstimated bool
+
+	PrecipitationIndicator int
+	StationType            int
+	CloudBase              *int
+	Visibility             *int
+
+	CloudCover    *int
+	WindDirection *int
+	WindVariable  bool
+	WindSpeed     *int
+
+	Temperature        *float64
+	DewPoint           *float64
+	Humidity           *float64
+	StationPressure    *float64
+	SeaLevelPressure   *float64
+	StandardLevel      *int
+	GeopotentialHeight *int
+	PressureTendency   *int
+	PressureChange     *float64
+	Precipitation      *Precipitation
+	PresentWeather     *int
+	PastWeather1       *int
+	PastWeather2       *int
+	Clouds             *Clouds
+
+	MaxTemperature   *float64
+	MinTemperature   *float64
+	Precipitation24h *float64
+}
+
+// Precipitation amount in mm accumulated over Period
+type Precipitation struct {
+	Amount float64
+	Period time.Duration
+}
+
+// Clouds group 8NhCLCMCH: amount of low (or middle) clouds in oktas and WMO cloud type codes
+type Clouds struct {
+	Amount *int
+	Low    *int
+	Middle *int
+	High   *int
+}
+
+// ParseError describes a malformed group of the report
+type ParseError struct {
+	Section int
+	Group   string
+	Msg     string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("synop: section %d: invalid group %q: %s", e.Section, e.Group, e.Msg)
+}
+
+// Time resolves report day/hour to the latest matching time not after ref (+1h to tolerate clock skew)
+func (r Report) Time(ref time.Time) time.Time {
+	ref = ref.UTC()
+	limit := ref.Add(time.Hour)
+
+	for months := 0; months < 3; months++ {
+		year, month, _ := time.Date(ref.Year(), ref.Month()-time.Month(months), 1, 0, 0, 0, 0, time.UTC).Date()
+		t := time.Date(year, month, r.Day, r.Hour, 0, 0, 0, time.UTC)
+
+		if t.Day() == r.Day && !t.After(limit) {
+			return t
+		}
+	}
+
+	return time.Date(ref.Year(), ref.Month(), r.Day, r.Hour, 0, 0, 0, time.UTC)
+}
+
+// WindSpeedMetersPerSecond converts wind speed to m/s
+func (r Report) WindSpeedMetersPerSecond() *float64 {
+	if r.WindSpeed == nil {
+		return nil
+	}
+
+	speed := float64(*r.WindSpeed)
+	if r.WindUnit == WindUnitKnots {
+		speed *= 0.514444
+	}
+
+	return &speed
+}
@@ -0,0 +1,299 @@
+package taf
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gitlab.com/peleng-meteo/meteo-go/pkg/metar"
+)
+
+var (
+	stationRegex     = regexp.MustCompile(`^[A-Z][A-Z0-9]{3}$`)
+	issueTimeRegex   = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	periodRegex      = regexp.MustCompile(`^(\d{2})(\d{2})/(\d{2})(\d{2})$`)
+	fromRegex        = regexp.MustCompile(`^FM(\d{2})(\d{2})(\d{2})$`)
+	probabilityRegex = regexp.MustCompile(`^PROB(\d{2})$`)
+	temperatureRegex = regexp.MustCompile(`^T([XN])(M?\d{2})/(\d{2})(\d{2})Z$`)
+	windShearRegex   = regexp.MustCompile(`^WS(\d{3})/(\S+)$`)
+	qnhRegex         = regexp.MustCompile(`^QNH(\d{4})INS$`)
+)
+
+// Decode parses TAF forecast optionally prefixed with "TAF" and terminated with "="
+func Decode(forecast string) (Forecast, error) {
+	groups := strings.Fields(strings.TrimSuffix(strings.TrimSpace(forecast), "="))
+
+	var (
+		res Forecast
+		pos int
+	)
+
+	next := func() string {
+		if pos < len(groups) {
+			pos++
+			return groups[pos-1]
+		}
+
+		return ""
+	}
+
+	peek := func() string {
+		if pos < len(groups) {
+			return groups[pos]
+		}
+
+		return ""
+	}
+
+	if peek() == "TAF" {
+		next()
+	}
+
+	for peek() == "AMD" || peek() == "COR" {
+		if next() == "AMD" {
+			res.Amended = true
+		} else {
+			res.Corrected = true
+		}
+	}
+
+	station := next()
+	if !stationRegex.MatchString(station) {
+		return Forecast{}, &ParseError{Group: station, Msg: "expected ICAO station identifier"}
+	}
+
+	res.Station = station
+
+	group := next()
+	m := issueTimeRegex.FindStringSubmatch(group)
+	if m == nil {
+		return Forecast{}, &ParseError{Group: group, Msg: "expected issue time DDHHMMZ"}
+	}
+
+	res.IssuedAt = DayTime{Day: atoi(m[1]), Hour: atoi(m[2]), Minute: atoi(m[3])}
+
+	if peek() == "NIL" {
+		res.Nil = true
+		return res, nil
+	}
+
+	group = next()
+	from, to, ok := parsePeriod(group)
+	if !ok {
+		return Forecast{}, &ParseError{Group: group, Msg: "expected validity period DDHH/DDHH"}
+	}
+
+	res.ValidFrom, res.ValidTo = from, to
+
+	if peek() == "CNL" {
+		res.Cancelled = true
+		return res, nil
+	}
+
+	// split the rest of forecast into base conditions and change groups
+	var (
+		chunks  [][]string
+		current []string
+	)
+
+	for pos < len(groups) {
+		group := next()
+
+		if group == "RMK" {
+			res.Remarks = strings.Join(groups[pos:], " ")
+			break
+		}
+
+		if isChangeIndicator(group) && !(group == ChangeTemporary && len(current) == 1 && probabilityRegex.MatchString(current[0])) {
+			chunks = append(chunks, current)
+			current = nil
+		}
+
+		current = append(current, group)
+	}
+
+	chunks = append(chunks, current)
+
+	base, err := res.decodeConditions(chunks[0])
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	res.Base = base
+
+	for _, chunk := range chunks[1:] {
+		change, err := res.decodeChange(chunk)
+		if err != nil {
+			return Forecast{}, err
+		}
+
+		res.Changes = append(res.Changes, change)
+	}
+
+	res.limitFromChanges()
+
+	return res, nil
+}
+
+// limitFromChanges ends every FM change at the start of the next one
+func (f *Forecast) limitFromChanges() {
+	last := -1
+	for i, change := range f.Changes {
+		if change.Type != ChangeFrom {
+			continue
+		}
+
+		if last >= 0 {
+			f.Changes[last].To = change.From
+		}
+
+		last = i
+	}
+}
+
+func (f *Forecast) decodeChange(groups []string) (Change, error) {
+	var change Change
+
+	indicator := groups[0]
+	rest := groups[1:]
+
+	if m := fromRegex.FindStringSubmatch(indicator); m != nil {
+		change.Type = ChangeFrom
+		change.From = DayTime{Day: atoi(m[1]), Hour: atoi(m[2]), Minute: atoi(m[3])}
+
+		// FM change lasts until the next FM group or the end of validity
+		change.To = f.ValidTo
+	} else {
+		change.Type = indicator
+
+		if m := probabilityRegex.FindStringSubmatch(indicator); m != nil {
+			change.Type = ChangeProbability
+			change.Probability = atoi(m[1])
+
+			if len(rest) > 0 && rest[0] == ChangeTemporary {
+				change.Type = ChangeTemporary
+				rest = rest[1:]
+			}
+		}
+
+		if len(rest) == 0 {
+			return Change{}, &ParseError{Group: indicator, Msg: "expected change period DDHH/DDHH"}
+		}
+
+		from, to, ok := parsePeriod(rest[0])
+		if !ok {
+			return Change{}, &ParseError{Group: rest[0], Msg: "expected change period DDHH/DDHH"}
+		}
+
+		change.From, change.To = from, to
+		rest = rest[1:]
+	}
+
+	conditions, err := f.decodeConditions(rest)
+	if err != nil {
+		return Change{}, err
+	}
+
+	change.Conditions = conditions
+
+	return change, nil
+}
+
+func (f *Forecast) decodeConditions(groups []string) (Conditions, error) {
+	var (
+		conditions Conditions
+		rest       []string
+	)
+
+	for _, group := range groups {
+		if group == "NSW" {
+			conditions.NoSignificantWeather = true
+			continue
+		}
+
+		if m := temperatureRegex.FindStringSubmatch(group); m != nil {
+			t := &TemperatureForecast{
+				Value: parseTemperature(m[2]),
+				At:    DayTime{Day: atoi(m[3]), Hour: atoi(m[4])},
+			}
+
+			if m[1] == "X" {
+				f.MaxTemperature = t
+			} else {
+				f.MinTemperature = t
+			}
+
+			continue
+		}
+
+		if m := windShearRegex.FindStringSubmatch(group); m != nil {
+			wind, err := metar.DecodeGroups([]string{m[2]})
+			if err != nil || wind.Wind == nil || len(wind.Unparsed) > 0 {
+				return Conditions{}, &ParseError{Group: group, Msg: "invalid wind shear"}
+			}
+
+			conditions.WindShear = &WindShear{Height: atoi(m[1]) * 100, Wind: *wind.Wind}
+			continue
+		}
+
+		if m := qnhRegex.FindStringSubmatch(group); m != nil {
+			conditions.QNH = &metar.Pressure{Value: float64(atoi(m[1])) / 100, Unit: metar.UnitInchesMercury}
+			continue
+		}
+
+		rest = append(rest, group)
+	}
+
+	report, err := metar.DecodeGroups(rest)
+	if err != nil {
+		return Conditions{}, &ParseError{Group: strings.Join(groups, " "), Msg: err.Error()}
+	}
+
+	if len(report.Unparsed) > 0 {
+		return Conditions{}, &ParseError{Group: report.Unparsed[0], Msg: "unknown group"}
+	}
+
+	conditions.Wind = report.Wind
+	conditions.CAVOK = report.CAVOK
+	conditions.Visibility = report.Visibility
+	conditions.Weather = report.Weather
+	conditions.Clouds = report.Clouds
+	conditions.VerticalVisibility = report.VerticalVisibility
+
+	return conditions, nil
+}
+
+func isChangeIndicator(group string) bool {
+	return group == ChangeBecoming || group == ChangeTemporary ||
+		fromRegex.MatchString(group) || probabilityRegex.MatchString(group)
+}
+
+func parsePeriod(group string) (DayTime, DayTime, bool) {
+	m := periodRegex.FindStringSubmatch(group)
+	if m == nil {
+		return DayTime{}, DayTime{}, false
+	}
+
+	from := DayTime{Day: atoi(m[1]), Hour: atoi(m[2])}
+	to := DayTime{Day: atoi(m[3]), Hour: atoi(m[4])}
+
+	if from.Hour > 24 || to.Hour > 24 || from.Day < 1 || from.Day > 31 || to.Day < 1 || to.Day > 31 {
+		return DayTime{}, DayTime{}, false
+	}
+
+	return from, to, true
+}
+
+func parseTemperature(value string) int {
+	t := atoi(strings.TrimPrefix(value, "M"))
+	if strings.HasPrefix(value, "M") {
+		return -t
+	}
+
+	return t
+}
+
+func atoi(value string) int {
+	res, _ := strconv.Atoi(value)
+	return res
+}
@@ -0,0 +1,206 @@
+package taf
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gitlab.com/peleng-meteo/meteo-go/pkg/metar"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		forecast string
+		check    func(t *testing.T, f Forecast)
+	}{
+		{
+			name:     "temporary and becoming changes with temperature forecast",
+			forecast: "TAF UUEE 181100Z 1812/1918 24008MPS 9999 BKN020 TX15/1812Z TN05/1903Z TEMPO 1812/1818 -SHRA BKN015CB BECMG 1900/1902 VRB02MPS CAVOK=",
+			check: func(t *testing.T, f Forecast) {
+				if f.Station != "UUEE" || f.IssuedAt != (DayTime{18, 11, 0}) || f.ValidFrom != (DayTime{18, 12, 0}) || f.ValidTo != (DayTime{19, 18, 0}) {
+					t.Errorf("header = %s %+v %+v-%+v", f.Station, f.IssuedAt, f.ValidFrom, f.ValidTo)
+				}
+				expectWind(t, f.Base.Wind, metar.Wind{Direction: 240, Speed: 8, Unit: metar.UnitMetersPerSecond})
+				if f.Base.Visibility == nil || f.Base.Visibility.Meters() != 9999 || len(f.Base.Clouds) != 1 {
+					t.Errorf("visibility = %+v, clouds = %+v, want 9999 m and BKN020", f.Base.Visibility, f.Base.Clouds)
+				}
+				if f.MaxTemperature == nil || *f.MaxTemperature != (TemperatureForecast{15, DayTime{18, 12, 0}}) {
+					t.Errorf("maximum temperature = %+v, want 15 at 18th 12 UTC", f.MaxTemperature)
+				}
+				if f.MinTemperature == nil || *f.MinTemperature != (TemperatureForecast{5, DayTime{19, 3, 0}}) {
+					t.Errorf("minimum temperature = %+v, want 5 at 19th 03 UTC", f.MinTemperature)
+				}
+				expectChanges(t, f.Changes, ChangeTemporary, ChangeBecoming)
+				tempo := f.Changes[0]
+				if tempo.From != (DayTime{18, 12, 0}) || tempo.To != (DayTime{18, 18, 0}) {
+					t.Errorf("TEMPO period = %+v-%+v, want 1812/1818", tempo.From, tempo.To)
+				}
+				expectWeather(t, tempo.Conditions.Weather, "-SHRA")
+				becoming := f.Changes[1].Conditions
+				if !becoming.CAVOK || becoming.Wind == nil || !becoming.Wind.Variable {
+					t.Errorf("BECMG conditions = %+v, want variable wind and CAVOK", becoming)
+				}
+			},
+		},
+		{
+			name: "US forecast with wind shear, QNH and FM changes",
+			forecast: "TAF KJFK 181730Z 1818/1924 31015G25KT P6SM SCT040 WS015/30045KT QNH2992INS " +
+				"FM190200 32010KT P6SM FEW250 QNH3001INS PROB30 1906/1910 2SM BR FM191500 VRB03KT P6SM FEW250 RMK NXT FCST BY 00Z",
+			check: func(t *testing.T, f Forecast) {
+				expectWind(t, f.Base.Wind, metar.Wind{Direction: 310, Speed: 15, Gust: 25, Unit: metar.UnitKnots})
+				if f.Base.Visibility == nil || !f.Base.Visibility.MoreThan || f.Base.Visibility.Unit != metar.UnitStatuteMiles {
+					t.Errorf("visibility = %+v, want more than 6 SM", f.Base.Visibility)
+				}
+				want := WindShear{Height: 1500, Wind: metar.Wind{Direction: 300, Speed: 45, Unit: metar.UnitKnots}}
+				if f.Base.WindShear == nil || *f.Base.WindShear != want {
+					t.Errorf("wind shear = %+v, want %+v", f.Base.WindShear, want)
+				}
+				expectQNH(t, f.Base.QNH, 29.92)
+				expectChanges(t, f.Changes, ChangeFrom, ChangeProbability, ChangeFrom)
+				if f.Changes[0].From != (DayTime{19, 2, 0}) || f.Changes[0].To != (DayTime{19, 15, 0}) {
+					t.Errorf("first FM period = %+v-%+v, want until the next FM change", f.Changes[0].From, f.Changes[0].To)
+				}
+				expectQNH(t, f.Changes[0].Conditions.QNH, 30.01)
+				if f.Changes[1].Probability != 30 {
+					t.Errorf("probability = %d, want 30", f.Changes[1].Probability)
+				}
+				expectWeather(t, f.Changes[1].Conditions.Weather, "BR")
+				if f.Changes[2].To != f.ValidTo {
+					t.Errorf("last FM change ends at %+v, want end of validity %+v", f.Changes[2].To, f.ValidTo)
+				}
+				if f.Remarks != "NXT FCST BY 00Z" {
+					t.Errorf("remarks = %q", f.Remarks)
+				}
+			},
+		},
+		{
+			name:     "amendment with probability of temporary change",
+			forecast: "TAF AMD ULLI 181400Z 1815/1915 18005MPS 6000 -RA OVC008 PROB40 TEMPO 1815/1818 1500 +RA BR OVC004 BECMG 1822/1824 NSW",
+			check: func(t *testing.T, f Forecast) {
+				if !f.Amended || f.Corrected {
+					t.Errorf("amended = %v, corrected = %v, want amendment", f.Amended, f.Corrected)
+				}
+				expectChanges(t, f.Changes, ChangeTemporary, ChangeBecoming)
+				if f.Changes[0].Probability != 40 {
+					t.Errorf("probability = %d, want 40", f.Changes[0].Probability)
+				}
+				expectWeather(t, f.Changes[0].Conditions.Weather, "+RA", "BR")
+				if !f.Changes[1].Conditions.NoSignificantWeather || f.Changes[1].To != (DayTime{18, 24, 0}) {
+					t.Errorf("BECMG = %+v, want NSW until the end of 18th", f.Changes[1])
+				}
+			},
+		},
+		{
+			name:     "cancelled forecast",
+			forecast: "TAF AMD UUEE 181200Z 1812/1918 CNL=",
+			check: func(t *testing.T, f Forecast) {
+				if !f.Cancelled || f.Base.Wind != nil {
+					t.Errorf("cancelled = %v, base = %+v, want cancelled forecast", f.Cancelled, f.Base)
+				}
+			},
+		},
+		{
+			name:     "missing forecast",
+			forecast: "TAF UUEE 181100Z NIL=",
+			check: func(t *testing.T, f Forecast) {
+				if !f.Nil || f.ValidTo != (DayTime{}) {
+					t.Errorf("nil = %v, valid to = %+v, want empty NIL forecast", f.Nil, f.ValidTo)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Decode(tt.forecast)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			tt.check(t, f)
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		forecast string
+	}{
+		{name: "invalid station", forecast: "TAF U1 181100Z 1812/1918 24008MPS 9999"},
+		{name: "invalid issue time", forecast: "TAF UUEE 1811Z 1812/1918 24008MPS 9999"},
+		{name: "validity hour out of range", forecast: "TAF UUEE 181100Z 1825/1918 24008MPS 9999"},
+		{name: "unknown group", forecast: "TAF UUEE 181100Z 1812/1918 24008MPS 9999 XYZ"},
+		{name: "change without period", forecast: "TAF UUEE 181100Z 1812/1918 24008MPS 9999 TEMPO -SHRA"},
+		{name: "invalid wind shear", forecast: "TAF KJFK 181730Z 1818/1924 31015KT P6SM WS015/300KT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.forecast); err == nil {
+				t.Errorf("Decode(%q) error = nil, want parse error", tt.forecast)
+			}
+		})
+	}
+}
+
+func TestDayTime(t *testing.T) {
+	f := Forecast{IssuedAt: DayTime{31, 23, 0}, ValidTo: DayTime{2, 0, 0}}
+
+	// the forecast issued on the last day of October is received on November 1st
+	issued := f.IssueTime(time.Date(2026, 11, 1, 0, 10, 0, 0, time.UTC))
+	if want := time.Date(2026, 10, 31, 23, 0, 0, 0, time.UTC); !issued.Equal(want) {
+		t.Errorf("IssueTime() = %v, want %v", issued, want)
+	}
+
+	if got, want := f.ValidTo.Time(issued), time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+
+	if got, want := (DayTime{31, 24, 0}).Time(issued), time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Time() of hour 24 = %v, want %v", got, want)
+	}
+}
+
+func expectWind(t *testing.T, got *metar.Wind, want metar.Wind) {
+	t.Helper()
+
+	if got == nil || *got != want {
+		t.Errorf("wind = %+v, want %+v", got, want)
+	}
+}
+
+func expectQNH(t *testing.T, got *metar.Pressure, want float64) {
+	t.Helper()
+
+	if got == nil || *got != (metar.Pressure{Value: want, Unit: metar.UnitInchesMercury}) {
+		t.Errorf("QNH = %+v, want %v inHg", got, want)
+	}
+}
+
+func expectChanges(t *testing.T, changes []Change, want ...string) {
+	t.Helper()
+
+	got := make([]string, len(changes))
+	for i, change := range changes {
+		got[i] = change.Type
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("changes = %v, want %v", got, want)
+	}
+}
+
+func expectWeather(t *testing.T, weather []metar.Weather, want ...string) {
+	t.Helper()
+
+	got := make([]string, len(weather))
+	for i, wx := range weather {
+		got[i] = wx.String()
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("weather = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+// Package taf decodes TAF aerodrome forecasts (WMO FM 51) with FM, BECMG, TEMPO and PROB change groups.
+package taf
+
+import (
+	"fmt"
+	"time"
+
+	"gitlab.com/peleng-meteo/meteo-go/pkg/metar"
+)
+
+const (
+	ChangeFrom        = "FM"
+	ChangeBecoming    = "BECMG"
+	ChangeTemporary   = "TEMPO"
+	ChangeProbability = "PROB"
+)
+
+// Forecast is a decoded TAF. Times are relative (day of month, hour, minute), use DayTime.Time to resolve them
+type Forecast struct {
+	Station   string
+	Amended   bool
+	Corrected bool
+	Cancelled bool
+	Nil       bool
+
+	IssuedAt  DayTime
+	ValidFrom DayTime
+	ValidTo   DayTime
+
+	Base    Conditions
+	Changes []Change
+
+	MaxTemperature *TemperatureForecast
+	MinTemperature *TemperatureForecast
+	Remarks        string
+}
+
+// Conditions forecast for a period. Nil values and empty slices weren't forecast.
+// WindShear and QNH are national additions, they are forecast in the US and Canada
+type Conditions struct {
+	Wind                 *metar.Wind
+	CAVOK                bool
+	Visibility           *metar.Visibility
+	Weather              []metar.Weather
+	NoSignificantWeather bool
+	Clouds               []metar.CloudLayer
+	VerticalVisibility   *int
+	WindShear            *WindShear
+	QNH                  *metar.Pressure
+}
+
+// WindShear is low-level wind shear, Wind is forecast at Height feet above ground
+type WindShear struct {
+	Height int
+	Wind   metar.Wind
+}
+
+// Change group. Probability is set for PROB30/PROB40 groups, their Type is PROB
+// or TEMPO for "PROB30 TEMPO" combination
+type Change struct {
+	Type        string
+	Probability int
+	From        DayTime
+	To          DayTime
+	Conditions  Conditions
+}
+
+type TemperatureForecast struct {
+	Value int
+	At    DayTime
+}
+
+// DayTime is a time relative to the forecast month
+type DayTime struct {
+	Day    int
+	Hour   int
+	Minute int
+}
+
+// ParseError describes a malformed group of the forecast
+type ParseError struct {
+	Group string
+	Msg   string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("taf: invalid group %q: %s", e.Group, e.Msg)
+}
+
+// Time resolves day time to the first matching time not earlier than one day before anchor.
+// Anchor is usually the resolved issue time. Hour 24 means the end of the day
+func (t DayTime) Time(anchor time.Time) time.Time {
+	anchor = anchor.UTC()
+	limit := anchor.Add(-24 * time.Hour)
+
+	for months := -1; months <= 1; months++ {
+		// step from the first day, AddDate normalizes e.g. October 31 plus a month to December 1
+		year, month, _ := time.Date(anchor.Year(), anchor.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC).Date()
+		res := time.Date(year, month, t.Day, t.Hour, t.Minute, 0, 0, time.UTC)
+
+		if !res.Before(limit) && (t.Hour == 24 || res.Day() == t.Day) {
+			return res
+		}
+	}
+
+	return time.Date(anchor.Year(), anchor.Month(), t.Day, t.Hour, t.Minute, 0, 0, time.UTC)
+}
+
+// IssueTime resolves issue time to the latest matching time not after ref (+1h to tolerate clock skew)
+func (f Forecast) IssueTime(ref time.Time) time.Time {
+	ref = ref.UTC()
+	limit := ref.Add(time.Hour)
+
+	for months := 0; months < 3; months++ {
+		year, month, _ := time.Date(ref.Year(), ref.Month()-time.Month(months), 1, 0, 0, 0, 0, time.UTC).Date()
+		t := time.Date(year, month, f.IssuedAt.Day, f.IssuedAt.Hour, f.IssuedAt.Minute, 0, 0, time.UTC)
+
+		if t.Day() == f.IssuedAt.Day && !t.After(limit) {
+			return t
+		}
+	}
+
+	return time.Date(ref.Year(), ref.Month(), f.IssuedAt.Day, f.IssuedAt.Hour, f.IssuedAt.Minute, 0, 0, time.UTC)
+}
@@ -0,0 +1,93 @@
+package units
+
+const (
+	SystemMetric   = "metric"
+	SystemImperial = "imperial"
+)
+
+// System is a set of units per quantity
+type System struct {
+	Name          string `json:"name"`
+	Temperature   string `json:"temperature"`
+	Speed         string `json:"speed"`
+	Pressure      string `json:"pressure"`
+	Length        string `json:"length"`
+	Precipitation string `json:"precipitation"`
+}
+
+var (
+	// Metric is SI based system observations are stored in
+	Metric = System{
+		Name:          SystemMetric,
+		Temperature:   Celsius,
+		Speed:         MetersPerSecond,
+		Pressure:      Hectopascals,
+		Length:        Meters,
+		Precipitation: Millimeters,
+	}
+
+	Imperial = System{
+		Name:          SystemImperial,
+		Temperature:   Fahrenheit,
+		Speed:         MilesPerHour,
+		Pressure:      InchesOfMercury,
+		Length:        Feet,
+		Precipitation: Inches,
+	}
+
+	systems = map[string]System{
+		SystemMetric:   Metric,
+		SystemImperial: Imperial,
+	}
+)
+
+// SystemByName returns system with given name, ok is false for unknown names
+func SystemByName(name string) (System, bool) {
+	system, ok := systems[name]
+	return system, ok
+}
+
+// Converter converts values from one system to another. Units of both systems must be known
+type Converter struct {
+	from System
+	to   System
+}
+
+func NewConverter(from, to System) Converter {
+	return Converter{from: from, to: to}
+}
+
+// Identity reports whether conversion doesn't change values
+func (c Converter) Identity() bool {
+	return c.from == c.to
+}
+
+func (c Converter) Temperature(value float64) float64 {
+	res, _ := Temperature(value, c.from.Temperature, c.to.Temperature)
+	return res
+}
+
+// TemperatureDifference converts temperature delta, e.g. anomaly, which doesn't depend on scale offset
+func (c Converter) TemperatureDifference(value float64) float64 {
+	return c.Temperature(value) - c.Temperature(0)
+}
+
+func (c Converter) Speed(value float64) float64 {
+	res, _ := Speed(value, c.from.Speed, c.to.Speed)
+	return res
+}
+
+func (c Converter) Pressure(value float64) float64 {
+	res, _ := Pressure(value, c.from.Pressure, c.to.Pressure)
+	return res
+}
+
+func (c Converter) Length(value float64) float64 {
+	res, _ := Length(value, c.from.Length, c.to.Length)
+	return res
+}
+
+func (c Converter) Precipitation(value float64) float64 {
+	res, _ := Precipitation(value, c.from.Precipitation, c.to.Precipitation)
+	return res
+}
@@ -0,0 +1,123 @@
+// Package units converts meteorological values between units of measurement
+package units
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	Celsius    = "C"
+	Fahrenheit = "F"
+	Kelvin     = "K"
+
+	MetersPerSecond   = "m/s"
+	KilometersPerHour = "km/h"
+	MilesPerHour      = "mph"
+	Knots             = "kn"
+
+	Hectopascals         = "hPa"
+	Kilopascals          = "kPa"
+	InchesOfMercury      = "inHg"
+	MillimetersOfMercury = "mmHg"
+
+	Meters     = "m"
+	Kilometers = "km"
+	Feet       = "ft"
+	Miles      = "mi"
+
+	Millimeters = "mm"
+	Inches      = "in"
+)
+
+var ErrUnknownUnit = errors.New("unknown unit")
+
+// linear units are converted through the base unit of quantity: value * factor = value in base units
+var (
+	speedFactors = map[string]float64{
+		MetersPerSecond:   1,
+		KilometersPerHour: 1 / 3.6,
+		MilesPerHour:      0.44704,
+		Knots:             1852.0 / 3600,
+	}
+
+	pressureFactors = map[string]float64{
+		Hectopascals:         1,
+		Kilopascals:          10,
+		InchesOfMercury:      33.8638866667,
+		MillimetersOfMercury: 1.33322387415,
+	}
+
+	lengthFactors = map[string]float64{
+		Meters:     1,
+		Kilometers: 1000,
+		Feet:       0.3048,
+		Miles:      1609.344,
+	}
+
+	precipitationFactors = map[string]float64{
+		Millimeters: 1,
+		Inches:      25.4,
+	}
+)
+
+// Temperature converts temperature between Celsius, Fahrenheit and Kelvin
+func Temperature(value float64, from, to string) (float64, error) {
+	var celsius float64
+
+	switch from {
+	case Celsius:
+		celsius = value
+	case Fahrenheit:
+		celsius = (value - 32) * 5 / 9
+	case Kelvin:
+		celsius = value - 273.15
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownUnit, from)
+	}
+
+	switch to {
+	case Celsius:
+		return celsius, nil
+	case Fahrenheit:
+		return celsius*9/5 + 32, nil
+	case Kelvin:
+		return celsius + 273.15, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownUnit, to)
+	}
+}
+
+func Speed(value float64, from, to string) (float64, error) {
+	return convertLinear(speedFactors, value, from, to)
+}
+
+func Pressure(value float64, from, to string) (float64, error) {
+	return convertLinear(pressureFactors, value, from, to)
+}
+
+func Length(value float64, from, to string) (float64, error) {
+	return convertLinear(lengthFactors, value, from, to)
+}
+
+func Precipitation(value float64, from, to string) (float64, error) {
+	return convertLinear(precipitationFactors, value, from, to)
+}
+
+func convertLinear(factors map[string]float64, value float64, from, to string) (float64, error) {
+	fromFactor, ok := factors[from]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownUnit, from)
+	}
+
+	toFactor, ok := factors[to]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownUnit, to)
+	}
+
+	if from == to {
+		return value, nil
+	}
+
+	return value * fromFactor / toFactor, nil
+}
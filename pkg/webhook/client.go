@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Meteo-Event"
+	HeaderDelivery  = "X-Meteo-Delivery"
+	HeaderTimestamp = "X-Meteo-Timestamp"
+	HeaderSignature = "X-Meteo-Signature"
+
+	userAgent       = "meteo-go-webhooks/1.0"
+	maxResponseSize = 256
+)
+
+// ErrPrivateAddress is returned when receiver resolves to loopback, private, link-local or unspecified address
+var ErrPrivateAddress = errors.New("webhook receiver address is not public")
+
+// privateNetworks aren't reachable from the internet, "this network", RFC 1918, RFC 4193 and carrier-grade NAT ranges
+var privateNetworks = parseNetworks("0.0.0.0/8", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7")
+
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Body       []byte
+}
+
+// Response of the receiver. Body is truncated to the first 256 bytes
+type Response struct {
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+}
+
+// StatusError is returned for non-2xx responses
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected response status %d", e.StatusCode)
+}
+
+type Client struct {
+	http *http.Client
+}
+
+// NewClient creates client that connects to public addresses only. The address is checked after DNS resolution,
+// so receivers can't be rebound to internal hosts after validation. allowPrivate disables the check for development
+func NewClient(timeout time.Duration, allowPrivate bool) *Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if !IsPublicIP(net.ParseIP(host)) {
+				return ErrPrivateAddress
+			}
+
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// connecting through proxy would check proxy address instead of the receiver
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Client{
+		http: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			// redirects could be used to bypass receiver URL validation
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// IsPublicIP reports whether ip isn't loopback, private, link-local, multicast or unspecified address
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+
+		networks[i] = network
+	}
+
+	return networks
+}
+
+// Send posts signed JSON body to the receiver. Response is returned along with error
+// whenever receiver responded, so callers can record it
+func (c *Client) Send(ctx context.Context, req Request) (Response, error) {
+	timestamp := time.Now().Unix()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return Response{}, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", userAgent)
+	httpReq.Header.Set(HeaderEvent, req.Event)
+	httpReq.Header.Set(HeaderDelivery, req.DeliveryID)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Body))
+
+	start := time.Now()
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return Response{Duration: time.Since(start)}, err
+	}
+	defer httpResp.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(httpResp.Body, maxResponseSize))
+	resp := Response{
+		StatusCode: httpResp.StatusCode,
+		Body:       string(body),
+		Duration:   time.Since(start),
+	}
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return resp, &StatusError{StatusCode: httpResp.StatusCode}
+	}
+
+	return resp, nil
+}